@@ -2,14 +2,32 @@ package main
 
 import (
 	"changemedaddy/internal/api"
+	"changemedaddy/internal/domain/subscription"
 	"changemedaddy/internal/pkg/closer"
+	"changemedaddy/internal/pkg/health"
+	"changemedaddy/internal/pkg/metrics"
+	"changemedaddy/internal/pkg/timeext"
 	"changemedaddy/internal/repository/analystrepo"
+	"changemedaddy/internal/repository/blobrepo"
+	"changemedaddy/internal/repository/candlerepo"
 	"changemedaddy/internal/repository/idearepo"
+	"changemedaddy/internal/repository/outboxrepo"
 	"changemedaddy/internal/repository/positionrepo"
+	"changemedaddy/internal/repository/subscriptionrepo"
 	"changemedaddy/internal/repository/tokenrepo"
 	"changemedaddy/internal/repository/visitorsrepo"
+	"changemedaddy/internal/repository/webhookrepo"
+	"changemedaddy/internal/service/announce"
+	"changemedaddy/internal/service/candles"
+	"changemedaddy/internal/service/catalog"
+	"changemedaddy/internal/service/expiry"
+	"changemedaddy/internal/service/hooks"
 	"changemedaddy/internal/service/market"
+	"changemedaddy/internal/service/notify"
+	"changemedaddy/internal/service/schedule"
+	"changemedaddy/internal/service/targets"
 	"changemedaddy/internal/service/tokenauth"
+	"changemedaddy/internal/service/visits"
 	"context"
 	"crypto/tls"
 	"fmt"
@@ -22,11 +40,15 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 const (
 	srvAddr         = ":8080"
-	shutdownTimeout = 5 * time.Second
+	shutdownTimeout = 15 * time.Second
+	// readinessGrace is how long the app keeps serving while reporting not ready,
+	// so the load balancer's probes see it before the server stops taking requests.
+	readinessGrace = 3 * time.Second
 )
 
 const mongoString = "mongodb://db-prod:27017/?directConnection=true&serverSelectionTimeoutMS=2000&appName=mongosh+2.2.4"
@@ -41,14 +63,26 @@ func main() {
 	})
 	log := slog.New(handler)
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoString))
+	if path := os.Getenv("MOEX_CALENDAR"); path != "" {
+		if err := timeext.LoadOverrides(path); err != nil {
+			panic(err)
+		}
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoString).SetMonitor(metrics.MongoMonitor()))
 	if err != nil {
 		panic(err)
 	}
 
-	posRepo := positionrepo.NewMongo(ctx, client)
+	posRepo, err := positionrepo.NewMongo(ctx, client)
+	if err != nil {
+		panic(err)
+	}
 	ideaRepo := idearepo.NewMongo(ctx, client)
-	visitorsRepo := visitorsrepo.NewInmem(ctx)
+	visitorsRepo, err := visitorsrepo.NewMongo(ctx, client)
+	if err != nil {
+		panic(err)
+	}
 	mp := market.NewService(log)
 
 	ar := analystrepo.NewMongo(ctx, client)
@@ -56,6 +90,65 @@ func main() {
 	tr := tokenrepo.NewMongo(ctx, client)
 	as := tokenauth.New(log, ar, tr)
 
+	sr := subscriptionrepo.NewMongo(ctx, client)
+	channels := map[subscription.Channel]notify.Channel{
+		subscription.Webhook: notify.NewWebhookChannel(),
+	}
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		channels[subscription.Email] = notify.NewEmailChannel(addr, os.Getenv("SMTP_FROM"), os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"))
+	}
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		channels[subscription.Telegram] = notify.NewTelegramChannel(token)
+	}
+	nd := notify.NewDispatcher(log, sr, outboxrepo.NewMongo(ctx, client), channels)
+	nd.Start()
+
+	wr := webhookrepo.NewMongo(ctx, client)
+	hs := hooks.NewSender(log, wr)
+	hs.Start()
+
+	cr, err := candlerepo.NewMongo(ctx, client)
+	if err != nil {
+		panic(err)
+	}
+	cs := candles.NewStore(log, market.WithMetrics(mp), cr, posRepo)
+	cs.Start()
+
+	cat := catalog.New(log, market.WithMetrics(mp))
+	cat.Start()
+
+	exp := expiry.New(log, posRepo, market.WithMetrics(mp), timeext.SystemClock)
+	exp.Start()
+
+	tt := targets.New(log, posRepo, cs)
+	tt.Start()
+
+	hc := &health.Checker{}
+	hc.Add("mongo", func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	})
+	hc.Add("market", mp.Ping)
+
+	attachmentsDir := os.Getenv("ATTACHMENTS_DIR")
+	if attachmentsDir == "" {
+		attachmentsDir = "data/attachments"
+	}
+	bs, err := blobrepo.NewDisk(attachmentsDir)
+	if err != nil {
+		panic(err)
+	}
+
+	vs := visits.New(log, visitorsRepo)
+	vs.Start()
+
+	h := api.NewHandler(posRepo, visitorsRepo, vs, ideaRepo, cs, cat, ar, as, sr, nd, wr, hs, bs, hc, log)
+
+	pub := schedule.New(log, ideaRepo, posRepo, market.WithMetrics(mp), timeext.SystemClock)
+	pub.Start()
+
+	an := announce.New(log, nd, hs, ideaRepo, posRepo, cs)
+	an.Start()
+
 	var (
 		mux     = http.NewServeMux()
 		cert, _ = tls.LoadX509KeyPair("server.crt", "server.key")
@@ -67,8 +160,27 @@ func main() {
 		c = &closer.Closer{}
 	)
 
-	c.Add(mp.Shutdown)
+	c.Add(hc.Shutdown)
+	c.Add(func(ctx context.Context) error {
+		select {
+		case <-time.After(readinessGrace):
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	// in-flight requests still use the workers and the provider, they stop after
 	c.Add(srv.Shutdown)
+	c.Add(vs.Shutdown)
+	c.Add(pub.Shutdown)
+	// the announcer publishes through the dispatcher and the sender, it stops first
+	c.Add(an.Shutdown)
+	c.Add(nd.Shutdown)
+	c.Add(hs.Shutdown)
+	c.Add(cs.Shutdown)
+	c.Add(cat.Shutdown)
+	c.Add(exp.Shutdown)
+	c.Add(tt.Shutdown)
+	c.Add(mp.Shutdown)
 	c.Add(func(ctx context.Context) error {
 		return client.Disconnect(ctx)
 	})
@@ -84,5 +196,5 @@ func main() {
 		}
 	}()
 
-	panic(api.NewHandler(posRepo, visitorsRepo, ideaRepo, mp, ar, as, log).MustEcho().StartServer(srv))
+	panic(h.MustEcho().StartServer(srv))
 }
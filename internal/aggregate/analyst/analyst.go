@@ -58,6 +58,7 @@ type ideaSaver interface {
 type IdeaCreationOptions struct {
 	Name       string `form:"name"`
 	SourceLink string `form:"source_link"`
+	Benchmark  string `form:"benchmark"`
 }
 
 func (a *Analyst) NewIdea(ctx context.Context, is ideaSaver, io IdeaCreationOptions) (*idea.Idea, error) {
@@ -66,6 +67,7 @@ func (a *Analyst) NewIdea(ctx context.Context, is ideaSaver, io IdeaCreationOpti
 		AuthorName: a.Name,
 		AuthorSlug: a.Slug,
 		SourceLink: io.SourceLink,
+		Benchmark:  io.Benchmark,
 	}
 
 	i, err := idea.New(ctx, is, opt)
@@ -77,11 +79,12 @@ func (a *Analyst) NewIdea(ctx context.Context, is ideaSaver, io IdeaCreationOpti
 }
 
 type ideaFinder interface {
-	FindByAnalystSlug(ctx context.Context, slug string) ([]*idea.Idea, error)
+	FindByAnalystSlug(ctx context.Context, slug string, ss ...idea.Status) ([]*idea.Idea, error)
 }
 
-func (a *Analyst) Ideas(ctx context.Context, idf ideaFinder) ([]*idea.Idea, error) {
-	ii, err := idf.FindByAnalystSlug(ctx, a.Slug)
+// Ideas finds the analyst's ideas with any of the statuses, all of them for none.
+func (a *Analyst) Ideas(ctx context.Context, idf ideaFinder, ss ...idea.Status) ([]*idea.Idea, error) {
+	ii, err := idf.FindByAnalystSlug(ctx, a.Slug, ss...)
 	if err != nil {
 		return ii, fmt.Errorf("couldn't find ideas for analyst (slug %q): %w", a.Slug, err)
 	}
@@ -0,0 +1,103 @@
+package idea
+
+import (
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+type blobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Attachment is an image the idea's body can show.
+type Attachment struct {
+	// Key is where the blob store keeps the image, it's served at /attachments/<key>.
+	Key         string    `bson:"key"`
+	Name        string    `bson:"name"`
+	ContentType string    `bson:"content_type"`
+	Size        int       `bson:"size"`
+	At          time.Time `bson:"at"`
+}
+
+func (a Attachment) URL() string {
+	return "/attachments/" + a.Key
+}
+
+// Markdown is the snippet that shows the image in the body.
+func (a Attachment) Markdown() string {
+	alt := strings.NewReplacer("[", "", "]", "").Replace(strings.TrimSuffix(a.Name, path.Ext(a.Name)))
+	return fmt.Sprintf("![%s](%s)", alt, a.URL())
+}
+
+const (
+	MaxAttachmentSize = 5 << 20
+	MaxAttachments    = 50
+)
+
+// imageTypes are the content types attachments can have, with their extensions.
+var imageTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Attach stores the image and adds it to the idea. The type is sniffed from the
+// content, whatever the name says.
+func (i *Idea) Attach(ctx context.Context, bs blobStore, iu ideaUpdater, name string, r io.Reader) (Attachment, error) {
+	if i.Status != Draft && i.Status != Active {
+		return Attachment{}, ErrClosedIdeaModified
+	}
+	if len(i.Attachments) >= MaxAttachments {
+		return Attachment{}, ErrTooManyAttachments
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, MaxAttachmentSize+1))
+	if err != nil {
+		return Attachment{}, fmt.Errorf("couldn't read attachment: %w", err)
+	}
+	if len(data) > MaxAttachmentSize {
+		return Attachment{}, ErrAttachmentTooBig
+	}
+
+	ct := http.DetectContentType(data)
+	ext, ok := imageTypes[ct]
+	if !ok {
+		return Attachment{}, ErrAttachmentType
+	}
+
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	a := Attachment{
+		Key:         fmt.Sprintf("%s/%s/%s%s", i.AuthorSlug, i.Slug, hex.EncodeToString(b), ext),
+		Name:        path.Base(strings.ReplaceAll(name, `\`, "/")),
+		ContentType: ct,
+		Size:        len(data),
+		At:          timeext.Now(),
+	}
+	if err := bs.Put(ctx, a.Key, data); err != nil {
+		return Attachment{}, fmt.Errorf("couldn't store attachment: %w", err)
+	}
+
+	old := i.Attachments
+	i.Attachments = append(slices.Clone(i.Attachments), a)
+	if err := iu.Update(ctx, i); err != nil {
+		i.Attachments = old
+		// nothing points at the image yet
+		_ = bs.Delete(ctx, a.Key)
+		return Attachment{}, fmt.Errorf("couldn't update idea: %w", err)
+	}
+
+	return a, nil
+}
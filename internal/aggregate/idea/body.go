@@ -0,0 +1,84 @@
+package idea
+
+import (
+	"changemedaddy/internal/pkg/markdown"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Body is the write-up of the idea, every section is Markdown.
+type Body struct {
+	Thesis    string `bson:"thesis" form:"thesis"`
+	Catalysts string `bson:"catalysts" form:"catalysts"`
+	Risks     string `bson:"risks" form:"risks"`
+}
+
+func (b Body) IsEmpty() bool {
+	return b == Body{}
+}
+
+// Version is a body the idea had before an edit.
+type Version struct {
+	Body Body `bson:"body"`
+	// EditedAt is when the body was written.
+	EditedAt time.Time `bson:"edited_at"`
+}
+
+const (
+	// MaxSectionLength is in runes.
+	MaxSectionLength = 10_000
+	excerptLength    = 200
+	// MaxVersions keeps the idea document well below Mongo's 16MB with every
+	// version at the longest, the oldest ones are dropped past it.
+	MaxVersions = 20
+)
+
+func normalizeSection(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// EditBody replaces the body. Readers have seen the body of a published idea,
+// so it's kept in the versions, a draft's body is rewritten in place.
+func (i *Idea) EditBody(ctx context.Context, iu ideaUpdater, b Body) error {
+	if i.Status != Draft && i.Status != Active {
+		return ErrClosedIdeaModified
+	}
+
+	b = Body{
+		Thesis:    normalizeSection(b.Thesis),
+		Catalysts: normalizeSection(b.Catalysts),
+		Risks:     normalizeSection(b.Risks),
+	}
+	for _, s := range []string{b.Thesis, b.Catalysts, b.Risks} {
+		if utf8.RuneCountInString(s) > MaxSectionLength {
+			return ErrBodyTooLong
+		}
+	}
+	if b == i.Body {
+		return nil
+	}
+
+	oldb, olde, oldv := i.Body, i.BodyEditedAt, i.Versions
+	if i.IsPublic() && !i.Body.IsEmpty() {
+		vv := append(slices.Clone(i.Versions), Version{Body: i.Body, EditedAt: i.BodyEditedAt})
+		i.Versions = vv[max(len(vv)-MaxVersions, 0):]
+	}
+	i.Body = b
+	i.BodyEditedAt = timeext.Now()
+
+	if err := iu.Update(ctx, i); err != nil {
+		i.Body, i.BodyEditedAt, i.Versions = oldb, olde, oldv
+		return fmt.Errorf("couldn't update idea: %w", err)
+	}
+	return nil
+}
+
+// Excerpt is the start of the thesis in plain text.
+func (i *Idea) Excerpt() string {
+	return markdown.Excerpt(i.Body.Thesis, excerptLength)
+}
@@ -0,0 +1,53 @@
+package idea
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestBodyVersions(t *testing.T) {
+	var (
+		ctx = context.Background()
+		i   = &Idea{Status: Draft}
+		u   ideaUpdates
+	)
+
+	// drafts are rewritten without a trace
+	for _, thesis := range []string{"first", "second"} {
+		if err := i.EditBody(ctx, &u, Body{Thesis: thesis}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(i.Versions) != 0 {
+		t.Fatalf("got %d versions of a draft, want none", len(i.Versions))
+	}
+
+	i.Status = Active
+	if err := i.EditBody(ctx, &u, Body{Thesis: "third", Risks: "  risks\r\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(i.Versions) != 1 || i.Versions[0].Body.Thesis != "second" || i.Body.Risks != "risks" {
+		t.Fatalf("got versions %v and body %v, want the draft's body kept", i.Versions, i.Body)
+	}
+
+	// unchanged bodies and too long ones aren't saved
+	if err := i.EditBody(ctx, &u, i.Body); err != nil || len(i.Versions) != 1 || u != 3 {
+		t.Errorf("got %v, %d versions and %d updates for an unchanged body", err, len(i.Versions), u)
+	}
+	long := Body{Catalysts: string(make([]rune, MaxSectionLength+1))}
+	if err := i.EditBody(ctx, &u, long); !errors.Is(err, ErrBodyTooLong) || i.Body.Thesis != "third" {
+		t.Errorf("got %v with thesis %q, want ErrBodyTooLong and the body intact", err, i.Body.Thesis)
+	}
+
+	// the oldest versions go past the cap
+	for n := range MaxVersions + 5 {
+		if err := i.EditBody(ctx, &u, Body{Thesis: strconv.Itoa(n)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(i.Versions) != MaxVersions || i.Versions[0].Body.Thesis != "4" {
+		t.Errorf("got %d versions from %q, want %d from the 5th edit", len(i.Versions), i.Versions[0].Body.Thesis, MaxVersions)
+	}
+}
@@ -6,7 +6,19 @@ var (
 	ErrNotFound           = errors.New("idea not found")
 	ErrConflict           = errors.New("idea with the same slug (or name) already exists")
 	ErrClosedIdeaModified = errors.New("cannot change a closed idea")
+	ErrStatus             = errors.New("idea can't change to that status from its own")
+	ErrNoPositions        = errors.New("idea needs a position to be published")
+	ErrPublishAt          = errors.New("publication time should be in the future")
+	ErrPendingDraft       = errors.New("positions of a draft are published with it")
+	ErrNotDraft           = errors.New("only a draft's positions can be rewritten or deleted")
 
 	ErrNameTooShort = errors.New("idea name must be at least 3 characters long")
 	ErrNameTooLong  = errors.New("idea name must be at most 55 characters long")
+	ErrBenchmark    = errors.New("unknown benchmark")
+	ErrBodyTooLong  = errors.New("idea body section is too long")
+
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentType     = errors.New("attachment must be a png, jpeg, gif or webp image")
+	ErrAttachmentTooBig   = errors.New("attachment must be at most 5 MB")
+	ErrTooManyAttachments = errors.New("idea has too many attachments")
 )
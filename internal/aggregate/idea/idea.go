@@ -3,8 +3,12 @@ package idea
 import (
 	"changemedaddy/internal/domain/instrument"
 	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gosimple/slug"
 	"github.com/greatcloak/decimal"
@@ -13,10 +17,21 @@ import (
 type Status string
 
 const (
-	Active Status = "active"
-	Closed Status = "closed"
+	// Draft ideas are seen by their author only, their positions don't count yet.
+	Draft Status = "draft"
+	// Active ideas are published.
+	Active   Status = "active"
+	Closed   Status = "closed"
+	Archived Status = "archived"
 )
 
+// Public are the statuses of the ideas anyone can see.
+var Public = []Status{Active, Closed}
+
+func (i *Idea) IsPublic() bool {
+	return slices.Contains(Public, i.Status)
+}
+
 type Idea struct {
 	Name        string `bson:"name"`
 	Slug        string `bson:"slug"`
@@ -25,7 +40,58 @@ type Idea struct {
 	AuthorSlug  string `bson:"author_slug"`
 	SourceLink  string `bson:"source_link"`
 	PositionIDs []int  `bson:"position_ids"`
-	Status      Status `bson:"status"`
+	// PendingIDs are the positions scheduled to open, hidden from readers until then.
+	PendingIDs []int  `bson:"pending_ids,omitempty"`
+	Status     Status `bson:"status"`
+	// ArchivedFrom is the status an archived idea is restored to.
+	ArchivedFrom Status `bson:"archived_from,omitempty"`
+	// Benchmark is the ticker of the index the idea's positions are compared to.
+	Benchmark string `bson:"benchmark"`
+
+	Body         Body      `bson:"body"`
+	BodyEditedAt time.Time `bson:"body_edited_at,omitempty"`
+	// Versions are the bodies a published idea had before its edits, oldest first.
+	Versions    []Version    `bson:"versions,omitempty"`
+	Attachments []Attachment `bson:"attachments,omitempty"`
+
+	CreatedAt   time.Time `bson:"created_at"`
+	PublishedAt time.Time `bson:"published_at,omitempty"`
+	// PublishAt is when a scheduled draft goes public.
+	PublishAt time.Time `bson:"publish_at,omitempty"`
+	// Unannounced is set on publication until the analyst's webhooks are told.
+	Unannounced bool `bson:"unannounced,omitempty"`
+}
+
+// DefaultBenchmark is the benchmark of ideas that didn't choose one.
+const DefaultBenchmark = "IMOEX"
+
+type BenchmarkOption struct {
+	Ticker string
+	Name   string
+}
+
+// Benchmarks are the indices an idea can be compared to.
+var Benchmarks = []BenchmarkOption{
+	{"IMOEX", "Индекс МосБиржи"},
+	{"MOEXOG", "Нефть и газ"},
+	{"MOEXFN", "Финансы"},
+	{"MOEXMM", "Металлы и добыча"},
+	{"MOEXEU", "Электроэнергетика"},
+	{"MOEXTL", "Телекоммуникации"},
+	{"MOEXCN", "Потребительский сектор"},
+	{"MOEXCH", "Химия и нефтехимия"},
+	{"MOEXTN", "Транспорт"},
+	{"MOEXRE", "Строительные компании"},
+	{"MOEXIT", "Информационные технологии"},
+	{"RGBI", "Государственные облигации"},
+}
+
+// BenchmarkTicker is the idea's benchmark, ideas saved before benchmarks existed use the default.
+func (i *Idea) BenchmarkTicker() string {
+	if i.Benchmark == "" {
+		return DefaultBenchmark
+	}
+	return i.Benchmark
 }
 
 // ideaSaver saves Idea s.
@@ -40,6 +106,7 @@ type CreationOptions struct {
 	AuthorSlug string
 	AuthorID   int
 	SourceLink string
+	Benchmark  string
 }
 
 func New(ctx context.Context, is ideaSaver, opt CreationOptions) (*Idea, error) {
@@ -49,6 +116,13 @@ func New(ctx context.Context, is ideaSaver, opt CreationOptions) (*Idea, error)
 		return nil, ErrNameTooLong
 	}
 
+	benchmark := strings.ToUpper(strings.TrimSpace(opt.Benchmark))
+	if benchmark == "" {
+		benchmark = DefaultBenchmark
+	} else if !slices.ContainsFunc(Benchmarks, func(b BenchmarkOption) bool { return b.Ticker == benchmark }) {
+		return nil, ErrBenchmark
+	}
+
 	i := &Idea{
 		Name:       opt.Name,
 		Slug:       slug.Make(opt.Name),
@@ -56,7 +130,9 @@ func New(ctx context.Context, is ideaSaver, opt CreationOptions) (*Idea, error)
 		AuthorID:   opt.AuthorID,
 		AuthorName: opt.AuthorName,
 		SourceLink: opt.SourceLink,
-		Status:     Active,
+		Status:     Draft,
+		Benchmark:  benchmark,
+		CreatedAt:  timeext.Now(),
 	}
 
 	err := is.Save(ctx, i)
@@ -84,16 +160,29 @@ type ideaUpdater interface {
 	Update(ctx context.Context, i *Idea) error
 }
 
-func (i *Idea) NewPosition(ctx context.Context, mp marketProvider, ps positionSaver, iu ideaUpdater, opt position.CreationOptions) (*position.Position, error) {
-	p, err := position.New(ctx, mp, ps, opt)
+func (i *Idea) NewPosition(ctx context.Context, now time.Time, mp marketProvider, ps positionSaver, iu ideaUpdater, opt position.CreationOptions) (*position.Position, error) {
+	if i.Status != Draft && i.Status != Active {
+		return nil, ErrClosedIdeaModified
+	}
+	if i.Status == Draft && opt.PublishAt != "" {
+		return nil, ErrPendingDraft
+	}
+
+	opt.Draft = i.Status == Draft
+	p, err := position.New(ctx, now, mp, ps, opt)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't create position: %w", err)
 	}
 
-	i.PositionIDs = append(i.PositionIDs, p.ID)
+	oldp, oldpp := i.PositionIDs, i.PendingIDs
+	if p.Status == position.Pending {
+		i.PendingIDs = append(slices.Clone(i.PendingIDs), p.ID)
+	} else {
+		i.PositionIDs = append(slices.Clone(i.PositionIDs), p.ID)
+	}
 
 	if err := iu.Update(ctx, i); err != nil {
-		i.PositionIDs = i.PositionIDs[:len(i.PositionIDs)-1]
+		i.PositionIDs, i.PendingIDs = oldp, oldpp
 		return nil, fmt.Errorf("couldn't update idea: %w", err)
 	}
 
@@ -104,8 +193,52 @@ type positionUpdater interface {
 	Update(ctx context.Context, p *position.Position) error
 }
 
-func (i *Idea) Close(ctx context.Context, mp marketProvider, pu positionUpdater) error {
-	panic("need to implement")
+type positionDeleter interface {
+	Delete(ctx context.Context, id int) error
+}
+
+// EditDraftPosition rewrites a position of the draft from opt. Nobody has seen
+// a draft, so unlike ApplyChange it leaves no history.
+func (i *Idea) EditDraftPosition(ctx context.Context, now time.Time, mp marketProvider, pu positionUpdater, p *position.Position, opt position.CreationOptions) error {
+	if i.Status != Draft {
+		return ErrNotDraft
+	}
+	if !slices.Contains(i.PositionIDs, p.ID) {
+		return position.ErrNotFound
+	}
+	if opt.PublishAt != "" {
+		return ErrPendingDraft
+	}
+
+	opt.Draft = true
+	if err := p.Redraft(ctx, now, mp, pu, opt); err != nil {
+		return fmt.Errorf("couldn't rewrite position: %w", err)
+	}
+	return nil
+}
+
+// DeleteDraftPosition drops a position of the draft.
+func (i *Idea) DeleteDraftPosition(ctx context.Context, pd positionDeleter, iu ideaUpdater, id int) error {
+	if i.Status != Draft {
+		return ErrNotDraft
+	}
+	n := slices.Index(i.PositionIDs, id)
+	if n < 0 {
+		return position.ErrNotFound
+	}
+
+	old := i.PositionIDs
+	i.PositionIDs = slices.Delete(slices.Clone(i.PositionIDs), n, n+1)
+	if err := iu.Update(ctx, i); err != nil {
+		i.PositionIDs = old
+		return fmt.Errorf("couldn't update idea: %w", err)
+	}
+
+	// the idea doesn't point at it anymore, a failed delete only leaves it orphaned
+	if err := pd.Delete(ctx, id); err != nil {
+		return fmt.Errorf("couldn't delete position: %w", err)
+	}
+	return nil
 }
 
 type WithProfit struct {
@@ -0,0 +1,168 @@
+package idea
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type positionRepo interface {
+	Find(ctx context.Context, id int) (*position.Position, error)
+	Update(ctx context.Context, p *position.Position) error
+	Delete(ctx context.Context, id int) error
+}
+
+type quoteProvider interface {
+	priceProvider
+	Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error)
+}
+
+// positions finds the idea's positions, skipping the ones that are gone.
+func (i *Idea) positions(ctx context.Context, pr positionRepo) ([]*position.Position, error) {
+	pp := make([]*position.Position, 0, len(i.PositionIDs))
+	for _, id := range i.PositionIDs {
+		p, err := pr.Find(ctx, id)
+		if errors.Is(err, position.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("couldn't find position (id %d): %w", id, err)
+		}
+		pp = append(pp, p)
+	}
+	return pp, nil
+}
+
+func (i *Idea) setStatus(ctx context.Context, iu ideaUpdater, s Status) error {
+	old := *i
+	i.Status = s
+	if err := iu.Update(ctx, i); err != nil {
+		*i = old
+		return fmt.Errorf("couldn't update idea: %w", err)
+	}
+	return nil
+}
+
+// Publish makes a draft public as of now. Its draft positions are reopened at
+// now's prices, the ones closed in the draft are dropped: only what happens after the
+// publication counts. All of them are priced before any is saved, so a position
+// that can't reopen leaves the idea a draft with its positions as they were.
+func (i *Idea) Publish(ctx context.Context, now time.Time, pp priceProvider, pr positionRepo, iu ideaUpdater) error {
+	if i.Status != Draft {
+		return ErrStatus
+	}
+
+	all, err := i.positions(ctx, pr)
+	if err != nil {
+		return err
+	}
+
+	var reopened []*position.Position
+	for _, p := range all {
+		if p.Status == position.Closed {
+			continue
+		}
+		r, err := p.Reopened(ctx, now, pp)
+		if err != nil {
+			return fmt.Errorf("couldn't reopen position (id %d): %w", p.ID, err)
+		}
+		reopened = append(reopened, r)
+	}
+	if len(reopened) == 0 {
+		return ErrNoPositions
+	}
+
+	// a failed save leaves the idea a draft, publishing it again reopens them all anew
+	ids := make([]int, 0, len(reopened))
+	for _, p := range reopened {
+		if err := pr.Update(ctx, p); err != nil {
+			return fmt.Errorf("couldn't save position (id %d): %w", p.ID, err)
+		}
+		ids = append(ids, p.ID)
+	}
+
+	old := *i
+	i.PositionIDs = ids
+	i.PublishedAt = now
+	i.PublishAt = time.Time{}
+	i.Unannounced = true
+	if err := i.setStatus(ctx, iu, Active); err != nil {
+		*i = old
+		return err
+	}
+	return nil
+}
+
+// Close closes the idea's active positions at the current prices and deletes
+// the pending ones, then closes the idea.
+func (i *Idea) Close(ctx context.Context, qp quoteProvider, pr positionRepo, iu ideaUpdater) error {
+	if i.Status != Active {
+		return ErrStatus
+	}
+
+	pp, err := i.positions(ctx, pr)
+	if err != nil {
+		return err
+	}
+	for _, p := range pp {
+		if p.Status == position.Closed {
+			continue
+		}
+		wp, err := p.WithProfit(ctx, qp)
+		if err != nil {
+			return fmt.Errorf("couldn't get profit of position (id %d): %w", p.ID, err)
+		}
+		if err := wp.Close(ctx, pr); err != nil {
+			return fmt.Errorf("couldn't close position (id %d): %w", p.ID, err)
+		}
+	}
+
+	// the pending positions never open, nobody has seen them
+	for _, id := range i.PendingIDs {
+		if err := pr.Delete(ctx, id); err != nil && !errors.Is(err, position.ErrNotFound) {
+			return fmt.Errorf("couldn't delete pending position (id %d): %w", id, err)
+		}
+	}
+	pending := i.PendingIDs
+	i.PendingIDs = nil
+	if err := i.setStatus(ctx, iu, Closed); err != nil {
+		i.PendingIDs = pending
+		return err
+	}
+	return nil
+}
+
+// Archive hides a draft or a closed idea from the analyst's page.
+func (i *Idea) Archive(ctx context.Context, iu ideaUpdater) error {
+	if i.Status != Draft && i.Status != Closed {
+		return ErrStatus
+	}
+
+	// archiving unschedules a draft, the time would have passed by its restore
+	oldf, oldp := i.ArchivedFrom, i.PublishAt
+	i.ArchivedFrom = i.Status
+	i.PublishAt = time.Time{}
+	if err := i.setStatus(ctx, iu, Archived); err != nil {
+		i.ArchivedFrom, i.PublishAt = oldf, oldp
+		return err
+	}
+	return nil
+}
+
+// Restore brings an archived idea back to the status it was archived from.
+func (i *Idea) Restore(ctx context.Context, iu ideaUpdater) error {
+	if i.Status != Archived || !slices.Contains([]Status{Draft, Closed}, i.ArchivedFrom) {
+		return ErrStatus
+	}
+
+	from := i.ArchivedFrom
+	i.ArchivedFrom = ""
+	if err := i.setStatus(ctx, iu, from); err != nil {
+		i.ArchivedFrom = from
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package idea
+
+import (
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+func TestLifecycle(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		m, clock = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+	)
+
+	type action func(i *Idea, pr memPositions, u *ideaUpdates) error
+	var (
+		doPublish = func(i *Idea, pr memPositions, u *ideaUpdates) error { return i.Publish(ctx, clock.Now(), m, pr, u) }
+		doClose   = func(i *Idea, pr memPositions, u *ideaUpdates) error { return i.Close(ctx, m, pr, u) }
+		doArchive = func(i *Idea, pr memPositions, u *ideaUpdates) error { return i.Archive(ctx, u) }
+		doRestore = func(i *Idea, pr memPositions, u *ideaUpdates) error { return i.Restore(ctx, u) }
+	)
+
+	cases := []struct {
+		name         string
+		from         Status
+		archivedFrom Status
+		do           action
+		// want is the status after, the same as from when the move is rejected
+		want Status
+	}{
+		{"publish a draft", Draft, "", doPublish, Active},
+		{"publish an active idea", Active, "", doPublish, Active},
+		{"publish a closed idea", Closed, "", doPublish, Closed},
+		{"publish an archived draft", Archived, Draft, doPublish, Archived},
+
+		{"close a draft", Draft, "", doClose, Draft},
+		{"close an active idea", Active, "", doClose, Closed},
+		{"close a closed idea", Closed, "", doClose, Closed},
+		{"close an archived idea", Archived, Closed, doClose, Archived},
+
+		{"archive a draft", Draft, "", doArchive, Archived},
+		{"archive an active idea", Active, "", doArchive, Active},
+		{"archive a closed idea", Closed, "", doArchive, Archived},
+		{"archive an archived idea", Archived, Closed, doArchive, Archived},
+
+		{"restore a draft", Draft, "", doRestore, Draft},
+		{"restore an active idea", Active, "", doRestore, Active},
+		{"restore a closed idea", Closed, "", doRestore, Closed},
+		{"restore an archived draft", Archived, Draft, doRestore, Draft},
+		{"restore an archived closed idea", Archived, Closed, doRestore, Closed},
+		{"restore an idea archived from nowhere", Archived, "", doRestore, Archived},
+	}
+	for _, c := range cases {
+		var (
+			pr = memPositions{1: mgnt(t, m, 1, position.Active)}
+			i  = &Idea{Status: c.from, ArchivedFrom: c.archivedFrom, PositionIDs: []int{1}}
+			u  ideaUpdates
+		)
+
+		err := c.do(i, pr, &u)
+		if allowed := c.want != c.from; allowed && err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		} else if !allowed && (!errors.Is(err, ErrStatus) || u != 0) {
+			t.Errorf("%s: got %v with %d updates, want ErrStatus and nothing saved", c.name, err, u)
+		}
+		if i.Status != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, i.Status, c.want)
+		}
+		if c.want == Archived && c.from != Archived && i.ArchivedFrom != c.from {
+			t.Errorf("%s: got archived from %q, want %q", c.name, i.ArchivedFrom, c.from)
+		}
+	}
+}
+
+func TestPublishIsAllOrNothing(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		m, clock = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		ok       = mgnt(t, m, 1, position.Active)
+		behind   = mgnt(t, m, 2, position.Active)
+		pr       = memPositions{1: ok, 2: behind}
+		i        = &Idea{Status: Draft, PositionIDs: []int{1, 2}}
+		u        ideaUpdates
+	)
+	// behind the price of 8800 now
+	behind.TargetPrice = decimal.NewFromInt(8500)
+
+	if err := i.Publish(ctx, clock.Now(), m, pr, &u); !errors.Is(err, position.ErrTargetPrice) {
+		t.Fatalf("got %v, want ErrTargetPrice", err)
+	}
+	if i.Status != Draft || u != 0 || pr[1] != ok || ok.Status != position.Active || !ok.OpenDate.Before(clock.Now()) {
+		t.Errorf("got the idea %s and the first position opened on %v, want both as they were", i.Status, pr[1].OpenDate)
+	}
+}
+
+func TestDraftPositionsChangeFreely(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		m, clock = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		p        = mgnt(t, m, 1, position.Draft)
+		pr       = memPositions{1: p}
+		i        = &Idea{Status: Draft, PositionIDs: []int{1}}
+		u        ideaUpdates
+		opt      = position.CreationOptions{Ticker: "MGNT", Type: position.Short, TargetPrice: "8000", Deadline: "01.06.2024"}
+	)
+
+	if err := i.EditDraftPosition(ctx, clock.Now(), m, pr, p, opt); err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 1 || pr[1].Type != position.Short || !p.OpenPrice.Equal(decimal.NewFromInt(8800)) || len(p.History) != 1 || p.Status != position.Draft {
+		t.Errorf("got #%d %s %s at %s with %d changes, want #1 short draft at 8800 opened anew", p.ID, p.Status, pr[1].Type, p.OpenPrice, len(p.History))
+	}
+
+	// nothing tracks a draft's positions, the publication opens them
+	np, err := i.NewPosition(ctx, clock.Now(), m, pr, &u, opt)
+	if err != nil || np.Status != position.Draft {
+		t.Fatalf("got %v, want a draft position", err)
+	}
+	if err := i.Publish(ctx, clock.Now(), m, pr, &u); err != nil || pr[np.ID].Status != position.Active {
+		t.Fatalf("got %v, want the position active with the idea", err)
+	}
+	i, u = &Idea{Status: Draft, PositionIDs: []int{1}}, 0
+
+	opt.PublishAt = "13.05.2024 10:00"
+	if err := i.EditDraftPosition(ctx, clock.Now(), m, pr, p, opt); !errors.Is(err, ErrPendingDraft) {
+		t.Errorf("got %v, want ErrPendingDraft", err)
+	}
+
+	if err := i.DeleteDraftPosition(ctx, pr, &u, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pr[1]; ok || len(i.PositionIDs) != 0 || u != 1 {
+		t.Errorf("got positions %v with %d updates, want it gone", i.PositionIDs, u)
+	}
+
+	// readers have seen a published idea's positions
+	i = &Idea{Status: Active, PositionIDs: []int{1}}
+	pr[1] = p
+	opt.PublishAt = ""
+	if err := i.EditDraftPosition(ctx, clock.Now(), m, pr, p, opt); !errors.Is(err, ErrNotDraft) {
+		t.Errorf("got %v, want ErrNotDraft", err)
+	}
+	if err := i.DeleteDraftPosition(ctx, pr, &u, 1); !errors.Is(err, ErrNotDraft) || pr[1] == nil {
+		t.Errorf("got %v, want ErrNotDraft and the position kept", err)
+	}
+}
+
+func TestCloseDeletesPending(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		m, _ = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		pr   = memPositions{1: mgnt(t, m, 1, position.Active), 2: mgnt(t, m, 2, position.Pending)}
+		i    = &Idea{Status: Active, PositionIDs: []int{1}, PendingIDs: []int{2}}
+		u    ideaUpdates
+	)
+
+	if err := i.Close(ctx, m, pr, &u); err != nil {
+		t.Fatal(err)
+	}
+	if _, kept := pr[2]; kept || len(i.PendingIDs) != 0 || pr[1].Status != position.Closed {
+		t.Errorf("got pending %v, kept %v and the active one %s, want the pending one deleted", i.PendingIDs, kept, pr[1].Status)
+	}
+}
@@ -0,0 +1,128 @@
+package idea
+
+import (
+	"changemedaddy/internal/domain/position"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Schedule sets when a draft goes public, a zero time unschedules it. The time
+// should be after now.
+func (i *Idea) Schedule(ctx context.Context, now time.Time, iu ideaUpdater, at time.Time) error {
+	if i.Status != Draft {
+		return ErrStatus
+	}
+	if !at.IsZero() && !at.After(now) {
+		return ErrPublishAt
+	}
+
+	old := i.PublishAt
+	i.PublishAt = at
+	if err := iu.Update(ctx, i); err != nil {
+		i.PublishAt = old
+		return fmt.Errorf("couldn't update idea: %w", err)
+	}
+	return nil
+}
+
+func (i *Idea) IsScheduled() bool {
+	return i.Status == Draft && !i.PublishAt.IsZero()
+}
+
+// Publication is what PublishDue has made public, and when it's next due.
+type Publication struct {
+	// Idea is set when the whole idea went public with its positions.
+	Idea      bool
+	Positions []*position.Position
+	// Next is zero when nothing else is scheduled.
+	Next time.Time
+}
+
+// cantOpen tells the errors retrying won't fix from the ones it may.
+func cantOpen(err error) bool {
+	return errors.Is(err, ErrNoPositions) || errors.Is(err, position.ErrTargetPrice) || errors.Is(err, position.ErrParseDeadline) || errors.Is(err, position.ErrExpired)
+}
+
+// PublishDue publishes the scheduled draft or opens the pending positions whose
+// time has come by now, at now's prices. A draft that can't be published
+// anymore is unscheduled and a pending position that can't open is deleted, so
+// they aren't retried forever.
+func (i *Idea) PublishDue(ctx context.Context, now time.Time, pp priceProvider, pr positionRepo, iu ideaUpdater) (Publication, error) {
+	if i.IsScheduled() {
+		if i.PublishAt.After(now) {
+			return Publication{Next: i.PublishAt}, nil
+		}
+
+		err := i.Publish(ctx, now, pp, pr, iu)
+		if err == nil {
+			opened, err := i.positions(ctx, pr)
+			return Publication{Idea: true, Positions: opened}, err
+		}
+		if !cantOpen(err) {
+			return Publication{Next: i.PublishAt}, fmt.Errorf("couldn't publish scheduled idea: %w", err)
+		}
+		if uerr := i.Schedule(ctx, now, iu, time.Time{}); uerr != nil {
+			err = errors.Join(err, uerr)
+		}
+		return Publication{}, fmt.Errorf("couldn't publish scheduled idea: %w", err)
+	}
+
+	var (
+		pub     Publication
+		pending []int
+		opened  []int
+		errs    error
+	)
+	for _, id := range i.PendingIDs {
+		p, err := pr.Find(ctx, id)
+		if errors.Is(err, position.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return Publication{}, fmt.Errorf("couldn't find position (id %d): %w", id, err)
+		}
+
+		switch p.Status {
+		case position.Active:
+			// opened, but the idea wasn't saved after
+			opened = append(opened, id)
+			continue
+		case position.Closed:
+			// closed before it opened, it never was public
+			continue
+		}
+		if p.PublishAt.After(now) {
+			pending = append(pending, id)
+			if pub.Next.IsZero() || p.PublishAt.Before(pub.Next) {
+				pub.Next = p.PublishAt
+			}
+			continue
+		}
+
+		if err := p.Reopen(ctx, now, pp, pr); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("couldn't open pending position (id %d): %w", id, err))
+			if !cantOpen(err) {
+				pending = append(pending, id)
+			} else if err := pr.Delete(ctx, id); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("couldn't delete pending position (id %d): %w", id, err))
+			}
+			continue
+		}
+		opened = append(opened, id)
+		pub.Positions = append(pub.Positions, p)
+	}
+
+	if len(pending) == len(i.PendingIDs) {
+		return pub, errs
+	}
+
+	oldp, oldpp := i.PositionIDs, i.PendingIDs
+	i.PositionIDs = append(append([]int(nil), i.PositionIDs...), opened...)
+	i.PendingIDs = pending
+	if err := iu.Update(ctx, i); err != nil {
+		i.PositionIDs, i.PendingIDs = oldp, oldpp
+		return Publication{}, errors.Join(errs, fmt.Errorf("couldn't update idea: %w", err))
+	}
+	return pub, errs
+}
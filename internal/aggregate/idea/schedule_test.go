@@ -0,0 +1,151 @@
+package idea
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+// market is what the tests need of the fake market.
+type market interface {
+	quoteProvider
+	instrumentProvider
+}
+
+func find(t *testing.T, m market, ticker string) *instrument.Instrument {
+	t.Helper()
+
+	i, err := m.Find(context.Background(), ticker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+// memPositions is an in-memory position repo.
+type memPositions map[int]*position.Position
+
+func (pp memPositions) Find(ctx context.Context, id int) (*position.Position, error) {
+	p, ok := pp[id]
+	if !ok {
+		return nil, position.ErrNotFound
+	}
+	return p, nil
+}
+
+func (pp memPositions) Save(ctx context.Context, p *position.Position) error {
+	pp[p.ID] = p
+	return nil
+}
+
+func (pp memPositions) Update(ctx context.Context, p *position.Position) error {
+	pp[p.ID] = p
+	return nil
+}
+
+func (pp memPositions) Delete(ctx context.Context, id int) error {
+	if _, ok := pp[id]; !ok {
+		return position.ErrNotFound
+	}
+	delete(pp, id)
+	return nil
+}
+
+type ideaUpdates int
+
+func (u *ideaUpdates) Update(ctx context.Context, i *Idea) error {
+	*u++
+	return nil
+}
+
+// mgnt is a long MGNT position, the price is 8800 from the 10th of May 2024.
+func mgnt(t *testing.T, m market, id int, status position.Status) *position.Position {
+	return &position.Position{
+		ID:          id,
+		Instrument:  find(t, m, "MGNT"),
+		Type:        position.Long,
+		Status:      status,
+		OpenPrice:   decimal.NewFromInt(8000),
+		OpenDate:    time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow),
+		Deadline:    time.Date(2024, 6, 1, 18, 50, 0, 0, timeext.Moscow),
+		TargetPrice: decimal.NewFromInt(9000),
+	}
+}
+
+func TestPendingPositionsOpen(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		m, clock = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		now      = clock.Now()
+		pending  = func(id int, at time.Time) *position.Position {
+			p := mgnt(t, m, id, position.Pending)
+			p.PublishAt = at
+			return p
+		}
+		pr = memPositions{1: pending(1, now.Add(-time.Minute)), 2: pending(2, now.Add(time.Hour))}
+		i  = &Idea{Status: Active, PositionIDs: []int{}, PendingIDs: []int{1, 2}}
+		u  ideaUpdates
+	)
+
+	pub, err := i.PublishDue(ctx, now, m, pr, &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.Positions) != 1 || !reflect.DeepEqual(i.PositionIDs, []int{1}) || !reflect.DeepEqual(i.PendingIDs, []int{2}) || u != 1 {
+		t.Fatalf("got %d opened, positions %v and pending %v, want the due one opened", len(pub.Positions), i.PositionIDs, i.PendingIDs)
+	}
+	if p := pr[1]; p.Status != position.Active || !p.OpenPrice.Equal(decimal.NewFromInt(8800)) || !p.OpenDate.Equal(now) {
+		t.Errorf("got %s at %s on %v, want it open at the price of the moment", p.Status, p.OpenPrice, p.OpenDate)
+	}
+	if !pub.Next.Equal(pr[2].PublishAt) {
+		t.Errorf("got next publication at %v, want %v", pub.Next, pr[2].PublishAt)
+	}
+
+	// nothing is due until then
+	if pub, _ := i.PublishDue(ctx, now, m, pr, &u); len(pub.Positions) != 0 || u != 1 {
+		t.Errorf("got %d more opened and %d updates, want none", len(pub.Positions), u)
+	}
+
+	clock.Advance(time.Hour)
+	pub, err = i.PublishDue(ctx, clock.Now(), m, pr, &u)
+	if err != nil || len(pub.Positions) != 1 || len(i.PendingIDs) != 0 || !pub.Next.IsZero() {
+		t.Errorf("got %v, %d opened and pending %v, want the last one opened", err, len(pub.Positions), i.PendingIDs)
+	}
+}
+
+func TestScheduledIdeaPublishes(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		m, clock = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		pr       = memPositions{1: mgnt(t, m, 1, position.Active)}
+		i        = &Idea{Status: Draft, PositionIDs: []int{1}}
+		u        ideaUpdates
+	)
+
+	if err := i.Schedule(ctx, clock.Now(), &u, clock.Now()); err == nil {
+		t.Error("scheduled for a moment that has passed")
+	}
+	if err := i.Schedule(ctx, clock.Now(), &u, clock.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if pub, err := i.PublishDue(ctx, clock.Now(), m, pr, &u); err != nil || pub.Idea || !pub.Next.Equal(i.PublishAt) {
+		t.Fatalf("got %+v, %v, want it still due at %v", pub, err, i.PublishAt)
+	}
+
+	clock.Advance(time.Hour)
+	pub, err := i.PublishDue(ctx, clock.Now(), m, pr, &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Idea || len(pub.Positions) != 1 || i.Status != Active || !i.PublishedAt.Equal(clock.Now()) || !i.PublishAt.IsZero() {
+		t.Errorf("got %+v, the idea %s published at %v, want it public now", pub, i.Status, i.PublishedAt)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"changemedaddy/internal/aggregate/analyst"
 	"changemedaddy/internal/aggregate/idea"
 	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
 	"errors"
 	"fmt"
 	"time"
@@ -84,7 +85,7 @@ func (h *handler) fakeMeData(c echo.Context) error {
 				h.log.Error("failed to fake data", "err", err)
 			}
 
-			p, err := i.NewPosition(ctx, h.mp, h.pos, h.ir, position.CreationOptions{
+			p, err := i.NewPosition(ctx, timeext.Now(), h.mp, h.pos, h.ir, position.CreationOptions{
 				Ticker:      "LQDT",
 				Type:        position.Long,
 				TargetPrice: "1.62",
@@ -96,13 +97,23 @@ func (h *handler) fakeMeData(c echo.Context) error {
 				return
 			}
 
-			p.OpenDate = time.Date(2024, time.May, 5, 13, 31, 32, 0, time.Local)
+			p.OpenDate = time.Date(2024, time.May, 5, 13, 31, 32, 0, timeext.Moscow)
 			p.OpenPrice = decimal.NewFromFloat32(1.3942)
 
+			// backdated, let the history be reconstructed from the fields
+			p.History = nil
+
 			if err := h.pos.Update(ctx, p); err != nil {
 				e = errors.Join(err, e)
 				return
 			}
+
+			// published back then, Publish would reopen the position now
+			i.Status = idea.Active
+			if err := h.ir.Update(ctx, i); err != nil {
+				e = errors.Join(err, e)
+				return
+			}
 		}()
 
 		// fake sofl
@@ -116,7 +127,7 @@ func (h *handler) fakeMeData(c echo.Context) error {
 				return
 			}
 
-			p, err := i.NewPosition(ctx, h.mp, h.pos, h.ir, position.CreationOptions{
+			p, err := i.NewPosition(ctx, timeext.Now(), h.mp, h.pos, h.ir, position.CreationOptions{
 				Ticker:      "SOFL",
 				Type:        position.Long,
 				TargetPrice: "200",
@@ -128,13 +139,22 @@ func (h *handler) fakeMeData(c echo.Context) error {
 				return
 			}
 
-			p.OpenDate = time.Date(2024, time.April, 29, 13, 31, 32, 0, time.Local)
+			p.OpenDate = time.Date(2024, time.April, 29, 13, 31, 32, 0, timeext.Moscow)
 			p.OpenPrice = decimal.NewFromFloat(173.45)
+			p.History = nil
+
 			if err := h.pos.Update(ctx, p); err != nil {
 				e = errors.Join(err, e)
 				h.log.Error("failed to fake data", "err", err)
 				return
 			}
+
+			i.Status = idea.Active
+			if err := h.ir.Update(ctx, i); err != nil {
+				e = errors.Join(err, e)
+				h.log.Error("failed to fake data", "err", err)
+				return
+			}
 		}()
 
 		// fake mgnt
@@ -147,7 +167,7 @@ func (h *handler) fakeMeData(c echo.Context) error {
 				return
 			}
 
-			p, err := i.NewPosition(ctx, h.mp, h.pos, h.ir, position.CreationOptions{
+			p, err := i.NewPosition(ctx, timeext.Now(), h.mp, h.pos, h.ir, position.CreationOptions{
 				Ticker:      "MGNT",
 				Type:        position.Long,
 				TargetPrice: "11000",
@@ -160,10 +180,12 @@ func (h *handler) fakeMeData(c echo.Context) error {
 
 			p.Status = position.Closed
 			p.ClosedPrice = decimal.NewFromInt(8800)
-			p.Deadline = time.Date(2024, time.May, 5, 13, 31, 32, 0, time.Local)
-			p.OpenDate = time.Date(2024, time.March, 10, 13, 31, 32, 0, time.Local)
+			p.Deadline = time.Date(2024, time.May, 5, 13, 31, 32, 0, timeext.Moscow)
+			p.OpenDate = time.Date(2024, time.March, 10, 13, 31, 32, 0, timeext.Moscow)
 			p.OpenPrice = decimal.NewFromInt(7841)
 
+			p.History = nil
+
 			if err := h.pos.Update(ctx, p); err != nil {
 				e = errors.Join(err, e)
 				return
@@ -188,7 +210,7 @@ func (h *handler) fakeMeData(c echo.Context) error {
 				return
 			}
 
-			p, err := i.NewPosition(ctx, h.mp, h.pos, h.ir, position.CreationOptions{
+			p, err := i.NewPosition(ctx, timeext.Now(), h.mp, h.pos, h.ir, position.CreationOptions{
 				Ticker:      "YNDX",
 				Type:        position.Long,
 				TargetPrice: "10000",
@@ -200,12 +222,14 @@ func (h *handler) fakeMeData(c echo.Context) error {
 				return
 			}
 			p.TargetPrice = decimal.NewFromFloat(4000)
-			p.OpenDate = time.Date(2023, time.December, 4, 13, 31, 32, 0, time.Local)
+			p.OpenDate = time.Date(2023, time.December, 4, 13, 31, 32, 0, timeext.Moscow)
 			p.OpenPrice = decimal.NewFromFloat(2387)
 			p.ClosedPrice = decimal.NewFromFloat(3933.4)
-			p.Deadline = time.Date(2024, time.March, 25, 0, 0, 0, 0, time.Local)
+			p.Deadline = time.Date(2024, time.March, 25, 0, 0, 0, 0, timeext.Moscow)
 			p.Status = position.Closed
 
+			p.History = nil
+
 			if err := h.pos.Update(ctx, p); err != nil {
 				e = errors.Join(err, e)
 				h.log.Error("failed to fake data", "err", err)
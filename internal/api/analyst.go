@@ -40,13 +40,19 @@ func (h *handler) getAnalyst(c echo.Context) error {
 		return ui.Render404(c)
 	}
 
-	ideas, err := a.Ideas(c.Request().Context(), h.ir)
+	// readers see only the published ideas, the owner also the drafts and the archive
+	isOwner := c.Get("isOwner").(bool)
+	var ss []idea.Status
+	if !isOwner {
+		ss = idea.Public
+	}
+
+	ideas, err := a.Ideas(c.Request().Context(), h.ir, ss...)
 	if err != nil {
-		h.log.Error("couldn't create position", "err", err)
+		h.log.Error("couldn't find ideas", "err", err)
 		return c.Redirect(307, "/500")
 	}
 
-	isOwner := c.Get("isOwner").(bool)
 	if isOwner {
 		return ui.Owner(a, ideas).Render(c)
 	} else {
@@ -79,14 +85,16 @@ func (h *handler) addIdea(c echo.Context) error {
 	}
 
 	i, err := a.NewIdea(c.Request().Context(), h.ir, io)
-	if errors.Is(err, idea.ErrConflict) || errors.Is(err, idea.ErrNameTooShort) || errors.Is(err, idea.ErrNameTooLong) {
+	if errors.Is(err, idea.ErrConflict) || errors.Is(err, idea.ErrNameTooShort) || errors.Is(err, idea.ErrNameTooLong) || errors.Is(err, idea.ErrBenchmark) {
 		ui.IdeaForm{
-			AnalystSlug:  a.Slug,
-			PrevName:     io.Name,
-			PrevLink:     io.SourceLink,
-			NameTooLong:  errors.Is(err, idea.ErrNameTooLong),
-			NameTooShort: errors.Is(err, idea.ErrNameTooShort),
-			NameTaken:    errors.Is(err, idea.ErrConflict),
+			AnalystSlug:   a.Slug,
+			PrevName:      io.Name,
+			PrevLink:      io.SourceLink,
+			PrevBenchmark: io.Benchmark,
+			Benchmarks:    idea.Benchmarks,
+			NameTooLong:   errors.Is(err, idea.ErrNameTooLong),
+			NameTooShort:  errors.Is(err, idea.ErrNameTooShort),
+			NameTaken:     errors.Is(err, idea.ErrConflict),
 		}.Render(c)
 		return err
 	} else if err != nil {
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"changemedaddy/internal/aggregate/analyst"
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/backtest"
+	"changemedaddy/internal/ui"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/greatcloak/decimal"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// backtestMaxAge is how long an analyst's priced positions are reused,
+	// pricing every position is too slow for each page view.
+	backtestMaxAge = 10 * time.Minute
+	// maxAmountLen keeps the amounts plain numbers well past backtest.MaxCapital,
+	// exponents of arbitrary size would take the decimals long to compare.
+	maxAmountLen = 20
+)
+
+// backtestOptions reads the capital, sizing and per_idea query params over the defaults.
+func backtestOptions(c echo.Context) (backtest.Options, error) {
+	opt := backtest.DefaultOptions
+	if s := c.QueryParam("sizing"); s != "" {
+		opt.Sizing = backtest.Sizing(s)
+	}
+
+	var parseError error
+	for name, d := range map[string]*decimal.Decimal{"capital": &opt.Capital, "per_idea": &opt.PerIdea} {
+		s := c.QueryParam(name)
+		if s == "" {
+			continue
+		}
+		if len(s) > maxAmountLen || strings.ContainsAny(s, "eE") {
+			parseError = errors.Join(parseError, fmt.Errorf("%w: %s is %q", backtest.ErrCapital, name, s))
+			continue
+		}
+		v, err := decimal.NewFromString(s)
+		if err != nil {
+			parseError = errors.Join(parseError, err, backtest.ErrCapital)
+			continue
+		}
+		*d = v
+	}
+	if parseError != nil {
+		return opt, parseError
+	}
+	return opt, opt.Validate()
+}
+
+func (h *handler) analystBacktest(ctx context.Context, a *analyst.Analyst, opt backtest.Options) (backtest.Result, error) {
+	pf, ok := h.backtests.Get(a.Slug)
+	if ok {
+		return pf.Run(opt)
+	}
+
+	ideas, err := a.Ideas(ctx, h.ir, idea.Public...)
+	if err != nil {
+		return backtest.Result{}, fmt.Errorf("couldn't get ideas: %w", err)
+	}
+
+	followed := make([]backtest.Idea, len(ideas))
+	for n, i := range ideas {
+		pp, err := h.ideaPositions(ctx, i)
+		if err != nil {
+			return backtest.Result{}, err
+		}
+		followed[n] = backtest.Idea{Slug: i.Slug, Positions: pp}
+	}
+
+	pf, err = backtest.Prepare(ctx, h.mp, followed)
+	if err != nil {
+		return backtest.Result{}, err
+	}
+	h.backtests.Set(a.Slug, pf)
+	return pf.Run(opt)
+}
+
+func (h *handler) getAnalystBacktest(c echo.Context) error {
+	return h.backtest(c, "html")
+}
+
+func (h *handler) getAnalystBacktestJSON(c echo.Context) error {
+	return h.backtest(c, "json")
+}
+
+func (h *handler) backtest(c echo.Context, format string) error {
+	a := c.Get("analyst").(*analyst.Analyst)
+
+	opt, err := backtestOptions(c)
+	if err != nil {
+		h.log.Debug("got bad backtest options", "err", err)
+		return c.NoContent(http.StatusBadRequest)
+	}
+
+	res, err := h.analystBacktest(c.Request().Context(), a, opt)
+	if errors.Is(err, backtest.ErrCapital) || errors.Is(err, backtest.ErrSizing) {
+		return c.NoContent(http.StatusBadRequest)
+	} else if err != nil {
+		h.log.Error("couldn't run backtest", "slug", a.Slug, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	if format == "json" {
+		return c.JSON(http.StatusOK, res)
+	}
+	return ui.Backtest(a.Slug, opt, res).Render(c)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"changemedaddy/internal/aggregate/analyst"
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/ui"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// benchmarkMaxAge is how long a found index is reused, indices don't change.
+const benchmarkMaxAge = 24 * time.Hour
+
+func (h *handler) benchmark(ctx context.Context, ticker string) (*instrument.Instrument, error) {
+	if b, ok := h.benchmarks.Get(ticker); ok {
+		return b, nil
+	}
+
+	b, err := h.mp.FindIndex(ctx, ticker)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find benchmark %s: %w", ticker, err)
+	}
+	h.benchmarks.Set(ticker, b)
+	return b, nil
+}
+
+// relative compares the position to the idea's benchmark. It's best effort:
+// without a benchmark quote the position is just shown without alpha.
+func (h *handler) relative(ctx context.Context, i *idea.Idea, wp position.WithProfit) (position.Relative, bool) {
+	b, err := h.benchmark(ctx, i.BenchmarkTicker())
+	if err != nil {
+		h.log.Warn("couldn't get benchmark", "idea", i.Slug, "err", err)
+		return position.Relative{}, false
+	}
+
+	r, err := wp.Against(ctx, h.mp, b)
+	if err != nil {
+		h.log.Warn("couldn't compare position to benchmark", "id", wp.ID, "benchmark", b.Ticker, "err", err)
+		return position.Relative{}, false
+	}
+	return r, true
+}
+
+func (h *handler) positionComponent(ctx context.Context, isOwner bool, i *idea.Idea, wp position.WithProfit) ui.PositionComponent {
+	pc := ui.Position(isOwner, i.AuthorSlug, i.Slug, wp)
+	pc.IsDraft = i.Status == idea.Draft
+	if r, ok := h.relative(ctx, i, wp); ok {
+		pc = pc.WithBenchmark(r)
+	}
+	return pc
+}
+
+func (h *handler) ideaRelatives(ctx context.Context, i *idea.Idea) ([]position.Relative, error) {
+	pp, err := h.ideaPositions(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	var rr []position.Relative
+	for _, p := range pp {
+		wp, err := p.WithProfit(ctx, h.mp)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get profit of position (id %d): %w", p.ID, err)
+		}
+		if r, ok := h.relative(ctx, i, wp); ok {
+			rr = append(rr, r)
+		}
+	}
+	return rr, nil
+}
+
+func (h *handler) getIdeaAlpha(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+
+	rr, err := h.ideaRelatives(c.Request().Context(), i)
+	if err != nil {
+		h.log.Error("couldn't get idea alpha", "idea", i.Slug, "err", err)
+		return c.NoContent(500)
+	}
+
+	return ui.IdeaAlpha(i, rr).Render(c)
+}
+
+func (h *handler) getAnalystAlpha(c echo.Context) error {
+	a := c.Get("analyst").(*analyst.Analyst)
+	ctx := c.Request().Context()
+
+	ideas, err := a.Ideas(ctx, h.ir, idea.Public...)
+	if err != nil {
+		h.log.Error("couldn't get ideas for alpha", "slug", a.Slug, "err", err)
+		return c.NoContent(500)
+	}
+
+	rr := make(map[string][]position.Relative, len(ideas))
+	for _, i := range ideas {
+		rr[i.Slug], err = h.ideaRelatives(ctx, i)
+		if err != nil {
+			h.log.Error("couldn't get idea alpha", "idea", i.Slug, "err", err)
+			return c.NoContent(500)
+		}
+	}
+
+	return ui.AnalystAlpha(a.Slug, ideas, rr).Render(c)
+}
@@ -1,10 +1,17 @@
 package api
 
 import (
+	"bytes"
 	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/ui"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -15,20 +22,24 @@ func (h *handler) getChartData(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	ticker := c.Param("ticker")
-	openedAt, err := time.ParseInLocation(chart.DateFormat, strings.Replace(c.Param("openedAt"), "%20", " ", -1), time.Local)
+	openedAt, err := time.ParseInLocation(chart.DateFormat, strings.Replace(c.Param("openedAt"), "%20", " ", -1), timeext.Moscow)
 	if err != nil {
 		_ = c.Blob(http.StatusBadRequest, "application/json", []byte{})
 		return err
 	}
-	deadline, err := time.ParseInLocation(chart.DateFormat, strings.Replace(c.Param("deadline"), "%20", " ", -1), time.Local)
+	deadline, err := time.ParseInLocation(chart.DateFormat, strings.Replace(c.Param("deadline"), "%20", " ", -1), timeext.Moscow)
 	if err != nil {
 		_ = c.Blob(http.StatusBadRequest, "application/json", []byte{})
 		return err
 	}
-	marketInterval, err := strconv.Atoi(c.Param("interval"))
-	if err != nil {
-		_ = c.Blob(http.StatusBadRequest, "application/json", []byte{})
-		return err
+
+	interval := chart.AutoInterval(openedAt, timeext.Min(deadline, time.Now()))
+	if raw := c.Param("interval"); raw != "" {
+		interval, err = chart.ParseInterval(raw)
+		if err != nil {
+			_ = c.Blob(http.StatusBadRequest, "application/json", []byte{})
+			return err
+		}
 	}
 
 	i, err := h.mp.Find(ctx, ticker)
@@ -36,13 +47,13 @@ func (h *handler) getChartData(c echo.Context) error {
 		_ = c.Blob(http.StatusBadRequest, "application/json", []byte{})
 		return err
 	}
-	interval, err := i.WithInterval(ctx, openedAt, deadline, marketInterval)
+	wi, err := i.WithInterval(ctx, openedAt, deadline, interval)
 	if err != nil {
 		_ = c.Blob(http.StatusBadRequest, "application/json", []byte{})
 		return err
 	}
 
-	candles, err := h.mp.GetCandles(ctx, &interval)
+	candles, err := h.mp.GetCandles(ctx, &wi)
 	if err != nil {
 		_ = c.Blob(http.StatusInternalServerError, "application/json", []byte{})
 		return err
@@ -54,3 +65,92 @@ func (h *handler) getChartData(c echo.Context) error {
 	}
 	return c.Blob(http.StatusOK, "application/json", jsonData)
 }
+
+func (h *handler) positionCandles(ctx context.Context, p *position.Position, interval chart.Interval) ([]chart.Candle, error) {
+	return p.Candles(ctx, h.mp, p.OpenDate, p.Deadline, interval)
+}
+
+// getPositionChart returns the candles of the position's lifespan along with its annotations.
+// The interval is picked automatically unless given in the interval query param.
+func (h *handler) getPositionChart(c echo.Context) error {
+	p := c.Get("position").(*position.Position)
+	now := time.Now()
+
+	interval := chart.AutoInterval(p.OpenDate, timeext.Min(p.Deadline, now))
+	if raw := c.QueryParam("interval"); raw != "" {
+		var err error
+		interval, err = chart.ParseInterval(raw)
+		if err != nil {
+			h.log.Debug("got bad chart interval", "interval", raw, "err", err)
+			return c.NoContent(http.StatusBadRequest)
+		}
+	}
+
+	candles, err := h.positionCandles(c.Request().Context(), p, interval)
+	if err != nil {
+		h.log.Error("couldn't get position chart", "id", p.ID, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return ui.PositionChart(p, candles, now).Render(c)
+}
+
+const imageMaxAge = 10 * time.Minute
+
+type chartImage struct {
+	body []byte
+	etag string
+}
+
+func (h *handler) getPositionSVG(c echo.Context) error {
+	return h.positionImage(c, "svg")
+}
+
+func (h *handler) getPositionPNG(c echo.Context) error {
+	return h.positionImage(c, "png")
+}
+
+// positionImage serves the chart of the position for link previews. Images are
+// cached per position version, so edits show up without waiting for the cache.
+func (h *handler) positionImage(c echo.Context, format string) error {
+	p := c.Get("position").(*position.Position)
+	key := fmt.Sprintf("%d/%d/%s/%s", p.ID, len(p.Changes()), p.Status, format)
+
+	img, ok := h.images.Get(key)
+	if !ok {
+		candles, err := h.positionCandles(c.Request().Context(), p, chart.AutoInterval(p.OpenDate, timeext.Min(p.Deadline, time.Now())))
+		if err != nil {
+			h.log.Error("couldn't get candles for position image", "id", p.ID, "err", err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		var (
+			buf bytes.Buffer
+			ci  = ui.PositionImage(p, candles)
+		)
+		if format == "svg" {
+			err = ci.SVG(&buf)
+		} else {
+			err = ci.PNG(&buf)
+		}
+		if err != nil {
+			h.log.Error("couldn't render position image", "id", p.ID, "format", format, "err", err)
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+		img = chartImage{body: buf.Bytes(), etag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+		h.images.Set(key, img)
+	}
+
+	c.Response().Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(imageMaxAge.Seconds())))
+	if notModified(c, img.etag, time.Time{}) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	contentType := "image/png"
+	if format == "svg" {
+		contentType = "image/svg+xml"
+	}
+	return c.Blob(http.StatusOK, contentType, img.body)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"changemedaddy/internal/aggregate/analyst"
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/ui"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handler) ideaPositions(ctx context.Context, i *idea.Idea) ([]*position.Position, error) {
+	pp := make([]*position.Position, 0, len(i.PositionIDs))
+	for _, id := range i.PositionIDs {
+		p, err := h.pos.Find(ctx, id)
+		if errors.Is(err, position.ErrNotFound) {
+			h.log.Warn("idea refers to a missing position", "idea", i.Slug, "id", id)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("couldn't find position (id %d): %w", id, err)
+		}
+		pp = append(pp, p)
+	}
+
+	return pp, nil
+}
+
+func baseURL(c echo.Context) string {
+	return c.Scheme() + "://" + c.Request().Host
+}
+
+// notModified answers conditional GETs, it must be called before writing the body.
+func notModified(c echo.Context, etag string, updated time.Time) bool {
+	c.Response().Header().Set("ETag", etag)
+	if !updated.IsZero() {
+		c.Response().Header().Set("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	}
+
+	req := c.Request()
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			if t = strings.TrimSpace(t); t == etag || t == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims, err := http.ParseTime(req.Header.Get("If-Modified-Since")); err == nil && !updated.IsZero() {
+		return !updated.Truncate(time.Second).After(ims)
+	}
+
+	return false
+}
+
+func (h *handler) renderFeed(c echo.Context, f ui.FeedComponent) error {
+	if notModified(c, f.ETag(), f.Updated) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return f.Render(c)
+}
+
+func (h *handler) getAnalystFeed(c echo.Context) error {
+	a := c.Get("analyst").(*analyst.Analyst)
+	ctx := c.Request().Context()
+
+	ideas, err := a.Ideas(ctx, h.ir, idea.Public...)
+	if err != nil {
+		h.log.Error("couldn't get ideas for feed", "slug", a.Slug, "err", err)
+		return c.NoContent(500)
+	}
+
+	positions := make(map[string][]*position.Position, len(ideas))
+	for _, i := range ideas {
+		pp, err := h.ideaPositions(ctx, i)
+		if err != nil {
+			h.log.Error("couldn't get positions for feed", "idea", i.Slug, "err", err)
+			return c.NoContent(500)
+		}
+		positions[i.Slug] = pp
+	}
+
+	return h.renderFeed(c, ui.AnalystFeed(baseURL(c), a, ideas, positions))
+}
+
+func (h *handler) getIdeaFeed(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+
+	pp, err := h.ideaPositions(c.Request().Context(), i)
+	if err != nil {
+		h.log.Error("couldn't get positions for feed", "idea", i.Slug, "err", err)
+		return c.NoContent(500)
+	}
+
+	return h.renderFeed(c, ui.IdeaFeed(baseURL(c), i, pp))
+}
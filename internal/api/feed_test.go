@@ -0,0 +1,58 @@
+package api
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/ui"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestFeedConditionalGet(t *testing.T) {
+	var (
+		h = &handler{}
+		e = echo.New()
+		f = ui.IdeaFeed("https://idea-x3.ru", &idea.Idea{Name: "Сбер", Slug: "sber", AuthorSlug: "mk", CreatedAt: time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)}, nil)
+	)
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/analyst/mk/idea/sber/feed.atom", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		if err := h.renderFeed(e.NewContext(req, rec), f); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec
+	}
+
+	first := get("", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("want 200 with an ETag, got %d and %q", first.Code, etag)
+	}
+
+	cases := []struct {
+		header, value string
+		want          int
+	}{
+		{"If-None-Match", etag, http.StatusNotModified},
+		{"If-None-Match", `"other", ` + etag, http.StatusNotModified},
+		{"If-None-Match", `"other"`, http.StatusOK},
+		{"If-Modified-Since", first.Header().Get("Last-Modified"), http.StatusNotModified},
+		{"If-Modified-Since", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat), http.StatusOK},
+	}
+	for _, c := range cases {
+		rec := get(c.header, c.value)
+		if rec.Code != c.want {
+			t.Errorf("%s: %s: want %d, got %d", c.header, c.value, c.want, rec.Code)
+		}
+		if c.want == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Errorf("%s: %s: want no body with 304", c.header, c.value)
+		}
+	}
+}
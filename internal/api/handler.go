@@ -3,11 +3,17 @@ package api
 import (
 	"changemedaddy/internal/aggregate/analyst"
 	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/backtest"
 	"changemedaddy/internal/domain/chart"
 	"changemedaddy/internal/domain/instrument"
 	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/domain/subscription"
+	"changemedaddy/internal/domain/visit"
+	"changemedaddy/internal/domain/webhook"
+	"changemedaddy/internal/pkg/cache"
 	"changemedaddy/internal/ui"
 	"context"
+	"io"
 	"log/slog"
 	"time"
 
@@ -22,24 +28,34 @@ type (
 		Save(ctx context.Context, p *position.Position) error
 		Find(ctx context.Context, id int) (*position.Position, error)
 		Update(ctx context.Context, p *position.Position) error
+		Delete(ctx context.Context, id int) error
+		CountActive(ctx context.Context) (int64, error)
 	}
 
 	ideaRepo interface {
 		Save(ctx context.Context, i *idea.Idea) error
 		Update(ctx context.Context, i *idea.Idea) error
-		FindByAnalystSlug(ctx context.Context, analystSLug string) ([]*idea.Idea, error)
+		FindByAnalystSlug(ctx context.Context, analystSLug string, ss ...idea.Status) ([]*idea.Idea, error)
 		FindBySlug(ctx context.Context, analystSlug string, slug string) (*idea.Idea, error)
+		CountActive(ctx context.Context) (int64, error)
 	}
 
 	marketProvider interface {
 		Find(ctx context.Context, ticker string) (*instrument.Instrument, error)
+		FindIndex(ctx context.Context, ticker string) (*instrument.Instrument, error)
 		Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error)
 		GetCandles(ctx context.Context, i *instrument.WithInterval) ([]chart.Candle, error)
+		Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error)
+	}
+
+	instrumentSearcher interface {
+		Search(ctx context.Context, query string, limit int) ([]instrument.Listing, error)
 	}
 
 	analystRepo interface {
 		Save(ctx context.Context, a *analyst.Analyst) error
 		FindBySlug(ctx context.Context, slug string) (*analyst.Analyst, error)
+		Count(ctx context.Context) (int64, error)
 	}
 
 	tokenAuthService interface {
@@ -47,20 +63,70 @@ type (
 		RegisterAs(ctx context.Context, token, name string) error
 	}
 
+	subscriptionRepo interface {
+		Save(ctx context.Context, s *subscription.Subscription) error
+		Confirm(ctx context.Context, id string) error
+		Delete(ctx context.Context, id string) error
+	}
+
+	notifier interface {
+		RequestConfirmation(ctx context.Context, s *subscription.Subscription) error
+	}
+
+	webhookRepo interface {
+		SaveEndpoint(ctx context.Context, e *webhook.Endpoint) error
+		DeleteEndpoint(ctx context.Context, analystSlug, id string) error
+		FindEndpoint(ctx context.Context, id string) (*webhook.Endpoint, error)
+		EndpointsByAnalyst(ctx context.Context, analystSlug string) ([]*webhook.Endpoint, error)
+		DeliveriesByAnalyst(ctx context.Context, analystSlug string, limit int) ([]webhook.Delivery, error)
+	}
+
+	hookSender interface {
+		SendTest(ctx context.Context, e *webhook.Endpoint) (webhook.Delivery, error)
+	}
+
+	// blobStore keeps the images attached to ideas.
+	blobStore interface {
+		Put(ctx context.Context, key string, data []byte) error
+		Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+		Delete(ctx context.Context, key string) error
+	}
+
+	readinessChecker interface {
+		Ready(ctx context.Context) (map[string]error, bool)
+	}
+
 	visitorsRepo interface {
-		Add(ctx context.Context, a *analyst.Analyst, ip string)
-		GetAll(ctx context.Context) map[string]int
+		Stats(ctx context.Context, analystSlug string, from, to time.Time) (visit.Stats, error)
+		AllStats(ctx context.Context, from, to time.Time) ([]visit.Stats, error)
+	}
+
+	// visitRecorder records page views off the request path.
+	visitRecorder interface {
+		Record(opt visit.ViewOptions)
 	}
 )
 
 type handler struct {
 	pos positionRepo
 	vr  visitorsRepo
+	vs  visitRecorder
 	mp  marketProvider
+	is  instrumentSearcher
 	ir  ideaRepo
 	ar  analystRepo
 	as  tokenAuthService
+	sr  subscriptionRepo
+	nd  notifier
+	wr  webhookRepo
+	hs  hookSender
+	bs  blobStore
+	hc  readinessChecker
 	log *slog.Logger
+
+	images     *cache.TTL[string, chartImage]
+	benchmarks *cache.TTL[string, *instrument.Instrument]
+	backtests  *cache.TTL[string, *backtest.Portfolio]
 }
 
 func (h *handler) MustEcho() *echo.Echo {
@@ -69,6 +135,7 @@ func (h *handler) MustEcho() *echo.Echo {
 
 	// e.Pre(middleware.HTTPSRedirect())
 	e.Use(slogecho.New(h.log))
+	e.Use(h.metricsMW)
 	e.Use(h.adminMW)
 	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
 		Skipper:      middleware.DefaultSkipper,
@@ -82,22 +149,68 @@ func (h *handler) MustEcho() *echo.Echo {
 
 	ui.NewRenderer().Register(e)
 
+	e.GET("/healthz", h.healthz)
+	e.GET("/readyz", h.readyz)
+	e.GET("/metrics", h.metrics, h.adminonlyMW)
+
+	e.GET("/chart-data/:ticker/from/:openedAt/to/:deadline", h.getChartData)
 	e.GET("/chart-data/:ticker/from/:openedAt/to/:deadline/interval/:interval", h.getChartData)
 
+	e.GET("/instruments", h.searchInstruments)
+	e.GET("/instruments/suggest", h.suggestInstruments)
+
+	e.GET("/unsubscribe/:id", h.unsubscribeForm)
+	e.POST("/unsubscribe/:id", h.unsubscribe)
+	e.GET("/subscription/:id/confirm", h.confirmSubscriptionForm)
+	e.POST("/subscription/:id/confirm", h.confirmSubscription)
+
+	e.GET("/attachments/*", h.getAttachment)
+
 	e.GET("/token_auth/:token", h.tokenAuth)
 	e.POST("/token_auth/:token", h.tokenAuth)
 
 	ae := e.Group("/analyst", h.analystMiddleware, h.ownerMW)
-	ae.GET("/:analystSlug", h.getAnalyst)
+	ae.GET("/:analystSlug", h.getAnalyst, h.visitorsMW)
+	ae.GET("/:analystSlug/stats", h.getAnalystStats, h.onlyOwnerMW)
+	ae.GET("/:analystSlug/alpha", h.getAnalystAlpha, h.onlyOwnerMW)
+	ae.GET("/:analystSlug/backtest", h.getAnalystBacktest)
+	ae.GET("/:analystSlug/backtest.json", h.getAnalystBacktestJSON)
+	ae.GET("/:analystSlug/feed.atom", h.getAnalystFeed)
+	ae.GET("/:analystSlug/idea/:ideaSlug/feed.atom", h.getIdeaFeed, h.ideaMW)
+	ae.GET("/:analystSlug/webhooks", h.getWebhooks, h.onlyOwnerMW)
+	ae.POST("/:analystSlug/webhooks", h.addWebhook, h.onlyOwnerMW)
+	ae.POST("/:analystSlug/webhooks/:webhookID/delete", h.deleteWebhook, h.onlyOwnerMW)
+	ae.POST("/:analystSlug/webhooks/:webhookID/test", h.testWebhook, h.onlyOwnerMW)
+	ae.GET("/:analystSlug/subscribe", h.subscribeForm)
+	ae.POST("/:analystSlug/subscribe", h.subscribe)
+	ae.GET("/:analystSlug/idea/:ideaSlug/subscribe", h.subscribeForm, h.ideaMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/subscribe", h.subscribe, h.ideaMW)
 	ae.GET("/:analystSlug/idea/:ideaSlug", h.getIdea, h.ideaMW, h.visitorsMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/alpha", h.getIdeaAlpha, h.ideaMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/versions", h.getIdeaVersions, h.ideaMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/body/edit", h.editIdeaBodyForm, h.onlyOwnerMW, h.ideaMW)
+	ae.PATCH("/:analystSlug/idea/:ideaSlug/body", h.editIdeaBody, h.onlyOwnerMW, h.ideaMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/attachments", h.addAttachment, h.onlyOwnerMW, h.ideaMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/status", h.getIdeaStatus, h.onlyOwnerMW, h.ideaMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/schedule", h.scheduleIdea, h.onlyOwnerMW, h.ideaMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/publish", h.postIdeaPublish, h.onlyOwnerMW, h.ideaMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/close", h.postIdeaClose, h.onlyOwnerMW, h.ideaMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/archive", h.postIdeaArchive, h.onlyOwnerMW, h.ideaMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/restore", h.postIdeaRestore, h.onlyOwnerMW, h.ideaMW)
 	ae.GET("/:analystSlug/new_idea", h.ideaForm)
-	ae.GET("/:analystSlug/idea/:ideaSlug/position/:positionID", h.getPosition, h.ideaMW, h.positionMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/position/:positionID", h.getPosition, h.ideaMW, h.positionMW, h.visitorsMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/position/:positionID/chart", h.getPositionChart, h.ideaMW, h.positionMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/position/:positionID/chart.svg", h.getPositionSVG, h.ideaMW, h.positionMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/position/:positionID/chart.png", h.getPositionPNG, h.ideaMW, h.positionMW)
 
 	ae.GET("/:analystSlug/idea/:ideaSlug/new_position", h.positionForm, h.onlyOwnerMW, h.ideaMW)
 	ae.POST("/:analystSlug/idea", h.addIdea, h.onlyOwnerMW)
 	ae.POST("/:analystSlug/idea/:ideaSlug/position", h.addPosition, h.onlyOwnerMW, h.ideaMW)
 	ae.GET("/:analystSlug/idea/:ideaSlug/edit_position/:positionID", h.editPositionForm, h.onlyOwnerMW, h.ideaMW, h.positionMW)
 	ae.PATCH("/:analystSlug/idea/:ideaSlug/position/:positionID", h.editPosition, h.onlyOwnerMW, h.ideaMW, h.positionMW)
+	ae.GET("/:analystSlug/idea/:ideaSlug/redraft_position/:positionID", h.redraftPositionForm, h.onlyOwnerMW, h.ideaMW, h.positionMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/position/:positionID/redraft", h.redraftPosition, h.onlyOwnerMW, h.ideaMW, h.positionMW)
+	ae.POST("/:analystSlug/idea/:ideaSlug/position/:positionID/delete", h.deletePosition, h.onlyOwnerMW, h.ideaMW, h.positionMW)
 
 	e.GET("/empty", func(c echo.Context) error { return c.NoContent(200) })
 
@@ -107,7 +220,7 @@ func (h *handler) MustEcho() *echo.Echo {
 	e.GET("/500", func(c echo.Context) error { return ui.Render500(c) })
 	e.GET("/wrongtoken", func(c echo.Context) error { return ui.RenderWrongToken(c) })
 
-	e.GET("/analytics", h.getVisitorsCount, h.adminonlyMW)
+	e.GET("/analytics", h.getAnalytics, h.adminonlyMW)
 
 	e.GET("/makeadmin/:password", h.makeAdmin)
 	e.GET("/fakemedata", h.fakeMeData, h.adminonlyMW)
@@ -120,14 +233,29 @@ func (h *handler) MustEcho() *echo.Echo {
 	return e
 }
 
-func NewHandler(pr positionRepo, vr visitorsRepo, ir ideaRepo, mp marketProvider, ar analystRepo, as tokenAuthService, log *slog.Logger) *handler {
-	return &handler{
+func NewHandler(pr positionRepo, vr visitorsRepo, vs visitRecorder, ir ideaRepo, mp marketProvider, is instrumentSearcher, ar analystRepo, as tokenAuthService, sr subscriptionRepo, nd notifier, wr webhookRepo, hs hookSender, bs blobStore, hc readinessChecker, log *slog.Logger) *handler {
+	h := &handler{
 		pos: pr,
 		vr:  vr,
+		vs:  vs,
 		mp:  mp,
+		is:  is,
 		ir:  ir,
 		ar:  ar,
 		as:  as,
+		sr:  sr,
+		nd:  nd,
+		wr:  wr,
+		hs:  hs,
+		bs:  bs,
+		hc:  hc,
 		log: log,
+
+		images:     cache.NewTTL[string, chartImage](imageMaxAge),
+		benchmarks: cache.NewTTL[string, *instrument.Instrument](benchmarkMaxAge),
+		backtests:  cache.NewTTL[string, *backtest.Portfolio](backtestMaxAge),
 	}
+	h.registerGauges()
+
+	return h
 }
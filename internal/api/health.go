@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"changemedaddy/internal/pkg/metrics"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var requestDuration = metrics.NewHistogramVec(
+	"http_request_duration_seconds",
+	"Latency of HTTP requests by route.",
+	metrics.DefBuckets,
+	"method", "route", "status",
+)
+
+func (h *handler) metricsMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		status := c.Response().Status
+		if he, ok := err.(*echo.HTTPError); ok {
+			status = he.Code
+		}
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		requestDuration.Observe(time.Since(start).Seconds(), c.Request().Method, route, strconv.Itoa(status))
+		return err
+	}
+}
+
+func (h *handler) registerGauges() {
+	metrics.NewGaugeFunc("positions_active", "Number of active positions.", func(ctx context.Context) (float64, error) {
+		n, err := h.pos.CountActive(ctx)
+		return float64(n), err
+	})
+	metrics.NewGaugeFunc("ideas_active", "Number of active ideas.", func(ctx context.Context) (float64, error) {
+		n, err := h.ir.CountActive(ctx)
+		return float64(n), err
+	})
+	metrics.NewGaugeFunc("analysts_total", "Number of registered analysts.", func(ctx context.Context) (float64, error) {
+		n, err := h.ar.Count(ctx)
+		return float64(n), err
+	})
+}
+
+func (h *handler) healthz(c echo.Context) error {
+	return c.String(200, "ok")
+}
+
+func (h *handler) readyz(c echo.Context) error {
+	errs, ok := h.hc.Ready(c.Request().Context())
+
+	checks := make(map[string]string, len(errs))
+	for name, err := range errs {
+		if err != nil {
+			// the errors name hosts and addresses, they stay in the logs
+			checks[name] = "fail"
+			h.log.Warn("readiness check failed", "check", name, "err", err)
+		} else {
+			checks[name] = "ok"
+		}
+	}
+
+	if !ok {
+		return c.JSON(503, checks)
+	}
+	return c.JSON(200, checks)
+}
+
+func (h *handler) metrics(c echo.Context) error {
+	var buf bytes.Buffer
+	if err := metrics.Default.WriteText(c.Request().Context(), &buf); err != nil {
+		h.log.Error("couldn't write metrics", "err", err)
+		return c.NoContent(500)
+	}
+
+	return c.Blob(200, "text/plain; version=0.0.4; charset=utf-8", buf.Bytes())
+}
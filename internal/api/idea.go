@@ -4,8 +4,11 @@ import (
 	"changemedaddy/internal/aggregate/analyst"
 	"changemedaddy/internal/aggregate/idea"
 	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
 	"changemedaddy/internal/ui"
 	"errors"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -29,6 +32,12 @@ func (h *handler) ideaMW(next echo.HandlerFunc) echo.HandlerFunc {
 			i = ia
 		}
 
+		// drafts and archived ideas don't exist for readers
+		if isOwner, _ := c.Get("isOwner").(bool); i != nil && !isOwner && !i.IsPublic() {
+			h.log.Debug("reader tried to see a private idea", "slug", iSlug, "status", i.Status)
+			return c.Redirect(307, "/404")
+		}
+
 		if i != nil {
 			c.Set("idea", i)
 			return next(c)
@@ -41,7 +50,10 @@ func (h *handler) ideaMW(next echo.HandlerFunc) echo.HandlerFunc {
 func (h *handler) getIdea(c echo.Context) error {
 	i := c.Get("idea").(*idea.Idea)
 	isOwner := c.Get("isOwner").(bool)
-	return ui.Idea(i, isOwner).Render(c)
+
+	// a missing or malformed position just falls back to the latest one
+	shared, _ := strconv.Atoi(c.QueryParam("position"))
+	return ui.Idea(i, isOwner).WithPreview(baseURL(c), shared).Render(c)
 }
 
 func (h *handler) addPosition(c echo.Context) error {
@@ -52,21 +64,11 @@ func (h *handler) addPosition(c echo.Context) error {
 	}
 
 	i := c.Get("idea").(*idea.Idea)
-	p, err := i.NewPosition(c.Request().Context(), h.mp, h.pos, h.ir, opt)
-	if errors.Is(err, position.ErrTicker) || errors.Is(err, position.ErrParseType) || errors.Is(err, position.ErrTargetPrice) || errors.Is(err, position.ErrParseDeadline) {
-		pf := ui.PositionForm{
-			IdeaSlug:      i.Slug,
-			AnalystSlug:   i.AuthorSlug,
-			PrevTicker:    opt.Ticker,
-			WrongTicker:   errors.Is(err, position.ErrTicker),
-			PrevTarget:    opt.TargetPrice,
-			WrongTarget:   errors.Is(err, position.ErrTargetPrice),
-			PrevType:      opt.Type,
-			WrongType:     errors.Is(err, position.ErrParseType),
-			PrevDeadline:  opt.Deadline,
-			WrongDeadline: errors.Is(err, position.ErrParseDeadline),
-		}
-		return pf.Render(c)
+	p, err := i.NewPosition(c.Request().Context(), timeext.Now(), h.mp, h.pos, h.ir, opt)
+	if isPositionFormError(err) {
+		return wrongPosition(i, opt, err).Render(c)
+	} else if errors.Is(err, idea.ErrClosedIdeaModified) {
+		return ui.Render400(c)
 	} else if err != nil {
 		h.log.Error("couldn't create position", "err", err)
 		ui.Render500(c)
@@ -80,10 +82,116 @@ func (h *handler) addPosition(c echo.Context) error {
 		return err
 	}
 
-	return ui.Position(true, i.AuthorSlug, i.Slug, wp).Render(c)
+	return h.positionComponent(c.Request().Context(), true, i, wp).Render(c)
+}
+
+// isPositionFormError tells the mistakes in the position form from the failures.
+func isPositionFormError(err error) bool {
+	return errors.Is(err, position.ErrTicker) || errors.Is(err, position.ErrParseType) || errors.Is(err, position.ErrTargetPrice) || errors.Is(err, position.ErrTargetYield) || errors.Is(err, position.ErrParseDeadline) || errors.Is(err, position.ErrLegs) || errors.Is(err, position.ErrSpreadKind) || errors.Is(err, position.ErrFraction) || errors.Is(err, position.ErrPublishAt) || errors.Is(err, idea.ErrPendingDraft)
+}
+
+// wrongPosition is the position form filled back with opt, err marks the wrong fields.
+func wrongPosition(i *idea.Idea, opt position.CreationOptions, err error) ui.PositionForm {
+	return ui.PositionForm{
+		IdeaSlug:      i.Slug,
+		AnalystSlug:   i.AuthorSlug,
+		PrevTicker:    opt.Ticker,
+		WrongTicker:   errors.Is(err, position.ErrTicker),
+		PrevLegs:      opt.Legs,
+		PrevSpread:    opt.Spread,
+		WrongLegs:     errors.Is(err, position.ErrLegs) || errors.Is(err, position.ErrSpreadKind),
+		PrevTarget:    opt.TargetPrice,
+		WrongTarget:   opt.Targets == "" && errors.Is(err, position.ErrTargetPrice),
+		PrevTargets:   opt.Targets,
+		WrongTargets:  opt.Targets != "" && (errors.Is(err, position.ErrTargetPrice) || errors.Is(err, position.ErrFraction)),
+		PrevYield:     opt.TargetYield,
+		WrongYield:    errors.Is(err, position.ErrTargetYield),
+		PrevType:      opt.Type,
+		WrongType:     errors.Is(err, position.ErrParseType),
+		PrevDeadline:  opt.Deadline,
+		WrongDeadline: errors.Is(err, position.ErrParseDeadline),
+
+		PrevPublishAt:  opt.PublishAt,
+		WrongPublishAt: errors.Is(err, position.ErrPublishAt) || errors.Is(err, idea.ErrPendingDraft),
+	}
 }
 
 func (h *handler) positionForm(c echo.Context) error {
 	i := c.Get("idea").(*idea.Idea)
 	return ui.NewPosition(i.Slug, i.AuthorSlug).Render(c)
 }
+
+func (h *handler) getIdeaStatus(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+	return ui.IdeaStatus(i, nil).Render(c)
+}
+
+// scheduleIdea sets when a draft goes public, an empty time unschedules it.
+func (h *handler) scheduleIdea(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+
+	var at time.Time
+	if s := c.FormValue("publish_at"); s != "" {
+		var err error
+		if at, err = timeext.ParseDateTime(s); err != nil {
+			return ui.IdeaStatus(i, idea.ErrPublishAt).Render(c)
+		}
+	}
+
+	err := i.Schedule(c.Request().Context(), timeext.Now(), h.ir, at)
+	if errors.Is(err, idea.ErrStatus) || errors.Is(err, idea.ErrPublishAt) {
+		return ui.IdeaStatus(i, err).Render(c)
+	} else if err != nil {
+		h.log.Error("couldn't schedule idea", "slug", i.Slug, "err", err)
+		return ui.Render500(c)
+	}
+
+	return ui.IdeaStatus(i, nil).Render(c)
+}
+
+func (h *handler) postIdeaPublish(c echo.Context) error {
+	return h.changeIdeaStatus(c, "publish")
+}
+
+func (h *handler) postIdeaClose(c echo.Context) error {
+	return h.changeIdeaStatus(c, "close")
+}
+
+func (h *handler) postIdeaArchive(c echo.Context) error {
+	return h.changeIdeaStatus(c, "archive")
+}
+
+func (h *handler) postIdeaRestore(c echo.Context) error {
+	return h.changeIdeaStatus(c, "restore")
+}
+
+// changeIdeaStatus moves the idea through its lifecycle, answering with its
+// status and the reason if it couldn't move.
+func (h *handler) changeIdeaStatus(c echo.Context, action string) error {
+	i := c.Get("idea").(*idea.Idea)
+	ctx := c.Request().Context()
+
+	var (
+		err     error
+		changed = timeext.Now()
+	)
+	switch action {
+	case "publish":
+		err = i.Publish(ctx, changed, h.mp, h.pos, h.ir)
+	case "close":
+		err = i.Close(ctx, h.mp, h.pos, h.ir)
+	case "archive":
+		err = i.Archive(ctx, h.ir)
+	case "restore":
+		err = i.Restore(ctx, h.ir)
+	}
+
+	if errors.Is(err, idea.ErrStatus) || errors.Is(err, idea.ErrNoPositions) || errors.Is(err, position.ErrTargetPrice) || errors.Is(err, position.ErrParseDeadline) || errors.Is(err, position.ErrExpired) || errors.Is(err, position.ErrClosedPositionModified) {
+		return ui.IdeaStatus(i, err).Render(c)
+	} else if err != nil {
+		h.log.Error("couldn't change idea status", "slug", i.Slug, "action", action, "err", err)
+		return ui.Render500(c)
+	}
+
+	return ui.IdeaStatus(i, nil).Render(c)
+}
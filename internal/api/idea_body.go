@@ -0,0 +1,112 @@
+package api
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/ui"
+	"errors"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// attachmentTypes are what the attachments are served as, by the extension of their key.
+var attachmentTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+func (h *handler) editIdeaBodyForm(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+	return ui.EditIdeaBody(i, i.Body, nil).Render(c)
+}
+
+func (h *handler) editIdeaBody(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+
+	var b idea.Body
+	if err := c.Bind(&b); err != nil {
+		h.log.Warn("couldn't bind idea body", "err", err)
+		return ui.Render400(c)
+	}
+
+	err := i.EditBody(c.Request().Context(), h.ir, b)
+	if errors.Is(err, idea.ErrBodyTooLong) {
+		return ui.EditIdeaBody(i, b, err).Render(c)
+	} else if errors.Is(err, idea.ErrClosedIdeaModified) {
+		return ui.Render400(c)
+	} else if err != nil {
+		h.log.Error("couldn't edit idea body", "slug", i.Slug, "err", err)
+		return ui.Render500(c)
+	}
+
+	return ui.IdeaBody(i, true).Render(c)
+}
+
+func (h *handler) getIdeaVersions(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+	return ui.IdeaVersions(i).Render(c)
+}
+
+func (h *handler) addAttachment(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+
+	// leave room for the rest of the form, Attach checks the image itself
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, idea.MaxAttachmentSize+1<<20)
+	fh, err := c.FormFile("image")
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		return ui.Attachments(i, idea.ErrAttachmentTooBig).Render(c)
+	} else if err != nil {
+		h.log.Warn("couldn't get attachment from form", "err", err)
+		return ui.Render400(c)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		h.log.Error("couldn't open uploaded attachment", "err", err)
+		return ui.Render500(c)
+	}
+	defer f.Close()
+
+	_, err = i.Attach(c.Request().Context(), h.bs, h.ir, fh.Filename, f)
+	if errors.Is(err, idea.ErrAttachmentType) || errors.Is(err, idea.ErrAttachmentTooBig) || errors.Is(err, idea.ErrTooManyAttachments) {
+		return ui.Attachments(i, err).Render(c)
+	} else if errors.Is(err, idea.ErrClosedIdeaModified) {
+		return ui.Render400(c)
+	} else if err != nil {
+		h.log.Error("couldn't attach image", "slug", i.Slug, "err", err)
+		return ui.Render500(c)
+	}
+
+	return ui.Attachments(i, nil).Render(c)
+}
+
+// getAttachment serves an attached image. The keys are random and never
+// rewritten, so the images are cached for good.
+func (h *handler) getAttachment(c echo.Context) error {
+	key := c.Param("*")
+	ct, ok := attachmentTypes[path.Ext(key)]
+	if !ok {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	f, err := h.bs.Open(c.Request().Context(), key)
+	if errors.Is(err, idea.ErrAttachmentNotFound) {
+		return c.NoContent(http.StatusNotFound)
+	} else if err != nil {
+		h.log.Error("couldn't open attachment", "key", key, "err", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	defer f.Close()
+
+	hd := c.Response().Header()
+	hd.Set("Content-Type", ct)
+	hd.Set("X-Content-Type-Options", "nosniff")
+	hd.Set("Cache-Control", "public, max-age=31536000, immutable")
+	http.ServeContent(c.Response(), c.Request(), "", time.Time{}, f)
+	return nil
+}
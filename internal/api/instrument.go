@@ -0,0 +1,51 @@
+package api
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/ui"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// searchInstruments returns instruments matching ?q= by ticker, name or ISIN, best first.
+func (h *handler) searchInstruments(c echo.Context) error {
+	limit := defaultSearchLimit
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be a positive number"})
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	ll, err := h.is.Search(c.Request().Context(), c.QueryParam("q"), limit)
+	if err != nil {
+		h.log.Error("couldn't search instruments", "q", c.QueryParam("q"), "err", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "instrument search is unavailable"})
+	}
+
+	if ll == nil {
+		ll = []instrument.Listing{}
+	}
+	return c.JSON(http.StatusOK, ll)
+}
+
+// suggestInstruments renders ticker autocomplete options for the position form.
+func (h *handler) suggestInstruments(c echo.Context) error {
+	q := c.QueryParam("ticker")
+
+	ll, err := h.is.Search(c.Request().Context(), q, defaultSearchLimit)
+	if err != nil {
+		// the form still works without suggestions
+		h.log.Warn("couldn't suggest instruments", "q", q, "err", err)
+	}
+
+	return ui.TickerOptions(ll).Render(c)
+}
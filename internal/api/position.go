@@ -3,8 +3,10 @@ package api
 import (
 	"changemedaddy/internal/aggregate/idea"
 	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
 	"changemedaddy/internal/ui"
 	"errors"
+	"slices"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
@@ -19,6 +21,13 @@ func (h *handler) positionMW(next echo.HandlerFunc) echo.HandlerFunc {
 			return c.Redirect(307, "/404")
 		}
 
+		// an idea's URL shows only its own positions, drafts' ones are hidden
+		i := c.Get("idea").(*idea.Idea)
+		if !slices.Contains(i.PositionIDs, id) && !slices.Contains(i.PendingIDs, id) {
+			h.log.Debug("tried to get position of another idea", "id", param, "idea", i.Slug)
+			return c.Redirect(307, "/404")
+		}
+
 		ctx := c.Request().Context()
 		p, err := h.pos.Find(ctx, id)
 		if errors.Is(err, position.ErrNotFound) {
@@ -29,6 +38,11 @@ func (h *handler) positionMW(next echo.HandlerFunc) echo.HandlerFunc {
 			return c.Redirect(307, "/500")
 		}
 
+		if isOwner, _ := c.Get("isOwner").(bool); p.Status == position.Pending && !isOwner {
+			h.log.Debug("reader tried to see a pending position", "id", param)
+			return c.Redirect(307, "/404")
+		}
+
 		c.Set("position", p)
 		return next(c)
 	}
@@ -46,7 +60,7 @@ func (h *handler) getPosition(c echo.Context) error {
 	}
 
 	isOwner := c.Get("isOwner").(bool)
-	return ui.Position(isOwner, i.AuthorSlug, i.Slug, wp).Render(c)
+	return h.positionComponent(ctx, isOwner, i, wp).Render(c)
 }
 
 func (h *handler) editPositionForm(c echo.Context) error {
@@ -72,8 +86,19 @@ func (h *handler) editPosition(c echo.Context) error {
 		return c.Redirect(307, "/500")
 	}
 
-	if err := wp.ApplyChange(ctx, opt, h.pos); err != nil {
-		if errors.Is(err, position.ErrTargetPrice) || errors.Is(err, position.ErrParseDeadline) {
+	fills := len(p.Fills)
+	err = wp.ApplyChange(ctx, opt, h.pos)
+	if len(wp.Fills) != fills {
+		// the average entry and the realized part have changed
+		if rwp, err := p.WithProfit(ctx, h.mp); err == nil {
+			wp = rwp
+		} else {
+			h.log.Warn("couldn't get price for scaled position", "id", p.ID, "err", err)
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, position.ErrTargetPrice) || errors.Is(err, position.ErrParseDeadline) || errors.Is(err, position.ErrFraction) || errors.Is(err, position.ErrScaleSpread) {
 			return ui.PositionEditForm{
 				ID:            wp.ID,
 				Name:          wp.Instrument.Name,
@@ -83,15 +108,76 @@ func (h *handler) editPosition(c echo.Context) error {
 				Type:          string(wp.Type),
 				TargetHint:    wp.TargetPrice.String(),
 				PrevTarget:    opt.TargetPrice,
-				WrongTarget:   errors.Is(err, position.ErrTargetPrice),
+				WrongTarget:   opt.Targets == "" && errors.Is(err, position.ErrTargetPrice),
+				TargetsHint:   ui.TargetsHint(p),
+				PrevTargets:   opt.Targets,
+				WrongTargets:  opt.Targets != "" && (errors.Is(err, position.ErrTargetPrice) || errors.Is(err, position.ErrFraction) || errors.Is(err, position.ErrScaleSpread)),
 				DeadlineHint:  wp.Deadline,
 				PrevDeadline:  opt.Deadline,
 				WrongDeadline: errors.Is(err, position.ErrParseDeadline),
+				CanScale:      !wp.IsSpread(),
+				PrevAdd:       opt.Add,
+				PrevReduce:    opt.Reduce,
+				WrongFraction: opt.Targets == "" && errors.Is(err, position.ErrFraction),
 			}.Render(c)
 		} else {
 			h.log.Error("couldn't apply changes to position", "err", err, "id", wp.ID)
 		}
 	}
 
-	return ui.Position(true, i.AuthorSlug, i.Slug, wp).Render(c)
+	return h.positionComponent(ctx, true, i, wp).Render(c)
+}
+
+func (h *handler) redraftPositionForm(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+	p := c.Get("position").(*position.Position)
+	return ui.RedraftPosition(i.AuthorSlug, i.Slug, p).Render(c)
+}
+
+// redraftPosition rewrites a position of a draft, it leaves no history.
+func (h *handler) redraftPosition(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+	p := c.Get("position").(*position.Position)
+
+	var opt position.CreationOptions
+	if err := c.Bind(&opt); err != nil {
+		h.log.Warn("couldn't bind position creation options", "err", err)
+		return c.Redirect(307, "/400")
+	}
+
+	ctx := c.Request().Context()
+	err := i.EditDraftPosition(ctx, timeext.Now(), h.mp, h.pos, p, opt)
+	if isPositionFormError(err) {
+		pf := wrongPosition(i, opt, err)
+		pf.PositionID = p.ID
+		return pf.Render(c)
+	} else if errors.Is(err, idea.ErrNotDraft) || errors.Is(err, position.ErrNotFound) {
+		return ui.Render400(c)
+	} else if err != nil {
+		h.log.Error("couldn't rewrite draft position", "id", p.ID, "err", err)
+		return ui.Render500(c)
+	}
+
+	wp, err := p.WithProfit(ctx, h.mp)
+	if err != nil {
+		h.log.Error("couldn't get price for position", "id", p.ID, "err", err)
+		return ui.Render500(c)
+	}
+
+	return h.positionComponent(ctx, true, i, wp).Render(c)
+}
+
+func (h *handler) deletePosition(c echo.Context) error {
+	i := c.Get("idea").(*idea.Idea)
+	p := c.Get("position").(*position.Position)
+
+	err := i.DeleteDraftPosition(c.Request().Context(), h.pos, h.ir, p.ID)
+	if errors.Is(err, idea.ErrNotDraft) || errors.Is(err, position.ErrNotFound) {
+		return ui.Render400(c)
+	} else if err != nil {
+		h.log.Error("couldn't delete draft position", "id", p.ID, "err", err)
+		return ui.Render500(c)
+	}
+
+	return c.NoContent(200)
 }
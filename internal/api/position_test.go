@@ -0,0 +1,65 @@
+package api
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/position"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type positions struct {
+	positionRepo
+	pp map[int]*position.Position
+}
+
+func (r positions) Find(ctx context.Context, id int) (*position.Position, error) {
+	p, ok := r.pp[id]
+	if !ok {
+		return nil, position.ErrNotFound
+	}
+	return p, nil
+}
+
+func TestPositionOfAnotherIdea(t *testing.T) {
+	h := &handler{
+		log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		pos: positions{pp: map[int]*position.Position{
+			1: {ID: 1, Status: position.Active},
+			2: {ID: 2, Status: position.Draft},
+			3: {ID: 3, Status: position.Pending},
+		}},
+	}
+	public := &idea.Idea{Slug: "sber", Status: idea.Active, PositionIDs: []int{1}, PendingIDs: []int{3}}
+
+	cases := []struct {
+		id      string
+		isOwner bool
+		want    int
+	}{
+		{"1", false, http.StatusOK},
+		// the draft's position through the public idea's URL
+		{"2", false, http.StatusTemporaryRedirect},
+		{"2", true, http.StatusTemporaryRedirect},
+		{"3", false, http.StatusTemporaryRedirect},
+		{"3", true, http.StatusOK},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		ctx.SetParamNames("positionID")
+		ctx.SetParamValues(c.id)
+		ctx.Set("idea", public)
+		ctx.Set("isOwner", c.isOwner)
+
+		err := h.positionMW(func(c echo.Context) error { return c.NoContent(http.StatusOK) })(ctx)
+		if err != nil || rec.Code != c.want {
+			t.Errorf("position %s, owner %v: got %d (%v), want %d", c.id, c.isOwner, rec.Code, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"changemedaddy/internal/aggregate/analyst"
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/subscription"
+	"changemedaddy/internal/ui"
+	"errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+func subscribeTarget(c echo.Context) (analystSlug, ideaSlug string) {
+	a := c.Get("analyst").(*analyst.Analyst)
+	if i, ok := c.Get("idea").(*idea.Idea); ok {
+		return a.Slug, i.Slug
+	}
+	return a.Slug, ""
+}
+
+func (h *handler) subscribeForm(c echo.Context) error {
+	analystSlug, ideaSlug := subscribeTarget(c)
+	return ui.NewSubscription(analystSlug, ideaSlug).Render(c)
+}
+
+func (h *handler) subscribe(c echo.Context) error {
+	var opt subscription.CreationOptions
+	if err := c.Bind(&opt); err != nil {
+		h.log.Warn("couldn't bind subscription options", "err", err)
+		return c.Redirect(307, "/400")
+	}
+	opt.AnalystSlug, opt.IdeaSlug = subscribeTarget(c)
+
+	sf := ui.SubscriptionForm{
+		AnalystSlug: opt.AnalystSlug,
+		IdeaSlug:    opt.IdeaSlug,
+		PrevChannel: string(opt.Channel),
+		PrevAddress: opt.Address,
+	}
+
+	s, err := subscription.New(c.Request().Context(), h.sr, opt)
+	switch {
+	case err == nil:
+		sf.Subscribed = true
+		sf.NeedsConfirmation = s.Unconfirmed
+		if s.Unconfirmed {
+			if err := h.nd.RequestConfirmation(c.Request().Context(), s); err != nil {
+				h.log.Error("couldn't request subscription confirmation", "err", err)
+				// without the letter nobody could ever confirm it, let them try again
+				if err := h.sr.Delete(c.Request().Context(), s.ID); err != nil {
+					h.log.Error("couldn't delete unconfirmable subscription", "id", s.ID, "err", err)
+				}
+				return ui.Render500(c)
+			}
+		}
+	case errors.Is(err, subscription.ErrConflict):
+		// no second letter, or the form would send as many as anyone wants
+		sf.Subscribed = true
+		sf.NeedsConfirmation = opt.Channel == subscription.Email
+	case errors.Is(err, subscription.ErrChannel) || errors.Is(err, subscription.ErrAddress):
+		sf.WrongChannel = errors.Is(err, subscription.ErrChannel)
+		sf.WrongAddress = errors.Is(err, subscription.ErrAddress)
+	default:
+		h.log.Error("couldn't subscribe", "err", err, "analyst", opt.AnalystSlug, "idea", opt.IdeaSlug)
+		return ui.Render500(c)
+	}
+
+	return sf.Render(c)
+}
+
+// unsubscribeForm only asks, the link is in every message and mail scanners follow it.
+func (h *handler) unsubscribeForm(c echo.Context) error {
+	return ui.ConfirmUnsubscribe(c, c.Param("id"))
+}
+
+func (h *handler) unsubscribe(c echo.Context) error {
+	err := h.sr.Delete(c.Request().Context(), c.Param("id"))
+	if err != nil && !errors.Is(err, subscription.ErrNotFound) {
+		h.log.Error("couldn't unsubscribe", "err", err)
+		return c.Redirect(307, "/500")
+	}
+
+	return ui.Unsubscribed(c)
+}
+
+func (h *handler) confirmSubscriptionForm(c echo.Context) error {
+	return ui.ConfirmSubscription(c, c.Param("id"), false)
+}
+
+func (h *handler) confirmSubscription(c echo.Context) error {
+	err := h.sr.Confirm(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, subscription.ErrNotFound) {
+		return ui.Render404(c)
+	} else if err != nil {
+		h.log.Error("couldn't confirm subscription", "err", err)
+		return ui.Render500(c)
+	}
+
+	return ui.ConfirmSubscription(c, c.Param("id"), true)
+}
@@ -2,18 +2,69 @@ package api
 
 import (
 	"changemedaddy/internal/aggregate/analyst"
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/domain/visit"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/ui"
+	"time"
+
 	"github.com/labstack/echo/v4"
 )
 
+const statsWindow = 30 * 24 * time.Hour
+
 func (h *handler) visitorsMW(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		a, _ := c.Get("analyst").(*analyst.Analyst)
-		h.vr.Add(c.Request().Context(), a, c.RealIP())
+		isOwner, _ := c.Get("isOwner").(bool)
+		isAdmin, _ := c.Get("is_admin").(bool)
+		if isOwner || isAdmin {
+			return next(c)
+		}
+
+		opt := visit.ViewOptions{
+			IP:        c.RealIP(),
+			UserAgent: c.Request().UserAgent(),
+			Referrer:  c.Request().Referer(),
+			Host:      c.Request().Host,
+			At:        timeext.Now(),
+		}
+		if a, ok := c.Get("analyst").(*analyst.Analyst); ok {
+			opt.AnalystSlug = a.Slug
+		}
+		if i, ok := c.Get("idea").(*idea.Idea); ok {
+			opt.IdeaSlug = i.Slug
+		}
+		if p, ok := c.Get("position").(*position.Position); ok {
+			opt.PositionID = p.ID
+		}
+
+		h.vs.Record(opt)
 
 		return next(c)
 	}
 }
 
-func (h *handler) getVisitorsCount(c echo.Context) error {
-	return c.JSON(200, h.vr.GetAll(c.Request().Context()))
+func (h *handler) getAnalystStats(c echo.Context) error {
+	a := c.Get("analyst").(*analyst.Analyst)
+
+	now := timeext.Now()
+	s, err := h.vr.Stats(c.Request().Context(), a.Slug, now.Add(-statsWindow), now)
+	if err != nil {
+		h.log.Error("couldn't get analyst stats", "slug", a.Slug, "err", err)
+		return c.Redirect(307, "/500")
+	}
+
+	return ui.AnalystStats(s).Render(c)
+}
+
+func (h *handler) getAnalytics(c echo.Context) error {
+	now := timeext.Now()
+	ss, err := h.vr.AllStats(c.Request().Context(), now.Add(-statsWindow), now)
+	if err != nil {
+		h.log.Error("couldn't get analytics", "err", err)
+		return c.Redirect(307, "/500")
+	}
+
+	return ui.Analytics(ss).Render(c)
 }
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"changemedaddy/internal/aggregate/analyst"
+	"changemedaddy/internal/domain/webhook"
+	"changemedaddy/internal/ui"
+	"context"
+	"errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+const deliveryLogSize = 20
+
+func (h *handler) webhooksComponent(ctx context.Context, analystSlug string) (ui.WebhooksComponent, error) {
+	ee, err := h.wr.EndpointsByAnalyst(ctx, analystSlug)
+	if err != nil {
+		return ui.WebhooksComponent{}, err
+	}
+
+	dd, err := h.wr.DeliveriesByAnalyst(ctx, analystSlug, deliveryLogSize)
+	if err != nil {
+		return ui.WebhooksComponent{}, err
+	}
+
+	return ui.Webhooks(analystSlug, ee, dd), nil
+}
+
+func (h *handler) getWebhooks(c echo.Context) error {
+	a := c.Get("analyst").(*analyst.Analyst)
+
+	wc, err := h.webhooksComponent(c.Request().Context(), a.Slug)
+	if err != nil {
+		h.log.Error("couldn't get webhooks", "slug", a.Slug, "err", err)
+		return ui.Render500(c)
+	}
+
+	return wc.Render(c)
+}
+
+func (h *handler) addWebhook(c echo.Context) error {
+	a := c.Get("analyst").(*analyst.Analyst)
+
+	var opt webhook.CreationOptions
+	if err := c.Bind(&opt); err != nil {
+		h.log.Warn("couldn't bind webhook options", "err", err)
+		return c.Redirect(307, "/400")
+	}
+	opt.AnalystSlug = a.Slug
+
+	ctx := c.Request().Context()
+	_, err := webhook.NewEndpoint(ctx, h.wr, opt)
+	if err != nil && !errors.Is(err, webhook.ErrURL) && !errors.Is(err, webhook.ErrTooMany) && !errors.Is(err, webhook.ErrConflict) {
+		h.log.Error("couldn't add webhook", "slug", a.Slug, "err", err)
+		return ui.Render500(c)
+	}
+
+	wc, werr := h.webhooksComponent(ctx, a.Slug)
+	if werr != nil {
+		h.log.Error("couldn't get webhooks", "slug", a.Slug, "err", werr)
+		return ui.Render500(c)
+	}
+
+	if err != nil {
+		wc.PrevURL = opt.URL
+		wc.WrongURL = errors.Is(err, webhook.ErrURL)
+		wc.TooMany = errors.Is(err, webhook.ErrTooMany)
+	}
+
+	return wc.Render(c)
+}
+
+func (h *handler) deleteWebhook(c echo.Context) error {
+	a := c.Get("analyst").(*analyst.Analyst)
+
+	ctx := c.Request().Context()
+	err := h.wr.DeleteEndpoint(ctx, a.Slug, c.Param("webhookID"))
+	if err != nil && !errors.Is(err, webhook.ErrNotFound) {
+		h.log.Error("couldn't delete webhook", "slug", a.Slug, "err", err)
+		return ui.Render500(c)
+	}
+
+	return h.getWebhooks(c)
+}
+
+func (h *handler) testWebhook(c echo.Context) error {
+	a := c.Get("analyst").(*analyst.Analyst)
+
+	ctx := c.Request().Context()
+	e, err := h.wr.FindEndpoint(ctx, c.Param("webhookID"))
+	if errors.Is(err, webhook.ErrNotFound) || (err == nil && e.AnalystSlug != a.Slug) {
+		return c.Redirect(307, "/404")
+	} else if err != nil {
+		h.log.Error("couldn't find webhook", "slug", a.Slug, "err", err)
+		return ui.Render500(c)
+	}
+
+	if _, err := h.hs.SendTest(ctx, e); err != nil {
+		h.log.Error("couldn't send test webhook", "slug", a.Slug, "err", err)
+	}
+
+	return h.getWebhooks(c)
+}
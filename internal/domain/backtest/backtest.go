@@ -0,0 +1,327 @@
+// Package backtest simulates following an analyst's positions with a capital.
+package backtest
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type Sizing string
+
+const (
+	// EqualWeight puts the same part of the capital into every position.
+	EqualWeight Sizing = "equal"
+	// FixedPerIdea puts the same amount into every idea, split evenly between its positions.
+	FixedPerIdea Sizing = "fixed"
+)
+
+var (
+	ErrCapital = errors.New("capital should be positive and at most a trillion")
+	ErrSizing  = errors.New("sizing does not exist")
+)
+
+type Options struct {
+	Capital decimal.Decimal
+	Sizing  Sizing
+	// PerIdea is the amount put into every idea with FixedPerIdea.
+	PerIdea decimal.Decimal
+}
+
+// MaxCapital caps the capital and the per-idea amount.
+var MaxCapital = decimal.NewFromInt(1_000_000_000_000)
+
+// DefaultOptions follow with 100k RUB, equal weight.
+var DefaultOptions = Options{
+	Capital: decimal.NewFromInt(100_000),
+	Sizing:  EqualWeight,
+	PerIdea: decimal.NewFromInt(10_000),
+}
+
+// Validate tells if the options can be run, before the positions are priced.
+func (opt Options) Validate() error {
+	var err error
+	if !opt.Capital.IsPositive() || opt.Capital.GreaterThan(MaxCapital) {
+		err = errors.Join(err, ErrCapital)
+	}
+	if opt.Sizing != EqualWeight && opt.Sizing != FixedPerIdea {
+		err = errors.Join(err, ErrSizing)
+	} else if opt.Sizing == FixedPerIdea && (!opt.PerIdea.IsPositive() || opt.PerIdea.GreaterThan(MaxCapital)) {
+		err = errors.Join(err, ErrCapital)
+	}
+	return err
+}
+
+// Idea is the positions of a followed idea.
+type Idea struct {
+	Slug      string
+	Positions []*position.Position
+}
+
+type Point struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+	// DrawdownP is how far below its peak so far the equity is, in percent.
+	DrawdownP float64 `json:"drawdown_p"`
+}
+
+type Result struct {
+	Capital      float64 `json:"capital"`
+	Final        float64 `json:"final"`
+	ReturnP      float64 `json:"return_p"`
+	MaxDrawdownP float64 `json:"max_drawdown_p"`
+	Followed     int     `json:"followed"`
+	// Skipped are the spreads, which can't be bought in one go, and the positions
+	// there was no cash left for.
+	Skipped int     `json:"skipped"`
+	Equity  []Point `json:"equity"`
+}
+
+type marketProvider interface {
+	Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error)
+	Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error)
+	GetCandles(ctx context.Context, i *instrument.WithInterval) ([]chart.Candle, error)
+}
+
+// sleeve is the money put into a position.
+type sleeve struct {
+	p      *position.Position
+	target decimal.Decimal
+	end    time.Time
+	// finalP is the position's return at its close, or now for active ones.
+	finalP  decimal.Decimal
+	dd      []instrument.Dividend
+	candles []chart.Candle
+	// siblings is how many positions of its idea are followed, it included.
+	siblings int
+
+	amount          decimal.Decimal
+	opened, settled bool
+}
+
+func (s *sleeve) value(returnP decimal.Decimal) decimal.Decimal {
+	return s.amount.Mul(returnP.Div(decimal.NewFromInt(100)).Add(decimal.NewFromInt(1)))
+}
+
+// Portfolio is the followed positions priced for the replay. Pricing them is
+// the slow part of a backtest and doesn't depend on the options, so a
+// portfolio can be replayed with many of them.
+type Portfolio struct {
+	// sleeves are in the order the positions were opened, none of them sized.
+	sleeves []sleeve
+	days    []time.Time
+	spreads int
+}
+
+// Prepare prices the positions of the ideas for the replay.
+func Prepare(ctx context.Context, mp marketProvider, ideas []Idea) (*Portfolio, error) {
+	pf := new(Portfolio)
+	for _, i := range ideas {
+		var followed []*position.Position
+		for _, p := range i.Positions {
+			if p.IsSpread() {
+				pf.spreads++
+				continue
+			}
+			followed = append(followed, p)
+		}
+
+		for _, p := range followed {
+			s, err := newSleeve(ctx, mp, p)
+			if err != nil {
+				return nil, err
+			}
+			s.siblings = len(followed)
+			pf.sleeves = append(pf.sleeves, *s)
+		}
+	}
+	sort.SliceStable(pf.sleeves, func(i, j int) bool {
+		a, b := pf.sleeves[i].p, pf.sleeves[j].p
+		if !a.OpenDate.Equal(b.OpenDate) {
+			return a.OpenDate.Before(b.OpenDate)
+		}
+		return a.ID < b.ID
+	})
+	pf.days = timeline(pf.sleeves)
+
+	return pf, nil
+}
+
+// Run replays the positions in the order they were opened, each entry on the
+// day it was made and each exit on the day the position closed, marking the
+// open ones to the daily closes. Positions still open are marked to their
+// current quotes at the end.
+func Run(ctx context.Context, mp marketProvider, ideas []Idea, opt Options) (Result, error) {
+	if err := opt.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	pf, err := Prepare(ctx, mp, ideas)
+	if err != nil {
+		return Result{}, err
+	}
+	return pf.Run(opt)
+}
+
+// Run replays the portfolio with the capital and sizing of the options.
+func (pf *Portfolio) Run(opt Options) (Result, error) {
+	if err := opt.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	var (
+		res = Result{Capital: opt.Capital.InexactFloat64(), Skipped: pf.spreads}
+		ss  = make([]*sleeve, len(pf.sleeves))
+	)
+	for n := range pf.sleeves {
+		s := pf.sleeves[n]
+		switch opt.Sizing {
+		case EqualWeight:
+			s.target = opt.Capital.Div(decimal.NewFromInt(int64(len(pf.sleeves))))
+		case FixedPerIdea:
+			s.target = opt.PerIdea.Div(decimal.NewFromInt(int64(s.siblings)))
+		}
+		ss[n] = &s
+	}
+
+	cash := opt.Capital
+	for _, day := range pf.days {
+		dayEnd := day.Add(chart.Day.Duration())
+
+		var equity decimal.Decimal
+		for _, s := range ss {
+			if !s.opened && s.p.OpenDate.Before(dayEnd) {
+				cash = open(s, cash, &res)
+			}
+			if s.opened && !s.settled && s.p.Status == position.Closed && s.end.Before(dayEnd) {
+				s.settled = true
+				cash = cash.Add(s.value(s.finalP))
+			}
+			if s.opened && !s.settled {
+				equity = equity.Add(s.value(s.returnPAt(day, dayEnd)))
+			}
+		}
+
+		res.Equity = append(res.Equity, Point{Time: day, Equity: cash.Add(equity).InexactFloat64()})
+	}
+
+	// the end is marked to the current quotes, income counted
+	for _, s := range ss {
+		if !s.opened {
+			cash = open(s, cash, &res)
+		}
+	}
+	final := cash
+	for _, s := range ss {
+		if !s.settled {
+			final = final.Add(s.value(s.finalP))
+		}
+	}
+	res.Final = final.InexactFloat64()
+	if n := len(res.Equity); n > 0 {
+		res.Equity[n-1].Equity = res.Final
+	}
+	res.ReturnP = round2((res.Final/res.Capital - 1) * 100)
+	res.MaxDrawdownP = drawdowns(res.Equity)
+
+	return res, nil
+}
+
+func newSleeve(ctx context.Context, mp marketProvider, p *position.Position) (*sleeve, error) {
+	wp, err := p.WithProfit(ctx, mp)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get profit of position (id %d): %w", p.ID, err)
+	}
+
+	end := timeext.Now()
+	if p.Status == position.Closed {
+		end = p.Deadline
+	}
+
+	s := &sleeve{p: p, end: end, finalP: wp.ProfitP}
+	if p.Instrument.PaysDividends() {
+		if s.dd, err = mp.Dividends(ctx, p.Instrument, p.OpenDate, end); err != nil {
+			return nil, fmt.Errorf("couldn't get dividends of %s: %w", p.Instrument.Ticker, err)
+		}
+	}
+	if s.candles, err = p.Candles(ctx, mp, p.OpenDate, end, chart.Day); err != nil {
+		return nil, fmt.Errorf("couldn't get candles of position (id %d): %w", p.ID, err)
+	}
+	return s, nil
+}
+
+// open puts the sleeve's part into its position, or what's left of the cash.
+func open(s *sleeve, cash decimal.Decimal, res *Result) decimal.Decimal {
+	s.opened = true
+	s.amount = decimal.Min(s.target, cash)
+	if !s.amount.IsPositive() {
+		s.settled = true
+		s.amount = decimal.Zero
+		res.Skipped++
+		return cash
+	}
+	res.Followed++
+	return cash.Sub(s.amount)
+}
+
+// returnPAt is the position's return at the day's close, or at its open price
+// before there's a close to mark it to.
+func (s *sleeve) returnPAt(day, dayEnd time.Time) decimal.Decimal {
+	at := timeext.Min(dayEnd, s.end)
+	price, ok := chart.CloseAt(s.candles, day)
+	if !ok {
+		return decimal.Zero
+	}
+	return s.p.ProfitPAt(at, decimal.NewFromFloat(price), s.dd)
+}
+
+// timeline is the trading days from the first entry to the last quote.
+func timeline(ss []sleeve) []time.Time {
+	if len(ss) == 0 {
+		return nil
+	}
+
+	var (
+		first = ss[0].p.OpenDate
+		seen  = make(map[int64]bool)
+		days  []time.Time
+	)
+	for _, s := range ss {
+		for _, c := range s.candles {
+			day := time.Unix(c.Time, 0).In(timeext.Moscow)
+			if seen[c.Time] || day.Add(chart.Day.Duration()).Before(first) || day.After(s.end) {
+				continue
+			}
+			seen[c.Time] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// drawdowns fills in the drawdowns of the points and returns the deepest one.
+func drawdowns(pp []Point) float64 {
+	var peak, deepest float64
+	for n := range pp {
+		peak = math.Max(peak, pp[n].Equity)
+		if peak > 0 {
+			pp[n].DrawdownP = round2((pp[n].Equity/peak - 1) * 100)
+		}
+		deepest = math.Min(deepest, pp[n].DrawdownP)
+	}
+	return deepest
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
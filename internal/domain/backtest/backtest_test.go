@@ -0,0 +1,125 @@
+package backtest
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+// market is what the tests need of the fake market.
+type market interface {
+	marketProvider
+	Find(ctx context.Context, ticker string) (*instrument.Instrument, error)
+}
+
+func find(t *testing.T, m market, ticker string) *instrument.Instrument {
+	t.Helper()
+
+	i, err := m.Find(context.Background(), ticker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+func TestRun(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		m, _ = fake.NewTest(t, time.Date(2024, 5, 15, 12, 0, 0, 0, timeext.Moscow))
+		// +10% on MGNT, then -7.69% shorting GAZP over its dividend
+		ideas = []Idea{
+			{Slug: "retail", Positions: []*position.Position{{
+				ID:          1,
+				Instrument:  find(t, m, "MGNT"),
+				Type:        position.Long,
+				Status:      position.Closed,
+				OpenPrice:   decimal.NewFromInt(8000),
+				OpenDate:    time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow),
+				ClosedPrice: decimal.NewFromInt(8800),
+				Deadline:    time.Date(2024, 5, 11, 12, 0, 0, 0, timeext.Moscow),
+			}}},
+			{Slug: "gas", Positions: []*position.Position{{
+				ID:          2,
+				Instrument:  find(t, m, "GAZP"),
+				Type:        position.Short,
+				Status:      position.Closed,
+				OpenPrice:   decimal.NewFromInt(130),
+				OpenDate:    time.Date(2024, 5, 13, 10, 0, 0, 0, timeext.Moscow),
+				ClosedPrice: decimal.NewFromFloat(133.5),
+				Deadline:    time.Date(2024, 5, 14, 12, 0, 0, 0, timeext.Moscow),
+			}}},
+		}
+		// the same positions in one idea
+		joint = []Idea{{Slug: "both", Positions: []*position.Position{ideas[0].Positions[0], ideas[1].Positions[0]}}}
+		fixed = Options{Capital: decimal.NewFromInt(100_000), Sizing: FixedPerIdea, PerIdea: decimal.NewFromInt(10_000)}
+		cases = []struct {
+			ideas              []Idea
+			opt                Options
+			final              float64
+			returnP, drawdownP float64
+		}{
+			// 50k in each: +5000, then -3846.15 off the peak of 105k
+			{ideas, DefaultOptions, 101_153.85, 1.15, -3.66},
+			// 10k in each: +1000, then -769.23 off the peak of 101k
+			{ideas, fixed, 100_230.77, 0.23, -0.76},
+			// 5k in each: +500, then -384.62 off the peak of 100.5k
+			{joint, fixed, 100_115.38, 0.12, -0.38},
+		}
+	)
+
+	for _, c := range cases {
+		name := fmt.Sprintf("%s, %d ideas", c.opt.Sizing, len(c.ideas))
+		res, err := Run(ctx, m, c.ideas, c.opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if round2(res.Final) != c.final || res.ReturnP != c.returnP || res.Followed != 2 {
+			t.Errorf("%s: got %.2f, %.2f%% on %d positions, want %.2f, %.2f%% on 2", name, res.Final, res.ReturnP, res.Followed, c.final, c.returnP)
+		}
+
+		deepest := 0.0
+		for _, p := range res.Equity {
+			deepest = min(deepest, p.DrawdownP)
+		}
+		if res.MaxDrawdownP != c.drawdownP || deepest != c.drawdownP {
+			t.Errorf("%s: got drawdown %.2f%% and %.2f%% on the curve, want %.2f%%", name, res.MaxDrawdownP, deepest, c.drawdownP)
+		}
+		if len(res.Equity) == 0 || res.Equity[len(res.Equity)-1].Equity != res.Final {
+			t.Errorf("%s: want the curve to end at the final equity %.2f, got %v", name, res.Final, res.Equity)
+		}
+
+		pf, err := Prepare(ctx, m, c.ideas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a replay with other options leaves the portfolio as it was
+		if _, err := pf.Run(Options{Capital: decimal.NewFromInt(1000), Sizing: EqualWeight}); err != nil {
+			t.Fatal(err)
+		}
+		if again, _ := pf.Run(c.opt); !reflect.DeepEqual(res, again) {
+			t.Errorf("%s: the same backtest gave different results replayed", name)
+		}
+	}
+}
+
+func TestOptionsCapped(t *testing.T) {
+	over := MaxCapital.Add(decimal.NewFromInt(1))
+	for _, opt := range []Options{
+		{Capital: decimal.Zero, Sizing: EqualWeight},
+		{Capital: over, Sizing: EqualWeight},
+		{Capital: decimal.NewFromInt(100_000), Sizing: FixedPerIdea, PerIdea: over},
+	} {
+		if err := opt.Validate(); !errors.Is(err, ErrCapital) {
+			t.Errorf("got %v for capital %s and %s per idea, want ErrCapital", err, opt.Capital, opt.PerIdea)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package chart
 
+import (
+	"sort"
+	"time"
+)
+
 const DateFormat = "2006-01-02 15:04:05"
 
 type Candle struct {
@@ -9,3 +14,25 @@ type Candle struct {
 	High  float64 `json:"high"`
 	Low   float64 `json:"low"`
 }
+
+// Coverage is the time range of a candle series that is stored locally.
+// SyncedAt is when the series was last fetched from the provider.
+type Coverage struct {
+	From     time.Time `bson:"from"`
+	To       time.Time `bson:"to"`
+	SyncedAt time.Time `bson:"synced_at"`
+}
+
+func (c Coverage) IsZero() bool {
+	return c.From.IsZero() && c.To.IsZero()
+}
+
+// CloseAt is the close of the last candle that started at or before t, the
+// candles being in time order.
+func CloseAt(cc []Candle, t time.Time) (float64, bool) {
+	n := sort.Search(len(cc), func(n int) bool { return cc[n].Time > t.Unix() })
+	if n == 0 {
+		return 0, false
+	}
+	return cc[n-1].Close, true
+}
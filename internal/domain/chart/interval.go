@@ -0,0 +1,105 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInterval = errors.New("unknown candle interval")
+
+// Interval is a candle width. Providers translate it to their own API values.
+type Interval string
+
+const (
+	Minute         Interval = "1m"
+	FiveMinutes    Interval = "5m"
+	FifteenMinutes Interval = "15m"
+	Hour           Interval = "1h"
+	FourHours      Interval = "4h"
+	Day            Interval = "1d"
+	Week           Interval = "1w"
+	Month          Interval = "1M"
+)
+
+// Intervals lists all intervals from the finest to the coarsest.
+var Intervals = []Interval{Minute, FiveMinutes, FifteenMinutes, Hour, FourHours, Day, Week, Month}
+
+var durations = map[Interval]time.Duration{
+	Minute:         time.Minute,
+	FiveMinutes:    5 * time.Minute,
+	FifteenMinutes: 15 * time.Minute,
+	Hour:           time.Hour,
+	FourHours:      4 * time.Hour,
+	Day:            24 * time.Hour,
+	Week:           7 * 24 * time.Hour,
+	Month:          30 * 24 * time.Hour,
+}
+
+var aliases = map[string]Interval{
+	"1min":    Minute,
+	"min":     Minute,
+	"minute":  Minute,
+	"5min":    FiveMinutes,
+	"15min":   FifteenMinutes,
+	"60m":     Hour,
+	"h":       Hour,
+	"hour":    Hour,
+	"240m":    FourHours,
+	"24h":     Day,
+	"d":       Day,
+	"day":     Day,
+	"daily":   Day,
+	"7d":      Week,
+	"w":       Week,
+	"week":    Week,
+	"weekly":  Week,
+	"1mo":     Month,
+	"mo":      Month,
+	"month":   Month,
+	"monthly": Month,
+}
+
+// ParseInterval accepts the canonical names ("15m", "1M") and common spellings
+// like "hour" or "week". Note that "1m" is a minute and "1M" is a month.
+func ParseInterval(s string) (Interval, error) {
+	s = strings.TrimSpace(s)
+	if i := Interval(s); i.Valid() {
+		return i, nil
+	}
+	if i, ok := aliases[strings.ToLower(s)]; ok {
+		return i, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrInterval, s)
+}
+
+func (i Interval) Valid() bool {
+	_, ok := durations[i]
+	return ok
+}
+
+// Duration is the nominal candle width, a month counts as 30 days.
+func (i Interval) Duration() time.Duration {
+	return durations[i]
+}
+
+func (i Interval) String() string {
+	return string(i)
+}
+
+// maxCandles is roughly how many candles fit on a chart and stay readable.
+const maxCandles = 300
+
+// AutoInterval picks the finest interval that shows the span in at most maxCandles candles.
+func AutoInterval(from, to time.Time) Interval {
+	span := to.Sub(from)
+	for _, i := range Intervals {
+		if span <= maxCandles*i.Duration() {
+			return i
+		}
+	}
+
+	return Month
+}
@@ -0,0 +1,55 @@
+package chart
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	cases := map[string]Interval{
+		"1m":    Minute,
+		"1M":    Month,
+		"15m":   FifteenMinutes,
+		" 4h ":  FourHours,
+		"HOUR":  Hour,
+		"week":  Week,
+		"1mo":   Month,
+		"daily": Day,
+	}
+	for in, want := range cases {
+		got, err := ParseInterval(in)
+		if err != nil || got != want {
+			t.Errorf("ParseInterval(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "2m", "5", "yearly"} {
+		if _, err := ParseInterval(in); !errors.Is(err, ErrInterval) {
+			t.Errorf("ParseInterval(%q) err = %v; want ErrInterval", in, err)
+		}
+	}
+}
+
+func TestAutoInterval(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		span time.Duration
+		want Interval
+	}{
+		{0, Minute},
+		{3 * time.Hour, Minute},
+		{24 * time.Hour, FiveMinutes},
+		{3 * 24 * time.Hour, FifteenMinutes},
+		{10 * 24 * time.Hour, Hour},
+		{40 * 24 * time.Hour, FourHours},
+		{200 * 24 * time.Hour, Day},
+		{3 * 365 * 24 * time.Hour, Week},
+		{30 * 365 * 24 * time.Hour, Month},
+	}
+	for _, c := range cases {
+		if got := AutoInterval(start, start.Add(c.span)); got != c.want {
+			t.Errorf("AutoInterval(%s) = %q; want %q", c.span, got, c.want)
+		}
+	}
+}
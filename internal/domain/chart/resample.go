@@ -0,0 +1,105 @@
+package chart
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Start returns the beginning of the interval that t falls in. Intraday intervals
+// are aligned to midnight, weeks start on Monday and months on the 1st, all in loc.
+func (i Interval) Start(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+	switch i {
+	case Day:
+		return midnight
+	case Week:
+		return midnight.AddDate(0, 0, -(int(midnight.Weekday())+6)%7)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		d := i.Duration()
+		return midnight.Add(t.Sub(midnight) / d * d)
+	}
+}
+
+// Next returns the start of the interval following the one that t falls in.
+func (i Interval) Next(t time.Time, loc *time.Location) time.Time {
+	start := i.Start(t, loc)
+	switch i {
+	case Day:
+		return start.AddDate(0, 0, 1)
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.Add(i.Duration())
+	}
+}
+
+// Resample folds candles into coarser ones: the open of the first candle of a bucket,
+// the close of the last, and the extremes of all. Buckets without candles (nights,
+// weekends, halts) are skipped rather than filled, so the result has the same gaps
+// as the source. The source must not be coarser than the target interval.
+func Resample(cc []Candle, to Interval, loc *time.Location) ([]Candle, error) {
+	if !to.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrInterval, to)
+	}
+
+	cc = sorted(cc)
+
+	var (
+		res    []Candle
+		bucket int64
+	)
+	for _, c := range cc {
+		start := to.Start(time.Unix(c.Time, 0), loc).Unix()
+		if len(res) == 0 || start != bucket {
+			bucket = start
+			c.Time = start
+			res = append(res, c)
+			continue
+		}
+
+		last := &res[len(res)-1]
+		last.Close = c.Close
+		last.High = max(last.High, c.High)
+		last.Low = min(last.Low, c.Low)
+	}
+
+	return res, nil
+}
+
+// Merge combines overlapping fetches of the same series. Candles with the same time
+// are taken from the newer fetch, since the older one may hold an unfinished candle.
+func Merge(older, newer []Candle) []Candle {
+	byTime := make(map[int64]Candle, len(older)+len(newer))
+	for _, c := range older {
+		byTime[c.Time] = c
+	}
+	for _, c := range newer {
+		byTime[c.Time] = c
+	}
+
+	res := make([]Candle, 0, len(byTime))
+	for _, c := range byTime {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Time < res[j].Time })
+
+	return res
+}
+
+func sorted(cc []Candle) []Candle {
+	if sort.SliceIsSorted(cc, func(i, j int) bool { return cc[i].Time < cc[j].Time }) {
+		return cc
+	}
+
+	cp := make([]Candle, len(cc))
+	copy(cp, cc)
+	sort.SliceStable(cp, func(i, j int) bool { return cp[i].Time < cp[j].Time })
+	return cp
+}
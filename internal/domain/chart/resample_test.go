@@ -0,0 +1,74 @@
+package chart
+
+import (
+	"changemedaddy/internal/pkg/timeext"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func at(s string) int64 {
+	t, err := time.ParseInLocation(DateFormat, s, timeext.Moscow)
+	if err != nil {
+		panic(err)
+	}
+	return t.Unix()
+}
+
+func TestIntervalStart(t *testing.T) {
+	ts := time.Date(2024, 5, 15, 13, 47, 12, 0, timeext.Moscow) // a Wednesday
+	cases := map[Interval]string{
+		Minute:         "2024-05-15 13:47:00",
+		FifteenMinutes: "2024-05-15 13:45:00",
+		FourHours:      "2024-05-15 12:00:00",
+		Day:            "2024-05-15 00:00:00",
+		Week:           "2024-05-13 00:00:00",
+		Month:          "2024-05-01 00:00:00",
+	}
+	for i, want := range cases {
+		if got := i.Start(ts, timeext.Moscow).Unix(); got != at(want) {
+			t.Errorf("%s.Start = %s; want %s", i, time.Unix(got, 0).In(timeext.Moscow).Format(DateFormat), want)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	src := []Candle{
+		{Time: at("2024-05-15 10:05:00"), Open: 11, Close: 12, High: 13, Low: 10},
+		{Time: at("2024-05-15 10:00:00"), Open: 10, Close: 11, High: 12, Low: 9},
+		{Time: at("2024-05-15 10:10:00"), Open: 12, Close: 9, High: 15, Low: 8},
+		// 10:15-10:30 has no trades
+		{Time: at("2024-05-15 10:30:00"), Open: 9, Close: 10, High: 10, Low: 9},
+	}
+
+	got, err := Resample(src, FifteenMinutes, timeext.Moscow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Candle{
+		{Time: at("2024-05-15 10:00:00"), Open: 10, Close: 9, High: 15, Low: 8},
+		{Time: at("2024-05-15 10:30:00"), Open: 9, Close: 10, High: 10, Low: 9},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resample = %+v; want %+v", got, want)
+	}
+	if src[0].Time != at("2024-05-15 10:05:00") {
+		t.Error("Resample modified its input")
+	}
+
+	if _, err := Resample(src, "2m", timeext.Moscow); err == nil {
+		t.Error("Resample accepted an unknown interval")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	older := []Candle{{Time: 1, Close: 1}, {Time: 2, Close: 2}, {Time: 3, Close: 3}}
+	newer := []Candle{{Time: 3, Close: 30}, {Time: 4, Close: 4}}
+
+	got := Merge(older, newer)
+	want := []Candle{{Time: 1, Close: 1}, {Time: 2, Close: 2}, {Time: 3, Close: 30}, {Time: 4, Close: 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge = %+v; want %+v", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package chart
+
+import "sort"
+
+// Spread builds the candles of a combination of instruments from each one's candles.
+// value combines prices in the order of series, false skips the candle. A series
+// missing a candle carries its last close over, and the spread isn't charted
+// until every series has started. Highs and lows of the legs don't happen at the
+// same time, so the spread's are those of its open and close.
+func Spread(series [][]Candle, value func(prices []float64) (float64, bool)) []Candle {
+	seen := make(map[int64]bool)
+	var times []int64
+	for _, cc := range series {
+		for _, c := range cc {
+			if !seen[c.Time] {
+				seen[c.Time] = true
+				times = append(times, c.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	var (
+		spread = make([]Candle, 0, len(times))
+		opens  = make([]float64, len(series))
+		closes = make([]float64, len(series))
+	)
+next:
+	for _, t := range times {
+		for n, cc := range series {
+			k := sort.Search(len(cc), func(k int) bool { return cc[k].Time > t })
+			if k == 0 {
+				continue next
+			}
+			if c := cc[k-1]; c.Time == t {
+				opens[n], closes[n] = c.Open, c.Close
+			} else {
+				opens[n], closes[n] = c.Close, c.Close
+			}
+		}
+
+		o, ok := value(opens)
+		if !ok {
+			continue
+		}
+		c, ok := value(closes)
+		if !ok {
+			continue
+		}
+		spread = append(spread, Candle{Time: t, Open: o, Close: c, High: max(o, c), Low: min(o, c)})
+	}
+
+	return spread
+}
@@ -0,0 +1,29 @@
+package chart
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpread(t *testing.T) {
+	var (
+		a = []Candle{
+			{Time: at("2024-05-15 10:00:00"), Open: 100, Close: 110},
+			{Time: at("2024-05-15 11:00:00"), Open: 110, Close: 120},
+			{Time: at("2024-05-15 12:00:00"), Open: 120, Close: 100},
+		}
+		// starts later and has no candle at 12:00
+		b = []Candle{
+			{Time: at("2024-05-15 11:00:00"), Open: 50, Close: 40},
+		}
+		ratio = func(pp []float64) (float64, bool) { return pp[0] / pp[1], pp[1] != 0 }
+	)
+
+	want := []Candle{
+		{Time: at("2024-05-15 11:00:00"), Open: 2.2, Close: 3, High: 3, Low: 2.2},
+		{Time: at("2024-05-15 12:00:00"), Open: 3, Close: 2.5, High: 3, Low: 2.5},
+	}
+	if got := Spread([][]Candle{a, b}, ratio); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
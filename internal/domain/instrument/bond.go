@@ -0,0 +1,151 @@
+package instrument
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+var ErrYield = errors.New("couldn't solve for yield to maturity")
+
+// Coupon is a coupon payment per bond, in the bond's currency.
+type Coupon struct {
+	Date   time.Time       `bson:"date" json:"date"`
+	Amount decimal.Decimal `bson:"amount" json:"amount"`
+}
+
+var hundred = decimal.NewFromInt(100)
+
+const daysInYear = 365
+
+func (i *Instrument) IsBond() bool {
+	return i.Type == Bond
+}
+
+// AccruedInterest is the part of the current coupon period's coupon earned by the
+// holder at the moment, which bond buyers pay on top of the price.
+func (i *Instrument) AccruedInterest(at time.Time) decimal.Decimal {
+	var (
+		prev time.Time
+		next *Coupon
+	)
+	for n := range i.Coupons {
+		c := &i.Coupons[n]
+		if !c.Date.After(at) {
+			prev = c.Date
+			continue
+		}
+		next = c
+		if prev.IsZero() && n+1 < len(i.Coupons) {
+			// the first period is assumed to be as long as the following one
+			prev = c.Date.Add(-i.Coupons[n+1].Date.Sub(c.Date))
+		}
+		break
+	}
+	if next == nil || prev.IsZero() || !at.After(prev) {
+		return decimal.Zero
+	}
+
+	elapsed := decimal.NewFromInt(int64(at.Sub(prev)))
+	period := decimal.NewFromInt(int64(next.Date.Sub(prev)))
+	return next.Amount.Mul(elapsed).Div(period)
+}
+
+// Value is how much one unit costs at the price. Bonds are quoted in percent of
+// the nominal and are bought together with the accrued interest, derivatives are
+// quoted in points.
+func (i *Instrument) Value(price decimal.Decimal, at time.Time) decimal.Decimal {
+	if i.IsDerivative() {
+		return price.Mul(i.pointValue())
+	}
+	if !i.IsBond() || !i.Nominal.IsPositive() {
+		return price
+	}
+	return price.Mul(i.Nominal).Div(hundred).Add(i.AccruedInterest(at))
+}
+
+// CouponIncome is the sum of the coupons paid after from and up to to.
+func (i *Instrument) CouponIncome(from, to time.Time) decimal.Decimal {
+	sum := decimal.Zero
+	for _, c := range i.Coupons {
+		if c.Date.After(from) && !c.Date.After(to) {
+			sum = sum.Add(c.Amount)
+		}
+	}
+	return sum
+}
+
+type cashflow struct {
+	years  float64
+	amount float64
+}
+
+// cashflows are the coupons left after at and the nominal repaid at maturity.
+func (i *Instrument) cashflows(at time.Time) []cashflow {
+	var cf []cashflow
+	for _, c := range i.Coupons {
+		if c.Date.After(at) && !c.Date.After(i.Maturity) {
+			cf = append(cf, cashflow{years(at, c.Date), c.Amount.InexactFloat64()})
+		}
+	}
+	return append(cf, cashflow{years(at, i.Maturity), i.Nominal.InexactFloat64()})
+}
+
+func years(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24 / daysInYear
+}
+
+func presentValue(cf []cashflow, ytm float64) float64 {
+	var pv float64
+	for _, c := range cf {
+		pv += c.amount / math.Pow(1+ytm, c.years)
+	}
+	return pv
+}
+
+func (i *Instrument) canYield(at time.Time) bool {
+	return i.IsBond() && i.Nominal.IsPositive() && i.Maturity.After(at)
+}
+
+// YieldToMaturity is the effective annual yield in percent of buying the bond at
+// the price (percent of nominal) at the moment and holding it until maturity.
+func (i *Instrument) YieldToMaturity(price decimal.Decimal, at time.Time) (decimal.Decimal, error) {
+	if !i.canYield(at) || !price.IsPositive() {
+		return decimal.Zero, ErrYield
+	}
+
+	var (
+		cf    = i.cashflows(at)
+		dirty = i.Value(price, at).InexactFloat64()
+	)
+
+	// present value falls as yield grows, so bisect
+	lo, hi := -0.99, 10.0
+	if presentValue(cf, lo) < dirty || presentValue(cf, hi) > dirty {
+		return decimal.Zero, ErrYield
+	}
+	for range 100 {
+		mid := (lo + hi) / 2
+		if presentValue(cf, mid) > dirty {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return decimal.NewFromFloat((lo + hi) / 2 * 100).Round(2), nil
+}
+
+// PriceForYield is the price in percent of nominal at which the bond yields ytm
+// percent at the moment.
+func (i *Instrument) PriceForYield(ytm decimal.Decimal, at time.Time) (decimal.Decimal, error) {
+	if !i.canYield(at) || ytm.LessThanOrEqual(decimal.NewFromInt(-99)) {
+		return decimal.Zero, ErrYield
+	}
+
+	dirty := presentValue(i.cashflows(at), ytm.InexactFloat64()/100)
+	clean := decimal.NewFromFloat(dirty).Sub(i.AccruedInterest(at))
+	return i.RoundToTick(clean.Mul(hundred).Div(i.Nominal)), nil
+}
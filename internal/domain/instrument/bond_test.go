@@ -0,0 +1,79 @@
+package instrument
+
+import (
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+func TestYieldToMaturity(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	zero := Instrument{Type: Bond, Nominal: decimal.NewFromInt(1000), Maturity: at.AddDate(0, 0, 365)}
+	// 100 a year paid at the end of every year
+	coupon := Instrument{Type: Bond, Nominal: decimal.NewFromInt(1000), Maturity: at.AddDate(0, 0, 3*365)}
+	for n := -1; n <= 3; n++ {
+		coupon.Coupons = append(coupon.Coupons, Coupon{Date: at.AddDate(0, 0, n*365), Amount: decimal.NewFromInt(100)})
+	}
+
+	cases := []struct {
+		name  string
+		i     Instrument
+		at    time.Time
+		price string
+		want  string
+	}{
+		{"zero coupon", zero, at, "90", "11.11"},
+		{"at par", coupon, at, "100", "10"},
+		// half a year into the period the buyer pays 50 of accrued interest on top,
+		// which isn't fully made up for by getting the coupon sooner
+		{"mid period at par", coupon, at.AddDate(0, 0, 365/2), "100", "9.94"},
+		{"below par", coupon, at, "95", "12.08"},
+	}
+	for _, c := range cases {
+		got, err := c.i.YieldToMaturity(decimal.RequireFromString(c.price), c.at)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if !got.Equal(decimal.RequireFromString(c.want)) {
+			t.Errorf("%s: YieldToMaturity(%s) = %s; want %s", c.name, c.price, got, c.want)
+		}
+
+		price, err := c.i.PriceForYield(got, c.at)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		// the yield is rounded to basis points
+		if diff := price.Sub(decimal.RequireFromString(c.price)).Abs(); diff.GreaterThan(decimal.RequireFromString("0.05")) {
+			t.Errorf("%s: PriceForYield(%s) = %s; want %s", c.name, got, price, c.price)
+		}
+	}
+
+	if _, err := (&Instrument{Type: Share}).YieldToMaturity(decimal.NewFromInt(100), at); err == nil {
+		t.Error("got a yield for a share")
+	}
+}
+
+func TestBondValue(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := Instrument{
+		Type:     Bond,
+		Nominal:  decimal.NewFromInt(1000),
+		Maturity: at.AddDate(1, 0, 0),
+		Coupons: []Coupon{
+			{Date: at.AddDate(0, 6, 0), Amount: decimal.NewFromInt(40)},
+			{Date: at.AddDate(1, 0, 0), Amount: decimal.NewFromInt(40)},
+		},
+	}
+
+	// the first period is taken as long as the second: 1.07-1.01 is a third of 1.01-1.07
+	if got := b.Value(decimal.NewFromInt(98), at.AddDate(0, 2, 0)).Round(0); !got.Equal(decimal.NewFromInt(993)) {
+		t.Errorf("Value = %s; want 993", got)
+	}
+	if got := b.CouponIncome(at, at.AddDate(1, 0, 0)); !got.Equal(decimal.NewFromInt(80)) {
+		t.Errorf("CouponIncome = %s; want 80", got)
+	}
+	if got := (&Instrument{Type: Share}).Value(decimal.NewFromInt(98), at); !got.Equal(decimal.NewFromInt(98)) {
+		t.Errorf("share Value = %s; want 98", got)
+	}
+}
@@ -0,0 +1,55 @@
+package instrument
+
+import (
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type OptionKind string
+
+const (
+	Call OptionKind = "call"
+	Put  OptionKind = "put"
+)
+
+func (i *Instrument) IsDerivative() bool {
+	return i.Type == Future || i.Type == Option
+}
+
+func (i *Instrument) IsOption() bool {
+	return i.Type == Option
+}
+
+// Expired tells whether the contract can no longer be traded at the moment.
+func (i *Instrument) Expired(at time.Time) bool {
+	return i.IsDerivative() && !i.Expiry.IsZero() && !at.Before(i.Expiry)
+}
+
+func (i *Instrument) pointValue() decimal.Decimal {
+	if !i.PointValue.IsPositive() {
+		return decimal.NewFromInt(1)
+	}
+	return i.PointValue
+}
+
+// Capital is what holding a unit ties up: the margin of a futures contract, so
+// that its returns show the leverage, and the value of anything else.
+func (i *Instrument) Capital(price decimal.Decimal, at time.Time) decimal.Decimal {
+	if i.Type == Future && i.Margin.IsPositive() {
+		return i.Margin
+	}
+	return i.Value(price, at)
+}
+
+// Payoff is what the option is worth at expiry with the underlying at the price.
+func (i *Instrument) Payoff(underlying decimal.Decimal) decimal.Decimal {
+	var payoff decimal.Decimal
+	switch i.OptionKind {
+	case Call:
+		payoff = underlying.Sub(i.Strike)
+	case Put:
+		payoff = i.Strike.Sub(underlying)
+	}
+	return decimal.Max(payoff, decimal.Zero)
+}
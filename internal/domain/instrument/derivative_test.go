@@ -0,0 +1,35 @@
+package instrument
+
+import (
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+func TestOptionSettlement(t *testing.T) {
+	expiry := time.Date(2024, 5, 14, 18, 50, 0, 0, time.UTC)
+	call := Instrument{Type: Option, OptionKind: Call, Strike: decimal.NewFromInt(130), Expiry: expiry}
+	put := Instrument{Type: Option, OptionKind: Put, Strike: decimal.NewFromInt(130), Expiry: expiry}
+
+	if !call.Expired(expiry) || call.Expired(expiry.Add(-time.Minute)) {
+		t.Error("want the option to expire at the session close")
+	}
+
+	cases := []struct {
+		name       string
+		i          Instrument
+		underlying string
+		want       string
+	}{
+		{"call in the money", call, "133.5", "3.5"},
+		{"call out of the money", call, "120", "0"},
+		{"put in the money", put, "120", "10"},
+		{"put out of the money", put, "133.5", "0"},
+	}
+	for _, c := range cases {
+		if got := c.i.Payoff(decimal.RequireFromString(c.underlying)); !got.Equal(decimal.RequireFromString(c.want)) {
+			t.Errorf("%s: Payoff(%s) = %s; want %s", c.name, c.underlying, got, c.want)
+		}
+	}
+}
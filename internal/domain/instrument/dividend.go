@@ -0,0 +1,31 @@
+package instrument
+
+import (
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+// Dividend is a dividend per share, net of tax, in the instrument's currency.
+// Whoever holds the share when the day before ExDate closes receives it.
+type Dividend struct {
+	ExDate time.Time       `bson:"ex_date" json:"ex_date"`
+	Amount decimal.Decimal `bson:"amount" json:"amount"`
+}
+
+// PaysDividends tells whether it's worth asking for the instrument's dividends.
+// Instruments saved before types existed are all shares.
+func (i *Instrument) PaysDividends() bool {
+	return i.Type == Share || i.Type == ""
+}
+
+// DividendIncome is the sum of the dividends a holder from open until to receives.
+func DividendIncome(dd []Dividend, open, to time.Time) decimal.Decimal {
+	sum := decimal.Zero
+	for _, d := range dd {
+		if open.Before(d.ExDate) && !to.Before(d.ExDate) {
+			sum = sum.Add(d.Amount)
+		}
+	}
+	return sum
+}
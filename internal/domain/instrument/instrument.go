@@ -1,17 +1,131 @@
 package instrument
 
 import (
+	"changemedaddy/internal/domain/chart"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/greatcloak/decimal"
 )
 
-type Instrument struct {
-	Name   string
-	Ticker string
-	Uid    string
+type (
+	Type string
+
+	TradingStatus string
+
+	Instrument struct {
+		Name   string `json:"name"`
+		Ticker string `json:"ticker"`
+		Uid    string `json:"uid"`
+
+		Type     Type   `bson:"type" json:"type"`
+		Currency string `bson:"currency" json:"currency"`
+		Lot      int    `bson:"lot" json:"lot"`
+		// Tick is the minimal price increment.
+		Tick     decimal.Decimal `bson:"tick" json:"tick"`
+		Exchange string          `bson:"exchange" json:"exchange"`
+		Board    string          `bson:"board" json:"board"`
+		Status   TradingStatus   `bson:"status" json:"status"`
+
+		// Bonds only: prices are in percent of the nominal.
+		Nominal  decimal.Decimal `bson:"nominal" json:"nominal"`
+		Maturity time.Time       `bson:"maturity,omitempty" json:"maturity,omitempty"`
+		Coupons  []Coupon        `bson:"coupons,omitempty" json:"coupons,omitempty"`
+
+		// Futures and options only.
+		Expiry     time.Time `bson:"expiry,omitempty" json:"expiry,omitempty"`
+		Underlying string    `bson:"underlying,omitempty" json:"underlying,omitempty"`
+		// PointValue is the money a contract makes on a price move of one.
+		PointValue decimal.Decimal `bson:"point_value" json:"point_value"`
+		// Margin is the initial margin of a futures contract, zero if unknown.
+		Margin decimal.Decimal `bson:"margin" json:"margin"`
+
+		// Options only.
+		OptionKind OptionKind      `bson:"option_kind,omitempty" json:"option_kind,omitempty"`
+		Strike     decimal.Decimal `bson:"strike" json:"strike"`
+	}
+)
+
+const (
+	Share    Type = "share"
+	Bond     Type = "bond"
+	ETF      Type = "etf"
+	Future   Type = "future"
+	Option   Type = "option"
+	Currency Type = "currency"
+	// Index is only a benchmark, positions can't be opened in it.
+	Index Type = "index"
+	// Spread stands for a combination of instruments, it's never listed.
+	Spread Type = "spread"
+)
+
+const (
+	Trading       TradingStatus = "trading"
+	NotTrading    TradingStatus = "not_trading"
+	UnknownStatus TradingStatus = ""
+)
+
+// Positions opened before instruments had a currency were all on rouble boards.
+const defaultCurrency = "rub"
+
+var currencySigns = map[string]string{
+	"rub": "₽",
+	"usd": "$",
+	"eur": "€",
+	"cny": "¥",
+	"hkd": "HK$",
+	"gbp": "£",
+}
+
+// CurrencyCode is the lower-cased ISO currency code.
+func (i *Instrument) CurrencyCode() string {
+	if i.Currency == "" {
+		return defaultCurrency
+	}
+	return strings.ToLower(i.Currency)
+}
+
+// CurrencySign is the currency symbol, or the upper-cased ISO code if there is none.
+func (i *Instrument) CurrencySign() string {
+	cur := i.CurrencyCode()
+	if sign, ok := currencySigns[cur]; ok {
+		return sign
+	}
+	return strings.ToUpper(cur)
+}
+
+// RoundToTick rounds the price to the nearest multiple of the tick, if the tick is known.
+func (i *Instrument) RoundToTick(d decimal.Decimal) decimal.Decimal {
+	if !i.Tick.IsPositive() {
+		return d
+	}
+	return d.Div(i.Tick).Round(0).Mul(i.Tick)
+}
+
+// FormatPrice formats the price with as many decimals as the tick has and the
+// currency sign, or percent of nominal for bonds.
+func (i *Instrument) FormatPrice(d decimal.Decimal) string {
+	unit := " " + i.CurrencySign()
+	switch {
+	case i.IsBond():
+		unit = "%"
+	case i.Type == Spread && i.Currency == "":
+		// ratio spreads
+		unit = ""
+	}
+
+	if !i.Tick.IsPositive() {
+		return d.String() + unit
+	}
+
+	places := max(-i.Tick.Exponent(), 0)
+	// ticks like 0.010 keep trailing zeros in the exponent
+	for places > 0 && i.Tick.Truncate(places-1).Equal(i.Tick) {
+		places--
+	}
+	return i.RoundToTick(d).StringFixed(places) + unit
 }
 
 type WithPrice struct {
@@ -21,9 +135,22 @@ type WithPrice struct {
 
 type WithInterval struct {
 	*Instrument
-	OpenedAt       time.Time
-	Deadline       time.Time
-	MarketInterval int
+	OpenedAt time.Time
+	Deadline time.Time
+	Interval chart.Interval
+}
+
+// leadCandles is how much history before the open is shown on charts.
+const leadCandles = 120
+
+// Span is the charted time range: some history before the open, up to the deadline or now.
+func (wi WithInterval) Span(now time.Time) (from, to time.Time) {
+	from = wi.OpenedAt.Add(-leadCandles * wi.Interval.Duration())
+	to = wi.Deadline
+	if now.Before(to) {
+		to = now
+	}
+	return from, to
 }
 
 type priceProvider interface {
@@ -42,14 +169,17 @@ func (i *Instrument) WithPrice(ctx context.Context, pp priceProvider) (WithPrice
 	}, nil
 }
 
-func (i *Instrument) WithInterval(ctx context.Context, openedAt time.Time, deadline time.Time, marketInterval int) (WithInterval, error) {
+func (i *Instrument) WithInterval(ctx context.Context, openedAt time.Time, deadline time.Time, interval chart.Interval) (WithInterval, error) {
 	if openedAt.After(deadline) {
 		return WithInterval{}, fmt.Errorf("openedAt %s > deadline %s", openedAt, deadline)
 	}
+	if !interval.Valid() {
+		return WithInterval{}, fmt.Errorf("%w: %q", chart.ErrInterval, interval)
+	}
 	return WithInterval{
-		Instrument:     i,
-		OpenedAt:       openedAt,
-		Deadline:       deadline,
-		MarketInterval: marketInterval,
+		Instrument: i,
+		OpenedAt:   openedAt,
+		Deadline:   deadline,
+		Interval:   interval,
 	}, nil
 }
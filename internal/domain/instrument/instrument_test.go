@@ -0,0 +1,28 @@
+package instrument
+
+import (
+	"testing"
+
+	"github.com/greatcloak/decimal"
+)
+
+func TestFormatPrice(t *testing.T) {
+	cases := []struct {
+		i     Instrument
+		price string
+		want  string
+	}{
+		{Instrument{Currency: "rub", Tick: decimal.RequireFromString("0.01")}, "250.5", "250.50 ₽"},
+		{Instrument{Currency: "RUB", Tick: decimal.RequireFromString("0.5")}, "5501.3", "5501.5 ₽"},
+		{Instrument{Currency: "rub", Tick: decimal.RequireFromString("0.010")}, "1.234", "1.23 ₽"},
+		{Instrument{Currency: "usd", Tick: decimal.RequireFromString("1")}, "101.6", "102 $"},
+		{Instrument{Currency: "kzt", Tick: decimal.RequireFromString("0.1")}, "12", "12.0 KZT"},
+		// legacy positions have neither currency nor tick
+		{Instrument{}, "170.15", "170.15 ₽"},
+	}
+	for _, c := range cases {
+		if got := c.i.FormatPrice(decimal.RequireFromString(c.price)); got != c.want {
+			t.Errorf("%+v.FormatPrice(%s) = %q; want %q", c.i, c.price, got, c.want)
+		}
+	}
+}
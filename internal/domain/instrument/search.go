@@ -0,0 +1,87 @@
+package instrument
+
+import (
+	"sort"
+	"strings"
+)
+
+// Listing is an instrument as listed in the provider's catalog.
+type Listing struct {
+	Instrument
+	Isin string `json:"isin"`
+}
+
+// score ranks how well the listing matches an upper-cased query, 0 is no match.
+func (l Listing) score(q string) int {
+	var (
+		ticker = strings.ToUpper(l.Ticker)
+		name   = strings.ToUpper(l.Name)
+	)
+
+	switch {
+	case ticker == q:
+		return 100
+	case strings.ToUpper(l.Isin) == q:
+		return 90
+	case strings.HasPrefix(ticker, q):
+		return 80
+	case strings.HasPrefix(name, q):
+		return 60
+	}
+
+	for _, w := range strings.Fields(name) {
+		if strings.HasPrefix(w, q) {
+			return 50
+		}
+	}
+
+	switch {
+	case strings.Contains(ticker, q):
+		return 40
+	case strings.Contains(name, q):
+		return 30
+	}
+
+	return 0
+}
+
+// Search returns up to limit listings matching the query by ticker, name or ISIN,
+// best matches first. Among equal matches shorter tickers go first, so that
+// ordinary shares come before preferred ones.
+func Search(ll []Listing, query string, limit int) []Listing {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	if q == "" || limit <= 0 {
+		return nil
+	}
+
+	type match struct {
+		l     Listing
+		score int
+	}
+	var mm []match
+	for _, l := range ll {
+		if s := l.score(q); s > 0 {
+			mm = append(mm, match{l, s})
+		}
+	}
+
+	sort.SliceStable(mm, func(i, j int) bool {
+		if mm[i].score != mm[j].score {
+			return mm[i].score > mm[j].score
+		}
+		if len(mm[i].l.Ticker) != len(mm[j].l.Ticker) {
+			return len(mm[i].l.Ticker) < len(mm[j].l.Ticker)
+		}
+		return mm[i].l.Ticker < mm[j].l.Ticker
+	})
+
+	if len(mm) > limit {
+		mm = mm[:limit]
+	}
+	res := make([]Listing, len(mm))
+	for n, m := range mm {
+		res[n] = m.l
+	}
+
+	return res
+}
@@ -0,0 +1,43 @@
+package instrument
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	ll := []Listing{
+		{Instrument{Ticker: "SBERP", Name: "Сбер Банк - привилегированные акции"}, "RU0009029557"},
+		{Instrument{Ticker: "SBER", Name: "Сбер Банк"}, "RU0009029540"},
+		{Instrument{Ticker: "MGNT", Name: "Магнит"}, "RU000A0JKQU8"},
+		{Instrument{Ticker: "GAZP", Name: "Газпром"}, "RU0007661625"},
+		{Instrument{Ticker: "SGZH", Name: "Сегежа Групп"}, "RU000A102XG9"},
+	}
+	tickers := func(ll []Listing) []string {
+		var tt []string
+		for _, l := range ll {
+			tt = append(tt, l.Ticker)
+		}
+		return tt
+	}
+
+	cases := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"sber", 10, []string{"SBER", "SBERP"}},
+		{"SB", 10, []string{"SBER", "SBERP"}},
+		{"сбер", 1, []string{"SBER"}},
+		{"ru0007661625", 10, []string{"GAZP"}},
+		{"групп", 10, []string{"SGZH"}},
+		{"G", 10, []string{"GAZP", "MGNT", "SGZH"}},
+		{"  ", 10, nil},
+		{"nothing", 10, nil},
+	}
+	for _, c := range cases {
+		if got := tickers(Search(ll, c.query, c.limit)); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Search(%q) = %v; want %v", c.query, got, c.want)
+		}
+	}
+}
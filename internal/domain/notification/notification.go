@@ -0,0 +1,113 @@
+package notification
+
+import (
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/domain/subscription"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type Kind string
+
+const (
+	PositionOpened  Kind = "position_opened"
+	PositionChanged Kind = "position_changed"
+	PositionClosed  Kind = "position_closed"
+	// SubscriptionConfirmation asks the owner of the address to confirm it.
+	SubscriptionConfirmation Kind = "subscription_confirmation"
+)
+
+// Event is something readers following an analyst or an idea should hear about.
+type Event struct {
+	ID   string    `bson:"id"`
+	Kind Kind      `bson:"kind"`
+	At   time.Time `bson:"at"`
+
+	AnalystSlug string `bson:"analyst_slug"`
+	AnalystName string `bson:"analyst_name"`
+	IdeaSlug    string `bson:"idea_slug"`
+	IdeaName    string `bson:"idea_name"`
+
+	PositionID  int             `bson:"position_id"`
+	Ticker      string          `bson:"ticker"`
+	Type        position.Type   `bson:"type"`
+	OpenPrice   decimal.Decimal `bson:"open_price"`
+	TargetPrice decimal.Decimal `bson:"target_price"`
+	ClosedPrice decimal.Decimal `bson:"closed_price"`
+	Deadline    time.Time       `bson:"deadline"`
+}
+
+func newID() string {
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// PositionEvent builds an event of the given kind from the current state of the position.
+func PositionEvent(kind Kind, analystSlug, analystName, ideaSlug, ideaName string, p *position.Position) Event {
+	return Event{
+		ID:          newID(),
+		Kind:        kind,
+		At:          time.Now(),
+		AnalystSlug: analystSlug,
+		AnalystName: analystName,
+		IdeaSlug:    ideaSlug,
+		IdeaName:    ideaName,
+		PositionID:  p.ID,
+		Ticker:      p.Instrument.Ticker,
+		Type:        p.Type,
+		OpenPrice:   p.OpenPrice,
+		TargetPrice: p.TargetPrice,
+		ClosedPrice: p.ClosedPrice,
+		Deadline:    p.Deadline,
+	}
+}
+
+// ConfirmationEvent is the request to confirm the subscription, it goes to its address only.
+func ConfirmationEvent(s *subscription.Subscription) Event {
+	return Event{
+		ID:          newID(),
+		Kind:        SubscriptionConfirmation,
+		At:          time.Now(),
+		AnalystSlug: s.AnalystSlug,
+		IdeaSlug:    s.IdeaSlug,
+	}
+}
+
+type Status string
+
+const (
+	Pending Status = "pending"
+	Sent    Status = "sent"
+	Failed  Status = "failed"
+)
+
+// Delivery is an outbox entry: one event for one subscriber. It is persisted
+// before anything is sent, so pending deliveries survive restarts.
+type Delivery struct {
+	ID             string               `bson:"id"`
+	Event          Event                `bson:"event"`
+	SubscriptionID string               `bson:"subscription_id"`
+	Channel        subscription.Channel `bson:"channel"`
+	Address        string               `bson:"address"`
+
+	Status        Status    `bson:"status"`
+	Attempts      int       `bson:"attempts"`
+	NextAttemptAt time.Time `bson:"next_attempt_at"`
+	LastError     string    `bson:"last_error"`
+}
+
+func NewDelivery(e Event, s *subscription.Subscription) Delivery {
+	return Delivery{
+		ID:             newID(),
+		Event:          e,
+		SubscriptionID: s.ID,
+		Channel:        s.Channel,
+		Address:        s.Address,
+		Status:         Pending,
+		NextAttemptAt:  e.At,
+	}
+}
@@ -0,0 +1,80 @@
+package position
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/greatcloak/decimal"
+)
+
+var ErrNoBenchmarkQuote = errors.New("no benchmark quote for the position's lifespan")
+
+// Relative is how a position did compared to a benchmark index over its lifespan.
+type Relative struct {
+	Benchmark string
+	// BenchmarkP is the benchmark's return in percent.
+	BenchmarkP decimal.Decimal
+	// AlphaP is ProfitP less what the benchmark made in the position's direction:
+	// a short is compared to shorting the index.
+	AlphaP decimal.Decimal
+}
+
+type candleProvider interface {
+	GetCandles(ctx context.Context, i *instrument.WithInterval) ([]chart.Candle, error)
+}
+
+// Against compares the position to the benchmark using its daily closes at the
+// open and at the close, or now for active positions.
+func (wp WithProfit) Against(ctx context.Context, cp candleProvider, benchmark *instrument.Instrument) (Relative, error) {
+	end := timeext.Now()
+	if wp.Status == Closed {
+		end = wp.Deadline
+	}
+
+	wi, err := benchmark.WithInterval(ctx, wp.OpenDate, end, chart.Day)
+	if err != nil {
+		return Relative{}, fmt.Errorf("couldn't get benchmark lifespan: %w", err)
+	}
+	cc, err := cp.GetCandles(ctx, &wi)
+	if err != nil {
+		return Relative{}, fmt.Errorf("couldn't get benchmark candles: %w", err)
+	}
+
+	from, ok := chart.CloseAt(cc, wp.OpenDate)
+	if !ok || from == 0 {
+		return Relative{}, ErrNoBenchmarkQuote
+	}
+	to, ok := chart.CloseAt(cc, end)
+	if !ok {
+		return Relative{}, ErrNoBenchmarkQuote
+	}
+
+	mul := one
+	if wp.Type == Short {
+		mul = negOne
+	}
+
+	bp := decimal.NewFromFloat(to).Sub(decimal.NewFromFloat(from)).Div(decimal.NewFromFloat(from)).Mul(decimal.NewFromInt(100))
+	return Relative{
+		Benchmark:  benchmark.Ticker,
+		BenchmarkP: bp,
+		AlphaP:     wp.ProfitP.Sub(bp.Mul(mul)),
+	}, nil
+}
+
+// MeanAlpha is the equally weighted alpha of positions, false if there are none.
+func MeanAlpha(rr []Relative) (decimal.Decimal, bool) {
+	if len(rr) == 0 {
+		return decimal.Zero, false
+	}
+
+	sum := decimal.Zero
+	for _, r := range rr {
+		sum = sum.Add(r.AlphaP)
+	}
+	return sum.Div(decimal.NewFromInt(int64(len(rr)))), true
+}
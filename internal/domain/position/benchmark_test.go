@@ -0,0 +1,44 @@
+package position
+
+import (
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+func TestAlphaAgainstIndex(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		s, _ = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		p    = &Position{
+			Instrument:  find(t, s, "MGNT"),
+			Type:        Long,
+			Status:      Closed,
+			OpenPrice:   decimal.NewFromInt(8000),
+			OpenDate:    time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow),
+			ClosedPrice: decimal.NewFromInt(8800),
+			Deadline:    time.Date(2024, 5, 11, 12, 0, 0, 0, timeext.Moscow),
+		}
+	)
+	imoex, err := s.FindIndex(ctx, "IMOEX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wp, err := p.WithProfit(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// +10% against the index's +5%
+	r, err := wp.Against(ctx, s, imoex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.BenchmarkP.Equal(decimal.NewFromInt(5)) || !r.AlphaP.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("got benchmark %s%% and alpha %s%%, want 5%% and 5%%", r.BenchmarkP, r.AlphaP)
+	}
+}
@@ -6,9 +6,18 @@ var (
 	ErrNotFound               = errors.New("position not found")
 	ErrConflict               = errors.New("position with the same ID already exists")
 	ErrClosedPositionModified = errors.New("cannot modify a closed position")
+	ErrModified               = errors.New("position was changed since it was read")
 
 	ErrTicker        = errors.New("cannot create position: instrument with this ticker does not exist")
 	ErrParseType     = errors.New("position type does not exist")
 	ErrTargetPrice   = errors.New("wrong target price")
+	ErrTargetYield   = errors.New("wrong target yield")
 	ErrParseDeadline = errors.New("couldn't parse deadline")
+	ErrLegs          = errors.New("a spread needs two or more legs on different instruments with non-zero weights")
+	ErrSpreadKind    = errors.New("spread kind does not exist")
+	ErrSpreadValue   = errors.New("spread has no sold legs to divide by")
+	ErrExpired       = errors.New("contract has expired")
+	ErrFraction      = errors.New("wrong part of the position to add or reduce")
+	ErrScaleSpread   = errors.New("spreads can't be added to or reduced")
+	ErrPublishAt     = errors.New("publication time should be a future date and time")
 )
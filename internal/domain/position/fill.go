@@ -0,0 +1,192 @@
+package position
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+// Fill is an entry into the position or an exit from it. Fraction is a part of
+// the size the position was opened with, positive for entries and negative for exits.
+type Fill struct {
+	At       time.Time       `bson:"at"`
+	Price    decimal.Decimal `bson:"price"`
+	Fraction decimal.Decimal `bson:"fraction"`
+	// Realized is the money an exit made per unit of the opening size, income left out.
+	Realized decimal.Decimal `bson:"realized"`
+}
+
+// fills returns the position fills. Positions saved before the fills were
+// recorded were opened in one go and closed in one go.
+func (p *Position) fills() []Fill {
+	if len(p.Fills) > 0 {
+		return p.Fills
+	}
+	return []Fill{{At: p.OpenDate, Price: p.OpenPrice, Fraction: one}}
+}
+
+func (p *Position) IsScaled() bool {
+	return len(p.Fills) > 1
+}
+
+// book is the position replayed fill by fill at average cost, in units of the
+// opening size and before applying the long or short sign.
+type book struct {
+	size     decimal.Decimal
+	avgPrice decimal.Decimal
+	// cost is the value of the held units at their average cost.
+	cost decimal.Decimal
+	// invested and investedPrice are the capital and the price paid on all entries.
+	invested      decimal.Decimal
+	investedPrice decimal.Decimal
+	realized      decimal.Decimal
+	realizedPrice decimal.Decimal
+	income        decimal.Decimal
+	last          time.Time
+}
+
+func (p *Position) replay(dd []instrument.Dividend) book {
+	return p.replayUntil(dd, time.Time{})
+}
+
+// replayUntil replays the fills made by the moment, all of them for a zero one.
+func (p *Position) replayUntil(dd []instrument.Dividend, at time.Time) book {
+	var b book
+	for _, f := range p.fills() {
+		if !at.IsZero() && f.At.After(at) {
+			break
+		}
+		b.accrue(p.Instrument, dd, f.At)
+		b.fill(p.Instrument, f)
+	}
+	return b
+}
+
+// profit is what the held units at the price and all the exits have made by the
+// moment the income was accrued to.
+func (b book) profit(i *instrument.Instrument, price decimal.Decimal) decimal.Decimal {
+	unrealized := i.Value(price, b.last).Mul(b.size).Sub(b.cost)
+	return b.realized.Add(unrealized).Add(b.income)
+}
+
+// ProfitPAt is the total return in percent the position had at a past moment with
+// the price then, counting only the fills made by then.
+func (p *Position) ProfitPAt(at time.Time, price decimal.Decimal, dd []instrument.Dividend) decimal.Decimal {
+	b := p.replayUntil(dd, at)
+	if !b.invested.IsPositive() {
+		return decimal.Zero
+	}
+	b.accrue(p.Instrument, dd, at)
+	return b.profit(p.Instrument, price).Mul(p.sign()).Div(b.invested).Mul(decimal.NewFromInt(100))
+}
+
+// accrue adds the dividends and coupons paid on the held units up to the moment.
+func (b *book) accrue(i *instrument.Instrument, dd []instrument.Dividend, to time.Time) {
+	perUnit := i.CouponIncome(b.last, to).Add(instrument.DividendIncome(dd, b.last, to))
+	b.income = b.income.Add(perUnit.Mul(b.size))
+	b.last = to
+}
+
+func (b *book) fill(i *instrument.Instrument, f Fill) {
+	value := i.Value(f.Price, f.At)
+
+	if f.Fraction.IsPositive() {
+		size := b.size.Add(f.Fraction)
+		b.avgPrice = b.avgPrice.Mul(b.size).Add(f.Price.Mul(f.Fraction)).Div(size)
+		b.cost = b.cost.Add(value.Mul(f.Fraction))
+		b.invested = b.invested.Add(i.Capital(f.Price, f.At).Mul(f.Fraction))
+		b.investedPrice = b.investedPrice.Add(f.Price.Mul(f.Fraction))
+		b.size = size
+		return
+	}
+
+	q := f.Fraction.Neg()
+	unitCost := b.cost.Div(b.size)
+	b.realized = b.realized.Add(value.Sub(unitCost).Mul(q))
+	b.realizedPrice = b.realizedPrice.Add(f.Price.Sub(b.avgPrice).Mul(q))
+	b.cost = b.cost.Sub(unitCost.Mul(q))
+	b.size = b.size.Sub(q)
+}
+
+// parseFraction reads a part of the opening size given in percent.
+func parseFraction(s string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if !d.IsPositive() {
+		return decimal.Zero, ErrFraction
+	}
+	return d.Div(decimal.NewFromInt(100)), nil
+}
+
+// Add enters more of the position at the current price: buys for a long and sells for a short.
+func (wp *WithProfit) Add(ctx context.Context, pu positionUpdater, fraction decimal.Decimal) error {
+	return wp.scale(ctx, pu, AddChange, Fill{
+		At:       timeext.Now(),
+		Price:    wp.Instrument.Price,
+		Fraction: fraction,
+	})
+}
+
+// Reduce exits a part of the position at the current price, realizing its P&L.
+// Reducing by all that's left closes the position.
+func (wp *WithProfit) Reduce(ctx context.Context, pu positionUpdater, fraction decimal.Decimal) error {
+	return wp.reduceAt(ctx, pu, timeext.Now(), wp.Instrument.Price, fraction)
+}
+
+func (wp *WithProfit) reduceAt(ctx context.Context, pu positionUpdater, at time.Time, price, fraction decimal.Decimal) error {
+	b := wp.replay(nil)
+	if fraction.GreaterThan(b.size) {
+		return ErrFraction
+	}
+	if fraction.Equal(b.size) {
+		return wp.closeAt(ctx, pu, at, price)
+	}
+
+	f := Fill{
+		At:       at,
+		Price:    price,
+		Fraction: fraction.Neg(),
+	}
+	before := b.realized
+	b.fill(wp.Instrument.Instrument, f)
+	f.Realized = b.realized.Sub(before).Mul(wp.sign())
+
+	return wp.scale(ctx, pu, ReduceChange, f)
+}
+
+func (wp *WithProfit) scale(ctx context.Context, pu positionUpdater, kind ChangeKind, f Fill) error {
+	if wp.Status == Closed {
+		return ErrClosedPositionModified
+	}
+	if wp.IsSpread() {
+		// a spread would need fills of every leg
+		return ErrScaleSpread
+	}
+
+	oldf, oldh := wp.Fills, wp.History
+	wp.Fills = append(slices.Clone(wp.fills()), f)
+	wp.History = append(wp.History, Change{
+		Kind:        kind,
+		At:          f.At,
+		Price:       f.Price,
+		Fraction:    f.Fraction,
+		TargetPrice: wp.TargetPrice,
+		Deadline:    wp.Deadline,
+	})
+
+	err := pu.Update(ctx, wp.Position)
+	if err == nil {
+		return nil
+	}
+
+	wp.Fills, wp.History = oldf, oldh
+	return fmt.Errorf("couldn't save position: %w", err)
+}
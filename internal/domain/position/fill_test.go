@@ -0,0 +1,47 @@
+package position
+
+import (
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+func TestScaledPositionReturn(t *testing.T) {
+	var (
+		s, _ = fake.NewTest(t, time.Date(2024, 5, 15, 12, 0, 0, 0, timeext.Moscow))
+		at   = func(h, m int) time.Time { return time.Date(2024, 5, 13, h, m, 0, 0, timeext.Moscow) }
+		p    = &Position{
+			Instrument: find(t, s, "GAZP"),
+			Type:       Long,
+			Status:     Active,
+			OpenPrice:  decimal.NewFromInt(130),
+			OpenDate:   at(10, 0),
+			Deadline:   time.Date(2024, 6, 1, 18, 50, 0, 0, timeext.Moscow),
+			// half more at 132, then half out at 132 too, before a dividend of 6.5
+			Fills: []Fill{
+				{At: at(10, 0), Price: decimal.NewFromInt(130), Fraction: decimal.NewFromInt(1)},
+				{At: at(11, 0), Price: decimal.NewFromInt(132), Fraction: decimal.NewFromFloat(0.5)},
+				{At: at(11, 30), Price: decimal.NewFromInt(132), Fraction: decimal.NewFromFloat(-0.5)},
+			},
+		}
+	)
+
+	wp, err := p.WithProfit(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wp.Size.Equal(decimal.NewFromInt(1)) || !wp.AvgPrice.Round(2).Equal(decimal.RequireFromString("130.67")) {
+		t.Errorf("got size %s at %s, want 1 at 130.67", wp.Size, wp.AvgPrice)
+	}
+	if !wp.Realized.Round(2).Equal(decimal.RequireFromString("0.67")) || !wp.Income.Equal(decimal.NewFromFloat(6.5)) {
+		t.Errorf("got realized %s and income %s, want 0.67 and 6.5", wp.Realized, wp.Income)
+	}
+	// 0.67 realized, 2.83 unrealized at 133.5 and the dividend, on 130 + 66 put in
+	if !wp.PnL.Round(2).Equal(decimal.NewFromInt(10)) || !wp.ProfitP.Round(2).Equal(decimal.RequireFromString("5.1")) {
+		t.Errorf("got P&L %s and profit %s%%, want 10 and 5.1%%", wp.PnL, wp.ProfitP)
+	}
+}
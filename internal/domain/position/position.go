@@ -3,10 +3,13 @@ package position
 import (
 	"changemedaddy/internal/domain/instrument"
 	"changemedaddy/internal/pkg/assert"
+	"changemedaddy/internal/pkg/timeext"
 	"context"
 	"errors"
 	"fmt"
 	"math/rand/v2"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/greatcloak/decimal"
@@ -20,20 +23,63 @@ type (
 	Position struct {
 		ID int `bson:"id"`
 
+		// Instrument of a spread stands for the legs, it isn't quoted itself.
 		Instrument *instrument.Instrument `bson:"instrument"`
+		// Legs and Spread are set for spreads only, prices are then spread values.
+		Legs   []Leg      `bson:"legs,omitempty"`
+		Spread SpreadKind `bson:"spread,omitempty"`
 
 		Type   Type   `bson:"type"`
 		Status Status `bson:"status"`
 
+		// OpenPrice is the price of the first entry, the average one is in the fills.
 		OpenPrice   decimal.Decimal `bson:"open_price"`
 		TargetPrice decimal.Decimal `bson:"target_price"`
 		ClosedPrice decimal.Decimal `bson:"closed_price"`
+		// TargetYield is the yield to maturity the analyst aims at, only for bonds.
+		// TargetPrice is then the price at which the bond yields that at the deadline.
+		TargetYield decimal.Decimal `bson:"target_yield"`
+		// Targets are staged, the last one is TargetPrice. Empty for a single target.
+		Targets []Target `bson:"targets,omitempty"`
 
 		Deadline time.Time `bson:"deadline"`
 		OpenDate time.Time `bson:"open_date"`
+		// PublishAt is when a pending position opens.
+		PublishAt time.Time `bson:"publish_at,omitempty"`
+
+		// Fills are empty for spreads and for positions never added to or reduced.
+		Fills   []Fill   `bson:"fills,omitempty"`
+		History []Change `bson:"history"`
+		// Announced is how many of the changes in the history subscribers were
+		// told about, the rest are announced in the background.
+		Announced int `bson:"announced"`
+	}
+
+	ChangeKind string
+
+	// Change is an entry of the position's history. Price is the instrument price for
+	// opens, closes and fills, TargetPrice and Deadline are the values after the change.
+	Change struct {
+		Kind        ChangeKind      `bson:"kind"`
+		At          time.Time       `bson:"at"`
+		Price       decimal.Decimal `bson:"price"`
+		Fraction    decimal.Decimal `bson:"fraction"`
+		TargetPrice decimal.Decimal `bson:"target_price"`
+		Deadline    time.Time       `bson:"deadline"`
 	}
 )
 
+const (
+	OpenedChange   ChangeKind = "opened"
+	TargetChange   ChangeKind = "target"
+	DeadlineChange ChangeKind = "deadline"
+	CloseChange    ChangeKind = "close"
+	AddChange      ChangeKind = "add"
+	ReduceChange   ChangeKind = "reduce"
+	// TargetHitChange has the target's price and the fraction it exits.
+	TargetHitChange ChangeKind = "target_hit"
+)
+
 const (
 	Long  Type = "long"
 	Short Type = "short"
@@ -42,6 +88,10 @@ const (
 const (
 	Active Status = "active"
 	Closed Status = "closed"
+	// Pending positions are scheduled to open later, only their author sees them.
+	Pending Status = "pending"
+	// Draft positions belong to unpublished ideas, they open anew with the idea.
+	Draft Status = "draft"
 )
 
 type priceProvider interface {
@@ -61,10 +111,95 @@ type CreationOptions struct {
 	Ticker      string `form:"ticker"`
 	Type        Type   `form:"type"`
 	TargetPrice string `form:"target_price"`
-	Deadline    string `form:"deadline"`
+	TargetYield string `form:"target_yield"`
+	// Targets are staged, like "300 50%, 350", they override TargetPrice and
+	// can't go with TargetYield.
+	Targets  string `form:"targets"`
+	Deadline string `form:"deadline"`
+	// Legs make the position a spread, Ticker is ignored then.
+	Legs   string     `form:"legs"`
+	Spread SpreadKind `form:"spread"`
+	// PublishAt schedules the opening, like "13.05.2024 10:00". Empty opens now.
+	PublishAt string `form:"publish_at"`
+	// Draft is set for the positions of drafts, nothing tracks them until they open.
+	Draft bool `form:"-"`
+}
+
+// parsePublishAt reads when a position should open, zero for now.
+func parsePublishAt(s string, now time.Time) (time.Time, error) {
+	if strings.TrimSpace(s) == "" {
+		return time.Time{}, nil
+	}
+
+	at, err := timeext.ParseDateTime(strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, errors.Join(err, ErrPublishAt)
+	}
+	if !at.After(now) {
+		return time.Time{}, ErrPublishAt
+	}
+	return at, nil
+}
+
+// opening is the status of a new position opening at publishAt.
+func opening(publishAt time.Time, draft bool) Status {
+	switch {
+	case draft:
+		return Draft
+	case publishAt.IsZero():
+		return Active
+	}
+	return Pending
+}
+
+// parseDeadline makes a date the end of its trading session, or of the next one
+// if the exchange is closed that day.
+func parseDeadline(s string) (time.Time, error) {
+	d, err := timeext.ParseDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return timeext.MOEX.SessionEnd(d), nil
+}
+
+// New opens the position as of now, or schedules it if opt.PublishAt is set.
+func New(ctx context.Context, now time.Time, mp marketProvider, ps positionSaver, opt CreationOptions) (*Position, error) {
+	pos, err := build(ctx, now, mp, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ps.Save(ctx, pos); err != nil {
+		return nil, fmt.Errorf("couldn't save position: %w", err)
+	}
+
+	return pos, nil
 }
 
-func New(ctx context.Context, mp marketProvider, ps positionSaver, opt CreationOptions) (*Position, error) {
+// Redraft rewrites the position from opt as if it were opened now, only the ID
+// is kept. It's for the positions of drafts: nobody has seen them, so nothing
+// goes to the history.
+func (p *Position) Redraft(ctx context.Context, now time.Time, mp marketProvider, pu positionUpdater, opt CreationOptions) error {
+	r, err := build(ctx, now, mp, opt)
+	if err != nil {
+		return err
+	}
+	r.ID = p.ID
+
+	if err := pu.Update(ctx, r); err != nil {
+		return fmt.Errorf("couldn't update position: %w", err)
+	}
+
+	*p = *r
+	return nil
+}
+
+// build makes the position New opens, without saving it.
+func build(ctx context.Context, now time.Time, mp marketProvider, opt CreationOptions) (*Position, error) {
+	if strings.TrimSpace(opt.Legs) != "" {
+		return buildSpread(ctx, now, mp, opt)
+	}
+
 	var parseError error
 
 	i, err := mp.Find(ctx, opt.Ticker)
@@ -72,22 +207,56 @@ func New(ctx context.Context, mp marketProvider, ps positionSaver, opt CreationO
 		parseError = errors.Join(parseError, err, ErrTicker)
 	} else if err != nil {
 		return nil, fmt.Errorf("couldn't get instrument: %w", err)
+	} else if i.Expired(now) {
+		parseError = errors.Join(parseError, ErrExpired, ErrTicker)
 	}
 
 	if opt.Type != Long && opt.Type != Short {
 		parseError = errors.Join(parseError, ErrParseType)
 	}
 
-	tp, err := decimal.NewFromString(opt.TargetPrice)
-	if err != nil || tp.LessThan(decimal.Zero) {
-		parseError = errors.Join(parseError, err, ErrTargetPrice)
+	publishAt, err := parsePublishAt(opt.PublishAt, now)
+	if err != nil {
+		parseError = errors.Join(parseError, err)
 	}
 
-	deadline, err := time.ParseInLocation("2.01.2006", opt.Deadline, time.Local)
+	deadline, err := parseDeadline(opt.Deadline)
 	if err != nil {
 		parseError = errors.Join(parseError, err, ErrParseDeadline)
-	} else if deadline.Before(time.Now()) {
+	} else if deadline.Before(now) || !publishAt.Before(deadline) && !publishAt.IsZero() {
 		parseError = errors.Join(parseError, ErrParseDeadline)
+	} else if i != nil && i.IsDerivative() && deadline.After(i.Expiry) {
+		// the contract settles at expiry whatever the analyst planned
+		deadline = i.Expiry
+	}
+
+	var (
+		tp, ty decimal.Decimal
+		tt     []Target
+	)
+	if opt.Targets != "" {
+		tt, err = parseTargets(opt.Targets)
+		if err != nil {
+			parseError = errors.Join(parseError, err)
+		} else {
+			tp = tt[len(tt)-1].Price
+		}
+		if opt.TargetYield != "" {
+			// a yield would silently lose to the staged prices
+			parseError = errors.Join(parseError, ErrTargetYield)
+		}
+	} else if opt.TargetYield != "" {
+		ty, err = decimal.NewFromString(opt.TargetYield)
+		if err != nil || i == nil || !i.IsBond() {
+			parseError = errors.Join(parseError, err, ErrTargetYield)
+		} else if tp, err = i.PriceForYield(ty, deadline); err != nil {
+			parseError = errors.Join(parseError, err, ErrTargetYield)
+		}
+	} else {
+		tp, err = decimal.NewFromString(opt.TargetPrice)
+		if err != nil || tp.LessThan(decimal.Zero) {
+			parseError = errors.Join(parseError, err, ErrTargetPrice)
+		}
 	}
 
 	wp, err := i.WithPrice(ctx, mp)
@@ -95,10 +264,18 @@ func New(ctx context.Context, mp marketProvider, ps positionSaver, opt CreationO
 		return nil, fmt.Errorf("couldn't get instrument (%q) price: %w", i.Ticker, err)
 	}
 
+	targetErr := ErrTargetPrice
+	if !ty.IsZero() {
+		targetErr = ErrTargetYield
+	}
 	if opt.Type == Long && tp.LessThan(wp.Price) {
-		parseError = errors.Join(ErrTargetPrice)
+		parseError = errors.Join(parseError, targetErr)
 	} else if opt.Type == Short && wp.Price.LessThan(tp) {
-		parseError = errors.Join(ErrTargetPrice)
+		parseError = errors.Join(parseError, targetErr)
+	} else if tt != nil {
+		if err := (&Position{Type: opt.Type}).checkTargets(tt, wp.Price, one); err != nil {
+			parseError = errors.Join(parseError, err)
+		}
 	}
 
 	if parseError != nil {
@@ -107,57 +284,150 @@ func New(ctx context.Context, mp marketProvider, ps positionSaver, opt CreationO
 
 	pos := &Position{
 		ID:          rand.Int(),
+		Fills:       []Fill{{At: now, Price: wp.Price, Fraction: one}},
 		Instrument:  i,
 		Type:        opt.Type,
-		Status:      Active,
+		Status:      opening(publishAt, opt.Draft),
 		OpenPrice:   wp.Price,
 		TargetPrice: tp,
+		TargetYield: ty,
+		Targets:     tt,
 		Deadline:    deadline,
-		OpenDate:    time.Now(),
+		OpenDate:    now,
+		PublishAt:   publishAt,
+		History: []Change{{
+			Kind:        OpenedChange,
+			At:          now,
+			Price:       wp.Price,
+			TargetPrice: tp,
+			Deadline:    deadline,
+		}},
 	}
 
-	err = ps.Save(ctx, pos)
-	if err != nil {
-		return nil, fmt.Errorf("couldn't save position: %w", err)
+	return pos, nil
+}
+
+// Changes returns the position history. Positions saved before the history was
+// recorded get one reconstructed from the open and close fields.
+func (p *Position) Changes() []Change {
+	if len(p.History) > 0 {
+		return p.History
 	}
 
-	return pos, nil
+	cc := []Change{{
+		Kind:        OpenedChange,
+		At:          p.OpenDate,
+		Price:       p.OpenPrice,
+		TargetPrice: p.TargetPrice,
+		Deadline:    p.Deadline,
+	}}
+	if p.Status == Closed {
+		cc = append(cc, Change{
+			Kind:        CloseChange,
+			At:          p.Deadline,
+			Price:       p.ClosedPrice,
+			TargetPrice: p.TargetPrice,
+			Deadline:    p.Deadline,
+		})
+	}
+
+	return cc
 }
 
 type WithProfit struct {
 	*Position
 	Instrument *instrument.WithPrice
-	ProfitP    decimal.Decimal
+	// ProfitP is the total return in percent of all that was put in, realized and
+	// unrealized: dividends, coupons and accrued interest included.
+	ProfitP decimal.Decimal
+	// PriceReturnP is the return in percent from the price change alone.
+	PriceReturnP decimal.Decimal
+	// Income is the dividends or coupons received per unit of the opening size.
+	Income decimal.Decimal
+	// AvgPrice is the average entry price, Size the part of the opening size still
+	// held and Realized the money the exits made per unit of the opening size.
+	AvgPrice decimal.Decimal
+	Size     decimal.Decimal
+	Realized decimal.Decimal
+	// Yield is the bond's current yield to maturity in percent, zero if there is none.
+	Yield decimal.Decimal
+	// PnL is the money made per unit or contract, income included.
+	PnL decimal.Decimal
+	// LegPrices are the current prices of a spread's legs.
+	LegPrices []decimal.Decimal
+}
+
+type quoteProvider interface {
+	priceProvider
+	Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error)
+}
+
+// sign turns the returns of holding the instrument into the position's returns.
+func (p *Position) sign() decimal.Decimal {
+	switch p.Type {
+	case Long:
+		return one
+	case Short:
+		return negOne
+	default:
+		panic(fmt.Sprintf("unknown position type %q in trusted data", p.Type))
+	}
 }
 
-func (p *Position) WithProfit(ctx context.Context, pp priceProvider) (WithProfit, error) {
-	wp, err := p.Instrument.WithPrice(ctx, pp)
+func (p *Position) WithProfit(ctx context.Context, qp quoteProvider) (WithProfit, error) {
+	mul := p.sign()
+
+	if p.IsSpread() {
+		return p.spreadWithProfit(ctx, qp, mul)
+	}
+
+	wp, err := p.Instrument.WithPrice(ctx, qp)
 	if err != nil {
 		return WithProfit{}, fmt.Errorf("couldn't get instrument quote: %w", err)
 	}
 
+	end, price := timeext.Now(), wp.Price
+	if p.Status == Closed {
+		end, price = p.Deadline, p.ClosedPrice
+	}
+
+	var dd []instrument.Dividend
+	if p.Instrument.PaysDividends() {
+		dd, err = qp.Dividends(ctx, p.Instrument, p.OpenDate, end)
+		if err != nil {
+			return WithProfit{}, fmt.Errorf("couldn't get dividends: %w", err)
+		}
+	}
+	b := p.replay(dd)
+	b.accrue(p.Instrument, dd, end)
+
+	// shorts pay out the dividends and coupons instead of receiving them, returns of
+	// futures are on the margin
 	var (
-		mul     decimal.Decimal
-		profitP decimal.Decimal
+		hundred      = decimal.NewFromInt(100)
+		profit       = b.profit(p.Instrument, price).Mul(mul)
+		profitP      = profit.Div(b.invested).Mul(hundred)
+		priceChange  = b.realizedPrice.Add(price.Sub(b.avgPrice).Mul(b.size))
+		priceReturnP = priceChange.Mul(mul).Div(b.investedPrice).Mul(hundred)
 	)
-	if p.Type == Long {
-		mul = one
-	} else if p.Type == Short {
-		mul = negOne
-	} else {
-		panic(fmt.Sprintf("unknown position type %q in trusted data", p.Type))
-	}
 
-	if p.Status == Closed {
-		profitP = p.ClosedPrice.Sub(p.OpenPrice).Mul(mul).Div(p.OpenPrice).Mul(decimal.NewFromInt(100))
-	} else {
-		profitP = wp.Price.Sub(p.OpenPrice).Mul(mul).Div(p.OpenPrice).Mul(decimal.NewFromInt(100))
+	var yield decimal.Decimal
+	if p.Instrument.IsBond() && p.Status != Closed {
+		// quotes of bonds close to maturity can be off, no yield is better than a wild one
+		yield, _ = p.Instrument.YieldToMaturity(wp.Price, end)
 	}
 
 	return WithProfit{
-		Position:   p,
-		Instrument: &wp,
-		ProfitP:    profitP,
+		Position:     p,
+		Instrument:   &wp,
+		ProfitP:      profitP,
+		PriceReturnP: priceReturnP,
+		Income:       b.income,
+		AvgPrice:     b.avgPrice,
+		Size:         b.size,
+		Realized:     b.realized.Mul(mul),
+		Yield:        yield,
+		PnL:          profit,
 	}, nil
 }
 
@@ -175,15 +445,126 @@ type positionUpdater interface {
 }
 
 func (wp WithProfit) Close(ctx context.Context, pu positionUpdater) error {
+	return wp.closeAt(ctx, pu, timeext.Now(), wp.Instrument.Price)
+}
+
+// Expire closes a position in a contract that has expired at the settlement price.
+// Expired contracts may have no quotes, so there's no WithProfit to close.
+func (p *Position) Expire(ctx context.Context, pu positionUpdater, settlement decimal.Decimal) error {
+	assert.That(p.Instrument.IsDerivative() && !p.IsSpread(), "expiring a position that has no expiry")
+
+	wp := WithProfit{
+		Position:   p,
+		Instrument: &instrument.WithPrice{Instrument: p.Instrument, Price: settlement},
+	}
+	return wp.closeAt(ctx, pu, p.Instrument.Expiry, settlement)
+}
+
+// Reopened is the position entered anew at now's price, with its fills
+// and history forgotten. It's for positions that weren't public yet, so that what
+// they did in private doesn't count, and for the pending ones. The position
+// itself is left as it is.
+func (p *Position) Reopened(ctx context.Context, now time.Time, pp priceProvider) (*Position, error) {
+	if p.Status == Closed {
+		return nil, ErrClosedPositionModified
+	}
+
+	if p.Instrument.Expired(now) {
+		return nil, ErrExpired
+	}
+	if p.Deadline.Before(now) {
+		return nil, ErrParseDeadline
+	}
+
+	var (
+		price decimal.Decimal
+		legs  = slices.Clone(p.Legs)
+	)
+	if p.IsSpread() {
+		prices, err := legPrices(ctx, pp, legs)
+		if err != nil {
+			return nil, err
+		}
+		for n := range legs {
+			legs[n].OpenPrice = prices[n]
+		}
+		if price, err = SpreadValue(p.Spread, legs, prices); err != nil {
+			return nil, fmt.Errorf("couldn't price spread: %w", err)
+		}
+	} else {
+		var err error
+		if price, err = pp.Price(ctx, p.Instrument); err != nil {
+			return nil, fmt.Errorf("couldn't get instrument (%q) price: %w", p.Instrument.Ticker, err)
+		}
+	}
+
+	tt := slices.Clone(p.Targets)
+	for n := range tt {
+		tt[n].HitAt = time.Time{}
+	}
+	if err := p.checkTargets(append(tt, Target{Price: p.TargetPrice}), price, one); err != nil {
+		return nil, err
+	}
+
+	r := *p
+	r.Status = Active
+	r.PublishAt = time.Time{}
+	r.Announced = 0
+	r.Legs = legs
+	r.Targets = tt
+	r.OpenPrice = price
+	r.OpenDate = now
+	r.Fills = nil
+	if !p.IsSpread() {
+		r.Fills = []Fill{{At: now, Price: price, Fraction: one}}
+	}
+	r.History = []Change{{
+		Kind:        OpenedChange,
+		At:          now,
+		Price:       price,
+		TargetPrice: p.TargetPrice,
+		Deadline:    p.Deadline,
+	}}
+
+	return &r, nil
+}
+
+// Reopen saves the position Reopened.
+func (p *Position) Reopen(ctx context.Context, now time.Time, pp priceProvider, pu positionUpdater) error {
+	r, err := p.Reopened(ctx, now, pp)
+	if err != nil {
+		return err
+	}
+	if err := pu.Update(ctx, r); err != nil {
+		return fmt.Errorf("couldn't save position: %w", err)
+	}
+	*p = *r
+	return nil
+}
+
+func (wp WithProfit) closeAt(ctx context.Context, pu positionUpdater, at time.Time, price decimal.Decimal) error {
 	if wp.Status == Closed {
 		return ErrClosedPositionModified
 	}
 
 	oldd := wp.Position.Deadline
+	olds := wp.Status
+	oldh := wp.History
+	oldl := slices.Clone(wp.Legs)
 
 	wp.Status = Closed
-	wp.ClosedPrice = wp.Instrument.Price
-	wp.Deadline = time.Now()
+	wp.ClosedPrice = price
+	for n := range wp.Legs {
+		wp.Legs[n].ClosedPrice = wp.LegPrices[n]
+	}
+	wp.Deadline = at
+	wp.History = append(wp.History, Change{
+		Kind:        CloseChange,
+		At:          wp.Deadline,
+		Price:       wp.ClosedPrice,
+		TargetPrice: wp.TargetPrice,
+		Deadline:    wp.Deadline,
+	})
 
 	err := pu.Update(ctx, wp.Position)
 	if err == nil {
@@ -191,7 +572,9 @@ func (wp WithProfit) Close(ctx context.Context, pu positionUpdater) error {
 	}
 
 	wp.Deadline = oldd
-	wp.Status = Active
+	wp.Status = olds
+	wp.History = oldh
+	wp.Legs = oldl
 	return fmt.Errorf("couldn't save position: %w", err)
 }
 
@@ -200,15 +583,21 @@ func (p *Position) ChangeDeadline(ctx context.Context, pu positionUpdater, newDe
 		return ErrClosedPositionModified
 	}
 
-	old := p.Deadline
+	old, oldh := p.Deadline, p.History
 	p.Deadline = newDeadline
+	p.History = append(p.History, Change{
+		Kind:        DeadlineChange,
+		At:          timeext.Now(),
+		TargetPrice: p.TargetPrice,
+		Deadline:    newDeadline,
+	})
 
 	err := pu.Update(ctx, p)
 	if err == nil {
 		return nil
 	}
 
-	p.Deadline = old
+	p.Deadline, p.History = old, oldh
 	return fmt.Errorf("couldn't save position: %w", err)
 }
 
@@ -217,24 +606,42 @@ func (p *Position) ChangeTargetPrice(ctx context.Context, pu positionUpdater, ne
 		return ErrClosedPositionModified
 	}
 
-	assert.That(newTargetPrice.GreaterThan(decimal.Zero), "non-positive target price in trusted data")
+	assert.That(newTargetPrice.GreaterThan(decimal.Zero) || p.allowsNegativeTarget(), "non-positive target price in trusted data")
+
+	if len(p.Targets) > 0 {
+		// a single target replaces the staged ones left
+		return p.ChangeTargets(ctx, pu, []Target{{Price: newTargetPrice}})
+	}
 
-	old := p.TargetPrice
+	old, oldy, oldh := p.TargetPrice, p.TargetYield, p.History
 	p.TargetPrice = newTargetPrice
+	// the target is a price from now on
+	p.TargetYield = decimal.Zero
+	p.History = append(p.History, Change{
+		Kind:        TargetChange,
+		At:          timeext.Now(),
+		TargetPrice: newTargetPrice,
+		Deadline:    p.Deadline,
+	})
 
 	err := pu.Update(ctx, p)
 	if err == nil {
 		return nil
 	}
 
-	p.TargetPrice = old
+	p.TargetPrice, p.TargetYield, p.History = old, oldy, oldh
 	return fmt.Errorf("couldn't save position: %w", err)
 }
 
 type ChangeOptions struct {
 	TargetPrice string `form:"target_price"`
-	Deadline    string `form:"deadline"`
-	Close       string `form:"close"`
+	// Targets replace the staged targets that haven't been hit, like "300 50%, 350".
+	Targets  string `form:"targets"`
+	Deadline string `form:"deadline"`
+	// Add and Reduce are in percent of the opening size.
+	Add    string `form:"add"`
+	Reduce string `form:"reduce"`
+	Close  string `form:"close"`
 }
 
 func (wp *WithProfit) ApplyChange(ctx context.Context, opt ChangeOptions, pu positionUpdater) error {
@@ -242,7 +649,7 @@ func (wp *WithProfit) ApplyChange(ctx context.Context, opt ChangeOptions, pu pos
 
 	if opt.TargetPrice != "" {
 		tp, err := decimal.NewFromString(opt.TargetPrice)
-		if err != nil || tp.LessThan(decimal.Zero) {
+		if err != nil || tp.LessThan(decimal.Zero) && !wp.allowsNegativeTarget() {
 			parseError = errors.Join(parseError, err, ErrTargetPrice)
 		} else {
 			if wp.Type == Long && tp.LessThan(wp.Instrument.Price) {
@@ -257,11 +664,23 @@ func (wp *WithProfit) ApplyChange(ctx context.Context, opt ChangeOptions, pu pos
 		}
 	}
 
+	if opt.Targets != "" {
+		tt, err := parseTargets(opt.Targets)
+		if err == nil {
+			err = wp.checkTargets(tt, wp.Instrument.Price, wp.Size)
+		}
+		if err != nil {
+			parseError = errors.Join(parseError, err)
+		} else if err := wp.ChangeTargets(ctx, pu, tt); err != nil {
+			return fmt.Errorf("couldn't change targets: %w", err)
+		}
+	}
+
 	if opt.Deadline != "" {
-		deadline, err := time.ParseInLocation("2.01.2006", opt.Deadline, time.Local)
+		deadline, err := parseDeadline(opt.Deadline)
 		if err != nil {
 			parseError = errors.Join(parseError, err, ErrParseDeadline)
-		} else if deadline.Before(time.Now()) {
+		} else if deadline.Before(timeext.Now()) {
 			parseError = errors.Join(parseError, ErrParseDeadline)
 		} else {
 			if err := wp.ChangeDeadline(ctx, pu, deadline); err != nil {
@@ -270,11 +689,38 @@ func (wp *WithProfit) ApplyChange(ctx context.Context, opt ChangeOptions, pu pos
 		}
 	}
 
+	var add, reduce decimal.Decimal
+	if opt.Add != "" {
+		f, err := parseFraction(opt.Add)
+		if err != nil {
+			parseError = errors.Join(parseError, err, ErrFraction)
+		}
+		add = f
+	}
+	if opt.Reduce != "" {
+		f, err := parseFraction(opt.Reduce)
+		if err != nil {
+			parseError = errors.Join(parseError, err, ErrFraction)
+		}
+		reduce = f
+	}
+
 	if parseError != nil {
 		return parseError
 	}
 
-	if opt.Close == "true" {
+	if add.IsPositive() {
+		if err := wp.Add(ctx, pu, add); err != nil {
+			return fmt.Errorf("couldn't add to position: %w", err)
+		}
+	}
+	if reduce.IsPositive() {
+		if err := wp.Reduce(ctx, pu, reduce); err != nil {
+			return fmt.Errorf("couldn't reduce position: %w", err)
+		}
+	}
+
+	if opt.Close == "true" && wp.Status != Closed {
 		if err := wp.Close(ctx, pu); err != nil {
 			return fmt.Errorf("couldn't close position: %w", err)
 		}
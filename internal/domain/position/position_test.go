@@ -0,0 +1,188 @@
+package position
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+// market is what the tests need of the fake market.
+type market interface {
+	marketProvider
+	quoteProvider
+	candleProvider
+	FindIndex(ctx context.Context, ticker string) (*instrument.Instrument, error)
+}
+
+func find(t *testing.T, m market, ticker string) *instrument.Instrument {
+	t.Helper()
+
+	i, err := m.Find(context.Background(), ticker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+func TestBondTotalReturn(t *testing.T) {
+	var (
+		s, _ = fake.NewTest(t, time.Date(2024, 7, 1, 12, 0, 0, 0, timeext.Moscow))
+		ofz  = find(t, s, "SU26299RMFS0")
+		p    = &Position{
+			Instrument:  ofz,
+			Type:        Long,
+			Status:      Closed,
+			OpenPrice:   decimal.NewFromInt(95),
+			OpenDate:    time.Date(2024, 4, 15, 0, 0, 0, 0, timeext.Moscow),
+			ClosedPrice: decimal.NewFromInt(96),
+			Deadline:    time.Date(2024, 6, 15, 0, 0, 0, 0, timeext.Moscow),
+		}
+	)
+	if len(ofz.Coupons) != 6 || !ofz.Coupons[5].Date.Equal(ofz.Maturity) {
+		t.Fatalf("got coupons %v, want 6 of them up to maturity", ofz.Coupons)
+	}
+
+	wp, err := p.WithProfit(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bought at 950 + 33.41 accrued, sold at 960 + 6.74 accrued with a coupon of 40 in between
+	if !wp.Income.Equal(decimal.NewFromInt(40)) || !wp.ProfitP.Round(2).Equal(decimal.RequireFromString("2.37")) {
+		t.Errorf("got income %s and profit %s%%, want 40 and 2.37%%", wp.Income, wp.ProfitP)
+	}
+}
+
+func TestDividendsInTotalReturn(t *testing.T) {
+	s, _ := fake.NewTest(t, time.Date(2024, 5, 15, 0, 0, 0, 0, timeext.Moscow))
+	gazp := find(t, s, "GAZP")
+
+	// 130 -> 133.5 across a dividend of 6.5
+	cases := []struct {
+		typ                 Type
+		priceReturn, profit string
+	}{
+		{Long, "2.69", "7.69"},
+		{Short, "-2.69", "-7.69"},
+	}
+	for _, c := range cases {
+		p := &Position{
+			Instrument:  gazp,
+			Type:        c.typ,
+			Status:      Closed,
+			OpenPrice:   decimal.NewFromInt(130),
+			OpenDate:    time.Date(2024, 5, 13, 10, 0, 0, 0, timeext.Moscow),
+			ClosedPrice: decimal.NewFromFloat(133.5),
+			Deadline:    time.Date(2024, 5, 14, 12, 0, 0, 0, timeext.Moscow),
+		}
+
+		wp, err := p.WithProfit(context.Background(), s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !wp.PriceReturnP.Round(2).Equal(decimal.RequireFromString(c.priceReturn)) || !wp.ProfitP.Round(2).Equal(decimal.RequireFromString(c.profit)) {
+			t.Errorf("%s: got price return %s%% and total %s%%, want %s%% and %s%%", c.typ, wp.PriceReturnP, wp.ProfitP, c.priceReturn, c.profit)
+		}
+	}
+}
+
+func TestFutureReturn(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		s, _ = fake.NewTest(t, time.Date(2024, 5, 15, 12, 0, 0, 0, timeext.Moscow))
+		fut  = &Position{
+			Instrument: find(t, s, "GZM4"),
+			Type:       Long,
+			Status:     Active,
+			OpenPrice:  decimal.NewFromInt(13000),
+			OpenDate:   time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow),
+			Deadline:   time.Date(2024, 6, 21, 18, 50, 0, 0, timeext.Moscow),
+		}
+	)
+
+	wp, err := fut.WithProfit(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 500 points made on a margin of 2600
+	if !wp.PnL.Equal(decimal.NewFromInt(500)) || !wp.ProfitP.Round(2).Equal(decimal.RequireFromString("19.23")) {
+		t.Errorf("got P&L %s and profit %s%%, want 500 and 19.23%%", wp.PnL, wp.ProfitP)
+	}
+}
+
+type saves int
+
+func (s *saves) Save(ctx context.Context, p *Position) error {
+	*s++
+	return nil
+}
+
+func TestNewRejectsEveryBadField(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		m, clock = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		s        saves
+	)
+
+	cases := []struct {
+		name string
+		opt  CreationOptions
+		want []error
+	}{
+		// MGNT is at 8800
+		{"yield with staged targets", CreationOptions{Ticker: "MGNT", Type: Long, Targets: "9000 50%, 9500", TargetYield: "12", Deadline: "1.06.2024"}, []error{ErrTargetYield}},
+		{"target behind the price and a bad deadline", CreationOptions{Ticker: "MGNT", Type: Long, TargetPrice: "8000", Deadline: "soon"}, []error{ErrTargetPrice, ErrParseDeadline}},
+	}
+	for _, c := range cases {
+		_, err := New(ctx, clock.Now(), m, &s, c.opt)
+		for _, want := range c.want {
+			if !errors.Is(err, want) {
+				t.Errorf("%s: got %v, want %v", c.name, err, want)
+			}
+		}
+	}
+	if s != 0 {
+		t.Errorf("got %d positions saved, want none", s)
+	}
+}
+
+func TestReopenForgetsDraft(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		s, clock = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		open     = time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow)
+		p        = &Position{
+			Instrument:  find(t, s, "MGNT"),
+			Type:        Long,
+			Status:      Active,
+			OpenPrice:   decimal.NewFromInt(8000),
+			OpenDate:    open,
+			Deadline:    time.Date(2024, 6, 1, 18, 50, 0, 0, timeext.Moscow),
+			TargetPrice: decimal.NewFromInt(9000),
+			Targets: []Target{
+				{Price: decimal.NewFromInt(8400), Fraction: decimal.NewFromFloat(0.5), HitAt: open.Add(time.Hour)},
+				{Price: decimal.NewFromInt(9000), Fraction: decimal.NewFromFloat(0.5)},
+			},
+			History: []Change{{Kind: OpenedChange, At: open, Price: decimal.NewFromInt(8000)}},
+		}
+		u updates
+	)
+
+	// the first target is behind the price of 8800 now
+	if err := p.Reopen(ctx, clock.Now(), s, &u); !errors.Is(err, ErrTargetPrice) || u != 0 || !p.OpenPrice.Equal(decimal.NewFromInt(8000)) {
+		t.Fatalf("got %v with %d updates, open price %s, want the target rejected and nothing changed", err, u, p.OpenPrice)
+	}
+
+	p.Targets = p.Targets[1:]
+	if err := p.Reopen(ctx, clock.Now(), s, &u); err != nil {
+		t.Fatal(err)
+	}
+	if !p.OpenPrice.Equal(decimal.NewFromInt(8800)) || !p.OpenDate.Equal(clock.Now()) || len(p.Changes()) != 1 || len(p.Fills) != 1 {
+		t.Errorf("got open at %s on %v, %d changes and %d fills, want a fresh open at 8800", p.OpenPrice, p.OpenDate, len(p.Changes()), len(p.Fills))
+	}
+}
@@ -0,0 +1,319 @@
+package position
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+// SpreadKind is what a spread position's price is: the ratio of its bought legs
+// to its sold legs, or the weighted difference between them.
+type SpreadKind string
+
+const (
+	Ratio      SpreadKind = "ratio"
+	Difference SpreadKind = "difference"
+)
+
+// Leg is an instrument of a spread. Weight is the units held per unit of the
+// spread, negative for the sold legs.
+type Leg struct {
+	Instrument  *instrument.Instrument `bson:"instrument"`
+	Weight      decimal.Decimal        `bson:"weight"`
+	OpenPrice   decimal.Decimal        `bson:"open_price"`
+	ClosedPrice decimal.Decimal        `bson:"closed_price"`
+}
+
+func (p *Position) IsSpread() bool {
+	return len(p.Legs) > 0
+}
+
+// Instruments are the instruments the position is priced from: the legs of a
+// spread, or the one instrument.
+func (p *Position) Instruments() []*instrument.Instrument {
+	if !p.IsSpread() {
+		return []*instrument.Instrument{p.Instrument}
+	}
+
+	ii := make([]*instrument.Instrument, len(p.Legs))
+	for n, l := range p.Legs {
+		ii[n] = l.Instrument
+	}
+	return ii
+}
+
+// allowsNegativeTarget tells whether the position's price can go below zero.
+func (p *Position) allowsNegativeTarget() bool {
+	return p.IsSpread() && p.Spread == Difference
+}
+
+// SpreadValue prices a spread from the prices of its legs, in the order of the legs.
+func SpreadValue(kind SpreadKind, legs []Leg, prices []decimal.Decimal) (decimal.Decimal, error) {
+	var bought, sold decimal.Decimal
+	for n, l := range legs {
+		v := l.Weight.Mul(prices[n])
+		if l.Weight.IsPositive() {
+			bought = bought.Add(v)
+		} else {
+			sold = sold.Sub(v)
+		}
+	}
+
+	if kind == Difference {
+		return bought.Sub(sold), nil
+	}
+	if !sold.IsPositive() {
+		return decimal.Zero, ErrSpreadValue
+	}
+	return bought.Div(sold), nil
+}
+
+type legOption struct {
+	ticker string
+	weight decimal.Decimal
+}
+
+// parseLegs reads legs like "SBER 1, VTBR -50". A leg without a weight is
+// bought one to one.
+func parseLegs(s string) ([]legOption, error) {
+	var (
+		ll   []legOption
+		seen = make(map[string]bool)
+	)
+	for _, raw := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		ff := strings.Fields(strings.ReplaceAll(raw, ":", " "))
+		if len(ff) == 0 {
+			continue
+		}
+		if len(ff) > 2 {
+			return nil, fmt.Errorf("%w: %q", ErrLegs, raw)
+		}
+
+		l := legOption{ticker: strings.ToUpper(ff[0]), weight: decimal.NewFromInt(1)}
+		if len(ff) == 2 {
+			w, err := decimal.NewFromString(ff[1])
+			if err != nil || w.IsZero() {
+				return nil, errors.Join(err, fmt.Errorf("%w: %q", ErrLegs, raw))
+			}
+			l.weight = w
+		}
+		if seen[l.ticker] {
+			return nil, fmt.Errorf("%w: %s twice", ErrLegs, l.ticker)
+		}
+		seen[l.ticker] = true
+
+		ll = append(ll, l)
+	}
+
+	if len(ll) < 2 {
+		return nil, ErrLegs
+	}
+	return ll, nil
+}
+
+// legPrices asks for the prices of all legs at once.
+func legPrices(ctx context.Context, pp priceProvider, legs []Leg) ([]decimal.Decimal, error) {
+	var (
+		wg     sync.WaitGroup
+		prices = make([]decimal.Decimal, len(legs))
+		errs   = make([]error, len(legs))
+	)
+	for n, l := range legs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prices[n], errs[n] = pp.Price(ctx, l.Instrument)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("couldn't get leg prices: %w", err)
+	}
+	return prices, nil
+}
+
+// spreadInstrument stands for the whole spread wherever a position needs an instrument.
+func spreadInstrument(kind SpreadKind, legs []Leg) *instrument.Instrument {
+	var tickers, names []string
+	for _, l := range legs {
+		tickers = append(tickers, l.Instrument.Ticker)
+		names = append(names, l.Instrument.Name)
+	}
+
+	i := &instrument.Instrument{
+		Ticker: strings.Join(tickers, "/"),
+		Name:   "Спред " + strings.Join(names, " / "),
+		Type:   instrument.Spread,
+		Tick:   decimal.New(1, -4),
+	}
+	if kind == Difference {
+		// a difference is in money, a ratio has no unit
+		i.Currency = legs[0].Instrument.CurrencyCode()
+		i.Tick = decimal.New(1, -2)
+	}
+	return i
+}
+
+func buildSpread(ctx context.Context, now time.Time, mp marketProvider, opt CreationOptions) (*Position, error) {
+	var parseError error
+
+	kind := opt.Spread
+	if kind == "" {
+		kind = Ratio
+	}
+	if kind != Ratio && kind != Difference {
+		parseError = errors.Join(parseError, ErrSpreadKind)
+	}
+
+	lo, err := parseLegs(opt.Legs)
+	if err != nil {
+		parseError = errors.Join(parseError, err)
+	}
+
+	var legs []Leg
+	var bought, sold bool
+	for _, l := range lo {
+		i, err := mp.Find(ctx, l.ticker)
+		if errors.Is(err, instrument.ErrNotFound) {
+			parseError = errors.Join(parseError, err, fmt.Errorf("%w: %s", ErrLegs, l.ticker))
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("couldn't get instrument: %w", err)
+		} else if i.IsDerivative() {
+			// a leg expiring would leave the spread without a price
+			parseError = errors.Join(parseError, fmt.Errorf("%w: %s is a derivative", ErrLegs, l.ticker))
+			continue
+		}
+		if len(legs) > 0 && i.CurrencyCode() != legs[0].Instrument.CurrencyCode() {
+			// a difference of prices in different currencies means nothing
+			parseError = errors.Join(parseError, fmt.Errorf("%w: %s is traded in %s, not %s", ErrLegs, l.ticker, i.CurrencyCode(), legs[0].Instrument.CurrencyCode()))
+			continue
+		}
+
+		legs = append(legs, Leg{Instrument: i, Weight: l.weight})
+		bought, sold = bought || l.weight.IsPositive(), sold || l.weight.IsNegative()
+	}
+	if kind == Ratio && len(legs) == len(lo) && !(bought && sold) {
+		parseError = errors.Join(parseError, fmt.Errorf("%w: a ratio needs bought and sold legs", ErrLegs))
+	}
+
+	if opt.Type != Long && opt.Type != Short {
+		parseError = errors.Join(parseError, ErrParseType)
+	}
+
+	publishAt, err := parsePublishAt(opt.PublishAt, now)
+	if err != nil {
+		parseError = errors.Join(parseError, err)
+	}
+
+	deadline, err := parseDeadline(opt.Deadline)
+	if err != nil {
+		parseError = errors.Join(parseError, err, ErrParseDeadline)
+	} else if deadline.Before(now) || !publishAt.Before(deadline) && !publishAt.IsZero() {
+		parseError = errors.Join(parseError, ErrParseDeadline)
+	}
+
+	tp, err := decimal.NewFromString(opt.TargetPrice)
+	if err != nil || kind == Ratio && !tp.IsPositive() {
+		parseError = errors.Join(parseError, err, ErrTargetPrice)
+	}
+
+	if parseError != nil {
+		return nil, parseError
+	}
+
+	prices, err := legPrices(ctx, mp, legs)
+	if err != nil {
+		return nil, err
+	}
+	for n := range legs {
+		legs[n].OpenPrice = prices[n]
+	}
+	value, err := SpreadValue(kind, legs, prices)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't price spread: %w", err)
+	}
+
+	if opt.Type == Long && tp.LessThan(value) || opt.Type == Short && value.LessThan(tp) {
+		return nil, ErrTargetPrice
+	}
+
+	pos := &Position{
+		ID:          rand.Int(),
+		Instrument:  spreadInstrument(kind, legs),
+		Legs:        legs,
+		Spread:      kind,
+		Type:        opt.Type,
+		Status:      opening(publishAt, opt.Draft),
+		OpenPrice:   value,
+		TargetPrice: tp,
+		Deadline:    deadline,
+		OpenDate:    now,
+		PublishAt:   publishAt,
+		History: []Change{{
+			Kind:        OpenedChange,
+			At:          now,
+			Price:       value,
+			TargetPrice: tp,
+			Deadline:    deadline,
+		}},
+	}
+
+	return pos, nil
+}
+
+// spreadWithProfit is the P&L of holding the legs in their weights: ProfitP is
+// relative to the gross amount put into the legs at the open.
+func (p *Position) spreadWithProfit(ctx context.Context, qp quoteProvider, mul decimal.Decimal) (WithProfit, error) {
+	prices, err := legPrices(ctx, qp, p.Legs)
+	if err != nil {
+		return WithProfit{}, err
+	}
+	value, err := SpreadValue(p.Spread, p.Legs, prices)
+	if err != nil {
+		return WithProfit{}, fmt.Errorf("couldn't price spread: %w", err)
+	}
+
+	end, closes := timeext.Now(), prices
+	if p.Status == Closed {
+		end, closes = p.Deadline, make([]decimal.Decimal, len(p.Legs))
+		for n, l := range p.Legs {
+			closes[n] = l.ClosedPrice
+		}
+	}
+
+	var gross, priceChange, income decimal.Decimal
+	for n, l := range p.Legs {
+		inc := l.Instrument.CouponIncome(p.OpenDate, end)
+		if l.Instrument.PaysDividends() {
+			dd, err := qp.Dividends(ctx, l.Instrument, p.OpenDate, end)
+			if err != nil {
+				return WithProfit{}, fmt.Errorf("couldn't get dividends of %s: %w", l.Instrument.Ticker, err)
+			}
+			inc = inc.Add(instrument.DividendIncome(dd, p.OpenDate, end))
+		}
+
+		gross = gross.Add(l.Weight.Abs().Mul(l.OpenPrice))
+		priceChange = priceChange.Add(l.Weight.Mul(closes[n].Sub(l.OpenPrice)))
+		income = income.Add(l.Weight.Mul(inc))
+	}
+
+	hundred := decimal.NewFromInt(100)
+	return WithProfit{
+		Position:     p,
+		Instrument:   &instrument.WithPrice{Instrument: p.Instrument, Price: value},
+		ProfitP:      priceChange.Add(income).Mul(mul).Div(gross).Mul(hundred),
+		PriceReturnP: priceChange.Mul(mul).Div(gross).Mul(hundred),
+		Income:       income,
+		LegPrices:    prices,
+	}, nil
+}
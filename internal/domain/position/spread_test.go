@@ -0,0 +1,72 @@
+package position
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+func TestSpreadReturn(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		s, _ = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		mgnt = find(t, s, "MGNT")
+	)
+	imoex, err := s.FindIndex(ctx, "IMOEX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// long one MGNT against two index units: 8000 -> 8800 and 3400 -> 3570
+	p := &Position{
+		Instrument: &instrument.Instrument{Ticker: "MGNT/IMOEX", Type: instrument.Spread},
+		Legs: []Leg{
+			{Instrument: mgnt, Weight: decimal.NewFromInt(1), OpenPrice: decimal.NewFromInt(8000)},
+			{Instrument: imoex, Weight: decimal.NewFromInt(-2), OpenPrice: decimal.NewFromInt(3400)},
+		},
+		Spread:    Ratio,
+		Type:      Long,
+		Status:    Active,
+		OpenDate:  time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow),
+		Deadline:  time.Date(2024, 6, 1, 18, 50, 0, 0, timeext.Moscow),
+		OpenPrice: decimal.NewFromInt(8000).Div(decimal.NewFromInt(6800)),
+	}
+
+	wp, err := p.WithProfit(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 800 - 2*170 made on 8000 + 2*3400 put in
+	if !wp.ProfitP.Round(2).Equal(decimal.RequireFromString("3.11")) {
+		t.Errorf("got profit %s%%, want 3.11%%", wp.ProfitP)
+	}
+	if want := decimal.NewFromInt(8800).Div(decimal.NewFromInt(7140)); !wp.Instrument.Price.Equal(want) {
+		t.Errorf("got spread %s, want %s", wp.Instrument.Price, want)
+	}
+}
+
+func TestSpreadLegsInOneCurrency(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		now  = time.Date(2024, 5, 15, 12, 0, 0, 0, timeext.Moscow)
+		s, _ = fake.NewTest(t, now)
+		opt  = CreationOptions{Type: Long, TargetPrice: "70", Deadline: "1.06.2024", Spread: Ratio}
+	)
+
+	opt.Legs = "MGNT 1, GAZP -1"
+	if _, err := build(ctx, now, s, opt); err != nil {
+		t.Fatalf("couldn't build a rouble spread: %v", err)
+	}
+
+	// Alibaba is quoted in Hong Kong dollars
+	opt.Legs = "MGNT 1, 9988 -1"
+	if _, err := build(ctx, now, s, opt); !errors.Is(err, ErrLegs) {
+		t.Errorf("got %v, want ErrLegs for legs in roubles and dollars", err)
+	}
+}
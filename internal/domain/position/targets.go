@@ -0,0 +1,279 @@
+package position
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/assert"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+// Target is a staged take-profit. Fraction is the part of the opening size to
+// exit when the price reaches the target, zero to only track the hit.
+type Target struct {
+	Price    decimal.Decimal `bson:"price"`
+	Fraction decimal.Decimal `bson:"fraction"`
+	HitAt    time.Time       `bson:"hit_at"`
+}
+
+func (t Target) IsHit() bool {
+	return !t.HitAt.IsZero()
+}
+
+// StagedTargets returns the targets in the analyst's order. A position given a
+// single target price has just that one.
+func (p *Position) StagedTargets() []Target {
+	if len(p.Targets) > 0 {
+		return p.Targets
+	}
+	return []Target{{Price: p.TargetPrice}}
+}
+
+// parseTargets reads targets like "300 50%, 320 30%, 350". A target without a
+// percent only tracks the hit.
+func parseTargets(s string) ([]Target, error) {
+	var tt []Target
+	for _, raw := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		ff := strings.Fields(raw)
+		if len(ff) == 0 {
+			continue
+		}
+		if len(ff) > 2 {
+			return nil, fmt.Errorf("%w: %q", ErrTargetPrice, raw)
+		}
+
+		price, err := decimal.NewFromString(ff[0])
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("%w: %q", ErrTargetPrice, raw))
+		}
+		t := Target{Price: price}
+		if len(ff) == 2 {
+			if t.Fraction, err = parseFraction(ff[1]); err != nil {
+				return nil, errors.Join(err, fmt.Errorf("%w: %q", ErrFraction, raw))
+			}
+		}
+		tt = append(tt, t)
+	}
+
+	if len(tt) == 0 {
+		return nil, ErrTargetPrice
+	}
+	return tt, nil
+}
+
+// FormatTargets writes targets the way parseTargets reads them.
+func FormatTargets(tt []Target) string {
+	ss := make([]string, len(tt))
+	for n, t := range tt {
+		ss[n] = t.Price.String()
+		if t.Fraction.IsPositive() {
+			ss[n] += " " + t.Fraction.Mul(decimal.NewFromInt(100)).String() + "%"
+		}
+	}
+	return strings.Join(ss, ", ")
+}
+
+// checkTargets validates targets set at the price: they are all on the profitable
+// side of it, and their exits fit in the size that's left.
+func (p *Position) checkTargets(tt []Target, price, size decimal.Decimal) error {
+	var exits decimal.Decimal
+	for _, t := range tt {
+		if !t.Price.IsPositive() && !p.allowsNegativeTarget() {
+			return ErrTargetPrice
+		}
+		if p.Type == Long && t.Price.LessThan(price) || p.Type == Short && price.LessThan(t.Price) {
+			return ErrTargetPrice
+		}
+		exits = exits.Add(t.Fraction)
+	}
+
+	if exits.IsPositive() && p.IsSpread() {
+		return ErrScaleSpread
+	}
+	if exits.GreaterThan(size) {
+		return ErrFraction
+	}
+	return nil
+}
+
+// ChangeTargets replaces the targets that haven't been hit. The last target is
+// the position's target price.
+func (p *Position) ChangeTargets(ctx context.Context, pu positionUpdater, tt []Target) error {
+	if p.Status == Closed {
+		return ErrClosedPositionModified
+	}
+
+	assert.That(len(tt) > 0, "no targets to change to")
+
+	var hit []Target
+	for _, t := range p.Targets {
+		if t.IsHit() {
+			hit = append(hit, t)
+		}
+	}
+
+	old, oldt, oldy, oldh := p.TargetPrice, p.Targets, p.TargetYield, p.History
+	p.Targets = append(hit, tt...)
+	p.TargetPrice = tt[len(tt)-1].Price
+	p.TargetYield = decimal.Zero
+	p.History = append(p.History, Change{
+		Kind:        TargetChange,
+		At:          timeext.Now(),
+		TargetPrice: p.TargetPrice,
+		Deadline:    p.Deadline,
+	})
+
+	err := pu.Update(ctx, p)
+	if err == nil {
+		return nil
+	}
+
+	p.TargetPrice, p.Targets, p.TargetYield, p.History = old, oldt, oldy, oldh
+	return fmt.Errorf("couldn't save position: %w", err)
+}
+
+// targetsSince is when the current targets were set.
+func (p *Position) targetsSince() time.Time {
+	cc := p.Changes()
+	for n := len(cc) - 1; n >= 0; n-- {
+		if cc[n].Kind == OpenedChange || cc[n].Kind == TargetChange {
+			return cc[n].At
+		}
+	}
+	return p.OpenDate
+}
+
+// Candles are the position's candles from one moment to another: a spread's are
+// combined from the candles of its legs.
+func (p *Position) Candles(ctx context.Context, cp candleProvider, from, to time.Time, interval chart.Interval) ([]chart.Candle, error) {
+	ii := p.Instruments()
+	series := make([][]chart.Candle, len(ii))
+	for n, i := range ii {
+		wi, err := i.WithInterval(ctx, from, to, interval)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get position lifespan: %w", err)
+		}
+
+		series[n], err = cp.GetCandles(ctx, &wi)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get candles of %s (interval %s): %w", i.Ticker, interval, err)
+		}
+	}
+
+	if !p.IsSpread() {
+		return series[0], nil
+	}
+	return chart.Spread(series, func(prices []float64) (float64, bool) {
+		dd := make([]decimal.Decimal, len(prices))
+		for n, pr := range prices {
+			dd[n] = decimal.NewFromFloat(pr)
+		}
+		v, err := SpreadValue(p.Spread, p.Legs, dd)
+		return v.InexactFloat64(), err == nil
+	}), nil
+}
+
+type targetHit struct {
+	n  int
+	at time.Time
+}
+
+// reachedAt finds the first candle ending after since that touches the price.
+// The candle since falls in counts from since on, the price may have got there
+// in its part after since.
+func (p *Position) reachedAt(cc []chart.Candle, interval chart.Interval, price decimal.Decimal, since time.Time) (time.Time, bool) {
+	pr := price.InexactFloat64()
+	for _, c := range cc {
+		at := time.Unix(c.Time, 0).In(timeext.Moscow)
+		if !at.Add(interval.Duration()).After(since) {
+			continue
+		}
+		if at.Before(since) {
+			at = since
+		}
+		if p.Type == Long && c.High >= pr || p.Type == Short && c.Low <= pr {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// TrackTargets marks the targets the price has reached since they were set, in
+// the order they were reached, and exits their fractions at the target prices.
+// It returns how many targets were hit.
+func (p *Position) TrackTargets(ctx context.Context, cp candleProvider, pu positionUpdater) (int, error) {
+	if p.Status == Closed {
+		return 0, ErrClosedPositionModified
+	}
+
+	since := p.targetsSince()
+	now := timeext.Now()
+	if !since.Before(now) {
+		return 0, nil
+	}
+	cc, err := p.Candles(ctx, cp, since, now, chart.Hour)
+	if err != nil {
+		return 0, err
+	}
+
+	var hits []targetHit
+	for n, t := range p.StagedTargets() {
+		if t.IsHit() {
+			continue
+		}
+		if at, ok := p.reachedAt(cc, chart.Hour, t.Price, since); ok {
+			hits = append(hits, targetHit{n, at})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].at.Before(hits[j].at) })
+
+	for k, h := range hits {
+		if err := p.hitTarget(ctx, pu, h); err != nil {
+			return k, fmt.Errorf("couldn't hit target %d: %w", h.n+1, err)
+		}
+		if p.Status == Closed {
+			return k + 1, nil
+		}
+	}
+	return len(hits), nil
+}
+
+func (p *Position) hitTarget(ctx context.Context, pu positionUpdater, h targetHit) error {
+	oldt, oldh := p.Targets, p.History
+	p.Targets = slices.Clone(p.StagedTargets())
+	t := &p.Targets[h.n]
+	t.HitAt = h.at
+	p.History = append(p.History, Change{
+		Kind:        TargetHitChange,
+		At:          h.at,
+		Price:       t.Price,
+		Fraction:    t.Fraction,
+		TargetPrice: p.TargetPrice,
+		Deadline:    p.Deadline,
+	})
+
+	var err error
+	wp := WithProfit{
+		Position:   p,
+		Instrument: &instrument.WithPrice{Instrument: p.Instrument, Price: t.Price},
+	}
+	if size := wp.replay(nil).size; t.Fraction.IsPositive() && size.IsPositive() {
+		err = wp.reduceAt(ctx, pu, h.at, t.Price, decimal.Min(t.Fraction, size))
+	} else {
+		err = pu.Update(ctx, p)
+	}
+	if err == nil {
+		return nil
+	}
+
+	p.Targets, p.History = oldt, oldh
+	return err
+}
@@ -0,0 +1,89 @@
+package position
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type updates int
+
+func (u *updates) Update(ctx context.Context, p *Position) error {
+	*u++
+	return nil
+}
+
+func TestStagedTargets(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		s, _ = fake.NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+		open = time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow)
+		p    = &Position{
+			Instrument:  find(t, s, "MGNT"),
+			Type:        Long,
+			Status:      Active,
+			OpenPrice:   decimal.NewFromInt(8000),
+			OpenDate:    open,
+			Deadline:    time.Date(2024, 6, 1, 18, 50, 0, 0, timeext.Moscow),
+			TargetPrice: decimal.NewFromInt(9000),
+			// half out at 8400, the next one only tracked, the last never reached
+			Targets: []Target{
+				{Price: decimal.NewFromInt(8400), Fraction: decimal.NewFromFloat(0.5)},
+				{Price: decimal.NewFromInt(8600)},
+				{Price: decimal.NewFromInt(9000), Fraction: decimal.NewFromFloat(0.5)},
+			},
+			History: []Change{{Kind: OpenedChange, At: open, Price: decimal.NewFromInt(8000)}},
+		}
+		u updates
+	)
+
+	hits, err := p.TrackTargets(ctx, s, &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hits != 2 || u != 2 || !p.Targets[0].IsHit() || !p.Targets[1].IsHit() || p.Targets[2].IsHit() {
+		t.Fatalf("got %d hits and %d updates, targets %v, want the first two hit", hits, u, p.Targets)
+	}
+	if p.Targets[1].HitAt.Before(p.Targets[0].HitAt) || p.Status != Active {
+		t.Errorf("want the targets hit in order and the position still open, got %v", p.Targets)
+	}
+
+	wp, err := p.WithProfit(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wp.Size.Equal(decimal.NewFromFloat(0.5)) || !wp.Realized.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("got size %s and realized %s, want 0.5 and 200", wp.Size, wp.Realized)
+	}
+
+	// reached targets stay reached
+	if hits, _ := p.TrackTargets(ctx, s, &u); hits != 0 {
+		t.Errorf("got %d more hits, want none", hits)
+	}
+}
+
+func TestReachedInOpeningCandle(t *testing.T) {
+	var (
+		p     = &Position{Type: Long}
+		at    = func(h, m int) time.Time { return time.Date(2024, 5, 13, h, m, 0, 0, timeext.Moscow) }
+		since = at(10, 30)
+		cc    = []chart.Candle{
+			{Time: at(9, 0).Unix(), High: 8500},
+			{Time: at(10, 0).Unix(), High: 8450},
+			{Time: at(11, 0).Unix(), High: 8600},
+		}
+	)
+
+	// the candle since falls in counts from since on, the one before doesn't
+	if got, ok := p.reachedAt(cc, chart.Hour, decimal.NewFromInt(8400), since); !ok || !got.Equal(since) {
+		t.Errorf("got %v, %t; want %v", got, ok, since)
+	}
+	if got, ok := p.reachedAt(cc, chart.Hour, decimal.NewFromInt(8550), since); !ok || !got.Equal(at(11, 0)) {
+		t.Errorf("got %v, %t; want %v", got, ok, at(11, 0))
+	}
+}
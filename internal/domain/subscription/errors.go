@@ -0,0 +1,12 @@
+package subscription
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("subscription not found")
+	ErrConflict = errors.New("already subscribed")
+
+	ErrChannel = errors.New("unknown notification channel")
+	ErrAddress = errors.New("wrong address for the notification channel")
+	ErrTarget  = errors.New("subscription must target an analyst or an idea")
+)
@@ -0,0 +1,115 @@
+package subscription
+
+import (
+	"changemedaddy/internal/pkg/netguard"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Channel string
+
+const (
+	Email    Channel = "email"
+	Telegram Channel = "telegram"
+	Webhook  Channel = "webhook"
+)
+
+// Subscription is a reader following an analyst or, if IdeaSlug is set, a single idea.
+type Subscription struct {
+	// ID is random and doubles as the unsubscribe token.
+	ID          string    `bson:"id"`
+	Channel     Channel   `bson:"channel"`
+	Address     string    `bson:"address"`
+	AnalystSlug string    `bson:"analyst_slug"`
+	IdeaSlug    string    `bson:"idea_slug"`
+	CreatedAt   time.Time `bson:"created_at"`
+	// Unconfirmed subscriptions get nothing until the owner of the address follows
+	// the link sent there, so nobody can subscribe someone else's inbox.
+	Unconfirmed bool `bson:"unconfirmed,omitempty"`
+}
+
+type subscriptionSaver interface {
+	Save(ctx context.Context, s *Subscription) error
+}
+
+type CreationOptions struct {
+	Channel     Channel `form:"channel"`
+	Address     string  `form:"address"`
+	AnalystSlug string
+	IdeaSlug    string
+}
+
+var telegramChat = regexp.MustCompile(`^(-?\d+|@[A-Za-z0-9_]{5,})$`)
+
+func validAddress(ch Channel, addr string) bool {
+	switch ch {
+	case Email:
+		a, err := mail.ParseAddress(addr)
+		return err == nil && a.Address == addr
+	case Telegram:
+		return telegramChat.MatchString(addr)
+	case Webhook:
+		// anyone can subscribe a webhook, so it must not point into our network;
+		// names are checked again when the channel connects, they may resolve anywhere
+		u, err := url.Parse(addr)
+		if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+			return false
+		}
+		if ip, err := netip.ParseAddr(u.Hostname()); err == nil {
+			return netguard.IsPublic(ip)
+		}
+		host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+		return host != "localhost" && !strings.HasSuffix(host, ".localhost") && strings.Contains(host, ".")
+	}
+	return false
+}
+
+func New(ctx context.Context, ss subscriptionSaver, opt CreationOptions) (*Subscription, error) {
+	var parseError error
+
+	opt.Address = strings.TrimSpace(opt.Address)
+
+	if opt.Channel != Email && opt.Channel != Telegram && opt.Channel != Webhook {
+		parseError = errors.Join(parseError, ErrChannel)
+	} else if !validAddress(opt.Channel, opt.Address) {
+		parseError = errors.Join(parseError, ErrAddress)
+	}
+
+	if opt.AnalystSlug == "" {
+		parseError = errors.Join(parseError, ErrTarget)
+	}
+
+	if parseError != nil {
+		return nil, parseError
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("couldn't generate subscription id: %w", err)
+	}
+
+	s := &Subscription{
+		ID:          hex.EncodeToString(id),
+		Channel:     opt.Channel,
+		Address:     opt.Address,
+		AnalystSlug: opt.AnalystSlug,
+		IdeaSlug:    opt.IdeaSlug,
+		CreatedAt:   time.Now(),
+		Unconfirmed: opt.Channel == Email,
+	}
+
+	if err := ss.Save(ctx, s); err != nil {
+		return nil, fmt.Errorf("couldn't save subscription: %w", err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,193 @@
+package visit
+
+import (
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DayFormat = time.DateOnly
+
+// View is a single page view. It never holds the raw IP: VisitorID is a hash of the
+// IP and user agent with a salt that rotates daily, so visitors can be counted
+// within a day but can't be traced across days or back to an address.
+type View struct {
+	AnalystSlug string
+	IdeaSlug    string
+	PositionID  int
+	VisitorID   string
+	Referrer    string
+	Day         string
+}
+
+type saltProvider interface {
+	// Salt returns the salt for the given day, creating it if needed.
+	Salt(ctx context.Context, day string) ([]byte, error)
+}
+
+type ViewOptions struct {
+	AnalystSlug string
+	IdeaSlug    string
+	PositionID  int
+
+	IP        string
+	UserAgent string
+	Referrer  string
+	// Host is our own host, referrals from it are counted as internal.
+	Host string
+	At   time.Time
+}
+
+func NewView(ctx context.Context, sp saltProvider, opt ViewOptions) (View, error) {
+	// days are Moscow days whatever zone the host is in
+	day := opt.At.In(timeext.Moscow).Format(DayFormat)
+
+	salt, err := sp.Salt(ctx, day)
+	if err != nil {
+		return View{}, fmt.Errorf("couldn't get salt for %s: %w", day, err)
+	}
+
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(opt.IP))
+	h.Write([]byte{0})
+	h.Write([]byte(opt.UserAgent))
+
+	return View{
+		AnalystSlug: opt.AnalystSlug,
+		IdeaSlug:    opt.IdeaSlug,
+		PositionID:  opt.PositionID,
+		VisitorID:   hex.EncodeToString(h.Sum(nil)[:16]),
+		Referrer:    referrerHost(opt.Referrer, opt.Host),
+		Day:         day,
+	}, nil
+}
+
+const (
+	Direct   = "direct"
+	Internal = "internal"
+)
+
+func referrerHost(ref, self string) string {
+	if ref == "" {
+		return Direct
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil || u.Hostname() == "" {
+		return Direct
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if self != "" && host == strings.TrimPrefix(strings.ToLower(self), "www.") {
+		return Internal
+	}
+
+	return host
+}
+
+// Counts are views and unique visitors. Visitors are unique per page and day, so
+// they add up to an upper bound when summed across pages or days.
+type Counts struct {
+	Views    int
+	Visitors int
+}
+
+func (c Counts) Add(o Counts) Counts {
+	return Counts{Views: c.Views + o.Views, Visitors: c.Visitors + o.Visitors}
+}
+
+// Rollup is the daily aggregate for one page.
+type Rollup struct {
+	Day         string
+	AnalystSlug string
+	IdeaSlug    string
+	PositionID  int
+	Counts
+}
+
+// ReferrerRollup is the daily number of views that came from one host.
+type ReferrerRollup struct {
+	Day         string
+	AnalystSlug string
+	Host        string
+	Views       int
+}
+
+type DayStats struct {
+	Day string
+	Counts
+}
+
+type IdeaStats struct {
+	IdeaSlug string
+	Counts
+}
+
+type ReferrerStats struct {
+	Host  string
+	Views int
+}
+
+type Stats struct {
+	AnalystSlug string
+	Total       Counts
+	Days        []DayStats
+	Ideas       []IdeaStats
+	Referrers   []ReferrerStats
+}
+
+// Summarize folds daily rollups of one analyst into Stats. Days are sorted
+// chronologically, ideas and referrers by views descending.
+func Summarize(analystSlug string, rr []Rollup, refs []ReferrerRollup) Stats {
+	var (
+		s     = Stats{AnalystSlug: analystSlug}
+		days  = make(map[string]Counts)
+		ideas = make(map[string]Counts)
+		hosts = make(map[string]int)
+	)
+
+	for _, r := range rr {
+		s.Total = s.Total.Add(r.Counts)
+		days[r.Day] = days[r.Day].Add(r.Counts)
+		if r.IdeaSlug != "" {
+			ideas[r.IdeaSlug] = ideas[r.IdeaSlug].Add(r.Counts)
+		}
+	}
+	for _, r := range refs {
+		hosts[r.Host] += r.Views
+	}
+
+	for d, c := range days {
+		s.Days = append(s.Days, DayStats{Day: d, Counts: c})
+	}
+	sort.Slice(s.Days, func(i, j int) bool { return s.Days[i].Day < s.Days[j].Day })
+
+	for i, c := range ideas {
+		s.Ideas = append(s.Ideas, IdeaStats{IdeaSlug: i, Counts: c})
+	}
+	sort.Slice(s.Ideas, func(i, j int) bool {
+		if s.Ideas[i].Views != s.Ideas[j].Views {
+			return s.Ideas[i].Views > s.Ideas[j].Views
+		}
+		return s.Ideas[i].IdeaSlug < s.Ideas[j].IdeaSlug
+	})
+
+	for h, v := range hosts {
+		s.Referrers = append(s.Referrers, ReferrerStats{Host: h, Views: v})
+	}
+	sort.Slice(s.Referrers, func(i, j int) bool {
+		if s.Referrers[i].Views != s.Referrers[j].Views {
+			return s.Referrers[i].Views > s.Referrers[j].Views
+		}
+		return s.Referrers[i].Host < s.Referrers[j].Host
+	})
+
+	return s
+}
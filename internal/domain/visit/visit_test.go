@@ -0,0 +1,57 @@
+package visit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeSalts map[string][]byte
+
+func (f fakeSalts) Salt(_ context.Context, day string) ([]byte, error) {
+	return f[day], nil
+}
+
+func TestNewViewRotatesVisitorID(t *testing.T) {
+	salts := fakeSalts{"2024-05-05": []byte("a"), "2024-05-06": []byte("b")}
+	opt := ViewOptions{AnalystSlug: "mk", IP: "10.0.0.1", UserAgent: "ua", At: time.Date(2024, 5, 5, 12, 0, 0, 0, time.UTC)}
+
+	v1, _ := NewView(context.Background(), salts, opt)
+	v2, _ := NewView(context.Background(), salts, opt)
+	if v1.VisitorID != v2.VisitorID {
+		t.Errorf("visitor id changed within a day: %q != %q", v1.VisitorID, v2.VisitorID)
+	}
+
+	opt.At = opt.At.Add(24 * time.Hour)
+	v3, _ := NewView(context.Background(), salts, opt)
+	if v1.VisitorID == v3.VisitorID {
+		t.Errorf("visitor id did not rotate with the salt")
+	}
+}
+
+func TestNewViewDayIsMoscow(t *testing.T) {
+	salts := fakeSalts{"2024-05-06": []byte("b")}
+	// 22:00 UTC is already the next day in Moscow
+	opt := ViewOptions{AnalystSlug: "mk", At: time.Date(2024, 5, 5, 22, 0, 0, 0, time.UTC)}
+
+	v, err := NewView(context.Background(), salts, opt)
+	if err != nil || v.Day != "2024-05-06" {
+		t.Errorf("want day 2024-05-06, got %q (err %v)", v.Day, err)
+	}
+}
+
+func TestReferrerHost(t *testing.T) {
+	cases := map[string]string{
+		"":                              Direct,
+		"not a url":                     Direct,
+		"https://t.me/some/channel":     "t.me",
+		"https://www.Google.com/":       "google.com",
+		"https://idea-x3.ru/analyst/mk": Internal,
+	}
+
+	for ref, want := range cases {
+		if got := referrerHost(ref, "idea-x3.ru"); got != want {
+			t.Errorf("referrerHost(%q): want %q, got %q", ref, want, got)
+		}
+	}
+}
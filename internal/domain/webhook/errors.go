@@ -0,0 +1,11 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("webhook endpoint not found")
+	ErrConflict = errors.New("webhook endpoint with this url already exists")
+
+	ErrURL     = errors.New("webhook url must be an absolute http(s) url")
+	ErrTooMany = errors.New("too many webhook endpoints")
+)
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const MaxEndpoints = 5
+
+type Event string
+
+const (
+	IdeaCreated     Event = "idea.created"
+	PositionOpened  Event = "position.opened"
+	PositionChanged Event = "position.changed"
+	PositionClosed  Event = "position.closed"
+	Test            Event = "test"
+)
+
+// Endpoint is an analyst's URL that receives signed event payloads.
+type Endpoint struct {
+	ID          string    `bson:"id"`
+	AnalystSlug string    `bson:"analyst_slug"`
+	URL         string    `bson:"url"`
+	Secret      string    `bson:"secret"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
+
+type endpointRepo interface {
+	EndpointsByAnalyst(ctx context.Context, analystSlug string) ([]*Endpoint, error)
+	SaveEndpoint(ctx context.Context, e *Endpoint) error
+}
+
+type CreationOptions struct {
+	URL         string `form:"url"`
+	AnalystSlug string
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func NewEndpoint(ctx context.Context, er endpointRepo, opt CreationOptions) (*Endpoint, error) {
+	opt.URL = strings.TrimSpace(opt.URL)
+
+	u, err := url.Parse(opt.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, errors.Join(err, ErrURL)
+	}
+
+	ee, err := er.EndpointsByAnalyst(ctx, opt.AnalystSlug)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get analyst endpoints: %w", err)
+	}
+	if len(ee) >= MaxEndpoints {
+		return nil, ErrTooMany
+	}
+
+	e := &Endpoint{
+		ID:          randomHex(8),
+		AnalystSlug: opt.AnalystSlug,
+		URL:         opt.URL,
+		Secret:      randomHex(24),
+		CreatedAt:   time.Now(),
+	}
+
+	if err := er.SaveEndpoint(ctx, e); err != nil {
+		return nil, fmt.Errorf("couldn't save webhook endpoint: %w", err)
+	}
+
+	return e, nil
+}
+
+// SignatureTolerance is how far the X-Timestamp of a delivery may be from the
+// receiver's clock. Receivers should reject deliveries outside of it, a captured
+// delivery can't be replayed later than that.
+const SignatureTolerance = 5 * time.Minute
+
+// Sign returns the value of the X-Signature header for a delivery sent at the
+// time: the hex HMAC-SHA256 of "<unix seconds>.<body>" keyed with the endpoint
+// secret, prefixed with "sha256=".
+func (e *Endpoint) Sign(at time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(e.Secret))
+	mac.Write([]byte(strconv.FormatInt(at.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and the timestamp of a delivery the way a receiver should.
+func (e *Endpoint) Verify(timestamp string, body []byte, signature string, now time.Time) bool {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	at := time.Unix(sec, 0)
+	if d := now.Sub(at); d > SignatureTolerance || d < -SignatureTolerance {
+		return false
+	}
+	return hmac.Equal([]byte(e.Sign(at, body)), []byte(signature))
+}
+
+type Status string
+
+const (
+	Pending Status = "pending"
+	Sent    Status = "sent"
+	Failed  Status = "failed"
+)
+
+// Delivery is one event for one endpoint. Deliveries are kept after they are
+// sent or failed and make up the delivery log shown to the analyst.
+type Delivery struct {
+	ID          string `bson:"id"`
+	EndpointID  string `bson:"endpoint_id"`
+	AnalystSlug string `bson:"analyst_slug"`
+	URL         string `bson:"url"`
+	Event       Event  `bson:"event"`
+	Body        []byte `bson:"body"`
+
+	Status        Status    `bson:"status"`
+	Attempts      int       `bson:"attempts"`
+	ResponseCode  int       `bson:"response_code"`
+	LastError     string    `bson:"last_error"`
+	CreatedAt     time.Time `bson:"created_at"`
+	NextAttemptAt time.Time `bson:"next_attempt_at"`
+}
+
+// Payload is the JSON body posted to endpoints.
+type Payload struct {
+	ID        string    `json:"id"`
+	Event     Event     `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Analyst   string    `json:"analyst"`
+	Data      any       `json:"data"`
+}
+
+func NewDelivery(e *Endpoint, ev Event, data any, at time.Time) (Delivery, error) {
+	id := randomHex(12)
+
+	body, err := json.Marshal(Payload{
+		ID:        id,
+		Event:     ev,
+		CreatedAt: at,
+		Analyst:   e.AnalystSlug,
+		Data:      data,
+	})
+	if err != nil {
+		return Delivery{}, fmt.Errorf("couldn't marshal webhook payload: %w", err)
+	}
+
+	return Delivery{
+		ID:            id,
+		EndpointID:    e.ID,
+		AnalystSlug:   e.AnalystSlug,
+		URL:           e.URL,
+		Event:         ev,
+		Body:          body,
+		Status:        Pending,
+		CreatedAt:     at,
+		NextAttemptAt: at,
+	}, nil
+}
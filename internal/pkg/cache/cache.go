@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type item[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// TTL is a concurrency-safe in-memory cache whose entries expire after a fixed time.
+type TTL[K comparable, V any] struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	items     map[K]item[V]
+	lastSweep time.Time
+}
+
+func NewTTL[K comparable, V any](ttl time.Duration) *TTL[K, V] {
+	return &TTL[K, V]{
+		ttl:       ttl,
+		items:     make(map[K]item[V]),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	it, ok := c.items[key]
+	if !ok || time.Now().After(it.expires) {
+		var zero V
+		return zero, false
+	}
+
+	return it.value, true
+}
+
+func (c *TTL[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.items[key] = item[V]{value: value, expires: now.Add(c.ttl)}
+
+	// expired entries are dropped at most once per ttl so that Set stays cheap
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	for k, it := range c.items {
+		if now.After(it.expires) {
+			delete(c.items, k)
+		}
+	}
+	c.lastSweep = now
+}
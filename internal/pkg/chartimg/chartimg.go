@@ -0,0 +1,129 @@
+// Package chartimg renders candle charts to SVG and PNG for link previews.
+package chartimg
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"image/color"
+	"math"
+)
+
+// The size of OpenGraph preview images.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	padLeft   = 40
+	padRight  = 160 // room for level labels
+	padTop    = 110 // room for the title
+	padBottom = 40
+)
+
+var (
+	Background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	Up         = color.RGBA{0x03, 0x86, 0x6a, 0xff}
+	Down       = color.RGBA{0xdc, 0x20, 0x20, 0xff}
+	Grey       = color.RGBA{0x6b, 0x72, 0x80, 0xff}
+	Text       = color.RGBA{0x11, 0x18, 0x27, 0xff}
+)
+
+// Level is a horizontal price line drawn across the chart, e.g. the open or the target price.
+type Level struct {
+	Label  string
+	Price  float64
+	Color  color.RGBA
+	Dashed bool
+}
+
+type Chart struct {
+	Title    string
+	Subtitle string
+	Candles  []chart.Candle
+	Levels   []Level
+}
+
+type rect struct {
+	x, y, w, h float64
+	color      color.RGBA
+}
+
+// line is always horizontal or vertical, which keeps rasterisation trivial.
+type line struct {
+	x1, y1, x2, y2 float64
+	color          color.RGBA
+	dashed         bool
+}
+
+type label struct {
+	x, y  float64
+	text  string
+	size  int
+	color color.RGBA
+}
+
+type scene struct {
+	rects  []rect
+	lines  []line
+	labels []label
+}
+
+func (c Chart) priceRange() (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, cd := range c.Candles {
+		lo, hi = math.Min(lo, cd.Low), math.Max(hi, cd.High)
+	}
+	for _, l := range c.Levels {
+		lo, hi = math.Min(lo, l.Price), math.Max(hi, l.Price)
+	}
+
+	if math.IsInf(lo, 0) {
+		return 0, 1
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+
+	pad := (hi - lo) * 0.05
+	return lo - pad, hi + pad
+}
+
+func (c Chart) scene() scene {
+	var (
+		s      scene
+		lo, hi = c.priceRange()
+		plotW  = float64(Width - padLeft - padRight)
+		plotH  = float64(Height - padTop - padBottom)
+		right  = float64(Width - padRight)
+		y      = func(p float64) float64 { return padTop + (hi-p)/(hi-lo)*plotH }
+	)
+
+	s.labels = append(s.labels,
+		label{x: padLeft, y: 50, text: c.Title, size: 36, color: Text},
+		label{x: padLeft, y: 88, text: c.Subtitle, size: 22, color: Grey},
+	)
+
+	if n := len(c.Candles); n > 0 {
+		slot := plotW / float64(n)
+		body := math.Max(1, math.Floor(slot*0.7))
+		for i, cd := range c.Candles {
+			col := Up
+			if cd.Close < cd.Open {
+				col = Down
+			}
+
+			mid := math.Floor(padLeft + slot*(float64(i)+0.5))
+			top, bottom := y(math.Max(cd.Open, cd.Close)), y(math.Min(cd.Open, cd.Close))
+			s.lines = append(s.lines, line{x1: mid, y1: y(cd.High), x2: mid, y2: y(cd.Low), color: col})
+			s.rects = append(s.rects, rect{x: mid - math.Floor(body/2), y: top, w: body, h: math.Max(1, bottom-top), color: col})
+		}
+	}
+
+	for _, l := range c.Levels {
+		ly := math.Round(y(l.Price))
+		s.lines = append(s.lines, line{x1: padLeft, y1: ly, x2: right, y2: ly, color: l.Color, dashed: l.Dashed})
+		s.labels = append(s.labels, label{x: right + 10, y: ly + 6, text: l.Label, size: 18, color: l.Color})
+	}
+
+	return s
+}
@@ -0,0 +1,82 @@
+package chartimg
+
+import (
+	"bytes"
+	"changemedaddy/internal/domain/chart"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func testChart() Chart {
+	return Chart{
+		Title:    "LONG SBER",
+		Subtitle: "Сбер <Банк> & co",
+		Candles: []chart.Candle{
+			{Time: 1, Open: 100, Close: 110, High: 112, Low: 98},
+			{Time: 2, Open: 110, Close: 105, High: 111, Low: 101},
+			{Time: 3, Open: 105, Close: 105, High: 106, Low: 104},
+		},
+		Levels: []Level{
+			{Label: "Открытие 100", Price: 100, Color: Grey, Dashed: true},
+			{Label: "Цель 130", Price: 130, Color: Up, Dashed: true},
+		},
+	}
+}
+
+func TestScene(t *testing.T) {
+	s := testChart().scene()
+
+	if len(s.rects) != 3 {
+		t.Fatalf("got %d candle bodies, want 3", len(s.rects))
+	}
+	// the target is above every candle, so it must be drawn higher
+	target := s.lines[len(s.lines)-1]
+	for _, r := range s.rects {
+		if r.y <= target.y1 {
+			t.Errorf("candle body at y=%.1f is above the target at y=%.1f", r.y, target.y1)
+		}
+	}
+	for _, r := range s.rects {
+		if r.x < padLeft || r.x+r.w > Width-padRight || r.y < padTop || r.y+r.h > Height-padBottom {
+			t.Errorf("candle body %+v is outside of the plot", r)
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testChart().SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	d := xml.NewDecoder(&buf)
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("svg is not well-formed: %v", err)
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	if err := (Chart{}).PNG(io.Discard); err != nil {
+		t.Fatalf("empty chart: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := testChart().PNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != Width || b.Dy() != Height {
+		t.Errorf("got %dx%d image, want %dx%d", b.Dx(), b.Dy(), Width, Height)
+	}
+}
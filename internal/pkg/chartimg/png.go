@@ -0,0 +1,46 @@
+package chartimg
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// PNG rasterises the chart. There's no font rasteriser in the standard library,
+// so unlike SVG the image has no labels; previews show the title next to it anyway.
+func (c Chart) PNG(w io.Writer) error {
+	var (
+		s   = c.scene()
+		img = image.NewRGBA(image.Rect(0, 0, Width, Height))
+	)
+
+	draw.Draw(img, img.Bounds(), image.NewUniform(Background), image.Point{}, draw.Src)
+
+	fill := func(x0, y0, x1, y1 float64, u *image.Uniform) {
+		r := image.Rect(int(math.Floor(x0)), int(math.Floor(y0)), int(math.Ceil(x1)), int(math.Ceil(y1)))
+		draw.Draw(img, r, u, image.Point{}, draw.Src)
+	}
+
+	for _, r := range s.rects {
+		fill(r.x, r.y, r.x+r.w, r.y+r.h, image.NewUniform(r.color))
+	}
+
+	for _, l := range s.lines {
+		u := image.NewUniform(l.color)
+		if l.y1 == l.y2 {
+			step, dash := l.x2-l.x1, l.x2-l.x1
+			if l.dashed {
+				step, dash = 14, 8
+			}
+			for x := l.x1; x < l.x2; x += step {
+				fill(x, l.y1, math.Min(x+dash, l.x2), l.y1+1, u)
+			}
+		} else {
+			fill(l.x1, math.Min(l.y1, l.y2), l.x1+1, math.Max(l.y1, l.y2), u)
+		}
+	}
+
+	return png.Encode(w, img)
+}
@@ -0,0 +1,39 @@
+package chartimg
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+)
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (c Chart) SVG(w io.Writer) error {
+	var (
+		s  = c.scene()
+		bw = bufio.NewWriter(w)
+	)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, Width, Height, Width, Height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`, hex(Background))
+	for _, r := range s.rects {
+		fmt.Fprintf(bw, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, r.x, r.y, r.w, r.h, hex(r.color))
+	}
+	for _, l := range s.lines {
+		dash := ""
+		if l.dashed {
+			dash = ` stroke-dasharray="8 6"`
+		}
+		fmt.Fprintf(bw, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="1"%s/>`, l.x1, l.y1, l.x2, l.y2, hex(l.color), dash)
+	}
+	for _, l := range s.labels {
+		fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" font-family="Inter, sans-serif" font-size="%d" fill="%s">%s</text>`, l.x, l.y, l.size, hex(l.color), html.EscapeString(l.text))
+	}
+	fmt.Fprint(bw, `</svg>`)
+
+	return bw.Flush()
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrShuttingDown = errors.New("shutting down")
+
+type Check func(ctx context.Context) error
+
+// Checker tells an orchestrator whether the app can serve traffic. The zero value
+// is ready and has no checks.
+type Checker struct {
+	mu       sync.Mutex
+	names    []string
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+// Add registers a dependency check under the given name.
+func (c *Checker) Add(name string, chk Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.checks == nil {
+		c.checks = make(map[string]Check)
+	}
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = chk
+}
+
+// Ready runs all checks concurrently and returns their errors by name. It
+// reports not ready without running anything once Shutdown was called.
+func (c *Checker) Ready(ctx context.Context) (map[string]error, bool) {
+	if c.draining.Load() {
+		return map[string]error{"app": ErrShuttingDown}, false
+	}
+
+	c.mu.Lock()
+	names := make([]string, len(c.names))
+	copy(names, c.names)
+	checks := make(map[string]Check, len(c.checks))
+	for n, chk := range c.checks {
+		checks[n] = chk
+	}
+	c.mu.Unlock()
+	sort.Strings(names)
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make(map[string]error, len(names))
+		ok   = true
+	)
+	for _, n := range names {
+		wg.Add(1)
+		go func(n string) {
+			defer wg.Done()
+
+			err := checks[n](ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			errs[n] = err
+			if err != nil {
+				ok = false
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	return errs, ok
+}
+
+// Shutdown flips readiness off so that load balancers stop sending new requests.
+// Add it to closer.Closer before the server shutdown, so it happens before draining.
+func (c *Checker) Shutdown(ctx context.Context) error {
+	c.draining.Store(true)
+	return nil
+}
@@ -0,0 +1,239 @@
+// Package markdown renders the subset of Markdown analysts write ideas in:
+// headings, paragraphs, lists, quotes, emphasis, code, links and images.
+//
+// The output is safe to embed: all the text is escaped and only the tags this
+// package writes get through, so raw HTML in the source shows up as text. Links
+// and images keep only http, https and site-relative URLs.
+package markdown
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type blockKind int
+
+const (
+	paragraph blockKind = iota
+	heading
+	bullets
+	numbers
+	quote
+	code
+)
+
+type block struct {
+	kind  blockKind
+	level int
+	lines []string
+}
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,3})\s+(.*)$`)
+	bulletRe  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberRe  = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	quoteRe   = regexp.MustCompile(`^>\s?(.*)$`)
+)
+
+// blocks splits the source into blocks, blank lines end paragraphs and lists.
+func blocks(src string) []block {
+	var (
+		bb  []block
+		cur *block
+	)
+	flush := func() {
+		if cur != nil {
+			bb = append(bb, *cur)
+			cur = nil
+		}
+	}
+	add := func(kind blockKind, line string) {
+		if cur == nil || cur.kind != kind {
+			flush()
+			cur = &block{kind: kind}
+		}
+		cur.lines = append(cur.lines, line)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for n := 0; n < len(lines); n++ {
+		line := lines[n]
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			flush()
+			cur = &block{kind: code}
+			for n++; n < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[n]), "```"); n++ {
+				cur.lines = append(cur.lines, lines[n])
+			}
+			flush()
+			continue
+		}
+
+		switch m := headingRe.FindStringSubmatch(line); {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case m != nil:
+			flush()
+			bb = append(bb, block{kind: heading, level: len(m[1]), lines: []string{m[2]}})
+		case bulletRe.MatchString(line):
+			add(bullets, bulletRe.FindStringSubmatch(line)[1])
+		case numberRe.MatchString(line):
+			add(numbers, numberRe.FindStringSubmatch(line)[1])
+		case quoteRe.MatchString(line):
+			add(quote, quoteRe.FindStringSubmatch(line)[1])
+		default:
+			add(paragraph, strings.TrimSpace(line))
+		}
+	}
+	flush()
+
+	return bb
+}
+
+// HTML renders the source. Headings start at h3, the page and the sections own
+// the levels above.
+func HTML(src string) template.HTML {
+	var b strings.Builder
+	for _, bl := range blocks(src) {
+		switch bl.kind {
+		case heading:
+			tag := []string{"h3", "h4", "h5"}[bl.level-1]
+			b.WriteString("<" + tag + ">" + inline(bl.lines[0]) + "</" + tag + ">\n")
+		case bullets, numbers:
+			tag := "ul"
+			if bl.kind == numbers {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for _, l := range bl.lines {
+				b.WriteString("<li>" + inline(l) + "</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+		case quote:
+			b.WriteString("<blockquote>" + inline(strings.Join(bl.lines, " ")) + "</blockquote>\n")
+		case code:
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(bl.lines, "\n")) + "</code></pre>\n")
+		default:
+			b.WriteString("<p>" + inline(strings.Join(bl.lines, " ")) + "</p>\n")
+		}
+	}
+
+	// everything above is escaped or written here
+	return template.HTML(b.String())
+}
+
+var (
+	codeRe   = regexp.MustCompile("`([^`]+)`")
+	imageRe  = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	linkRe   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	strongRe = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	emRe     = regexp.MustCompile(`\*([^*]+)\*`)
+
+	placeholderRe = regexp.MustCompile("\x00[0-9]+\x00")
+)
+
+// spans holds the rendered parts of a line out of the way of the formatting
+// of the rest, behind placeholders.
+type spans []string
+
+func (ss *spans) keep(html string) string {
+	*ss = append(*ss, html)
+	return "\x00" + strconv.Itoa(len(*ss)-1) + "\x00"
+}
+
+// restore puts the parts back, the later ones first as they may hold the earlier.
+func (ss spans) restore(s string) string {
+	for n := len(ss) - 1; n >= 0; n-- {
+		s = strings.Replace(s, "\x00"+strconv.Itoa(n)+"\x00", ss[n], 1)
+	}
+	return s
+}
+
+func emphasis(s string) string {
+	s = strongRe.ReplaceAllString(s, "<strong>$1</strong>")
+	return emRe.ReplaceAllString(s, "<em>$1</em>")
+}
+
+// inline renders the spans of a line. Code is cut out first so nothing inside
+// it is formatted, links and images next so their URLs aren't.
+func inline(s string) string {
+	var ss spans
+	s = strings.ReplaceAll(s, "\x00", "")
+	s = codeRe.ReplaceAllStringFunc(s, func(m string) string {
+		return ss.keep("<code>" + html.EscapeString(codeRe.FindStringSubmatch(m)[1]) + "</code>")
+	})
+
+	s = html.EscapeString(s)
+	s = imageRe.ReplaceAllStringFunc(s, func(m string) string {
+		sm := imageRe.FindStringSubmatch(m)
+		u, ok := safeURL(sm[2])
+		if !ok {
+			return sm[1]
+		}
+		alt := placeholderRe.ReplaceAllString(sm[1], "")
+		return ss.keep(`<img src="` + u + `" alt="` + alt + `" loading="lazy">`)
+	})
+	s = linkRe.ReplaceAllStringFunc(s, func(m string) string {
+		sm := linkRe.FindStringSubmatch(m)
+		u, ok := safeURL(sm[2])
+		if !ok {
+			return sm[1]
+		}
+		return ss.keep(`<a href="` + u + `" rel="nofollow noopener" target="_blank">` + emphasis(sm[1]) + `</a>`)
+	})
+
+	return ss.restore(emphasis(s))
+}
+
+// safeURL lets through http, https and site-relative URLs. The URL comes
+// escaped already and is returned so.
+func safeURL(escaped string) (string, bool) {
+	u, err := url.Parse(html.UnescapeString(escaped))
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case u.Scheme == "http" || u.Scheme == "https":
+	case u.Scheme == "" && u.Host == "" && strings.HasPrefix(u.Path, "/") && !strings.HasPrefix(u.Path, "//"):
+	default:
+		return "", false
+	}
+	return html.EscapeString(u.String()), true
+}
+
+var markupRe = regexp.MustCompile("[*`#>]+")
+
+// Plain is the text of the source with the markup left out, links and images
+// by their text.
+func Plain(src string) string {
+	var ss []string
+	for _, bl := range blocks(src) {
+		if bl.kind == code {
+			continue
+		}
+		text := strings.Join(bl.lines, " ")
+		text = imageRe.ReplaceAllString(text, "$1")
+		text = linkRe.ReplaceAllString(text, "$1")
+		ss = append(ss, strings.TrimSpace(markupRe.ReplaceAllString(text, "")))
+	}
+	return strings.Join(strings.Fields(strings.Join(ss, " ")), " ")
+}
+
+// Excerpt is the start of the plain text cut at a word to at most n runes.
+func Excerpt(src string, n int) string {
+	text := Plain(src)
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+
+	cut := string([]rune(text)[:n])
+	if sp := strings.LastIndex(cut, " "); sp > 0 {
+		cut = cut[:sp]
+	}
+	return strings.TrimRight(cut, " ,.;:—-") + "…"
+}
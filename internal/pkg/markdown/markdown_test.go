@@ -0,0 +1,55 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"paragraphs", "Сбер\nдешёвый\n\nВТБ нет", "<p>Сбер дешёвый</p>\n<p>ВТБ нет</p>\n"},
+		{"heading", "## Риски", "<h4>Риски</h4>\n"},
+		{"list", "- **дивиденды** 12%\n- *байбэк*", "<ul>\n<li><strong>дивиденды</strong> 12%</li>\n<li><em>байбэк</em></li>\n</ul>\n"},
+		{"numbers", "1. раз\n2) два", "<ol>\n<li>раз</li>\n<li>два</li>\n</ol>\n"},
+		{"code", "ставка `*ЦБ*` и\n```\n<b>\n```", "<p>ставка <code>*ЦБ*</code> и</p>\n<pre><code>&lt;b&gt;</code></pre>\n"},
+		{"link", "[отчёт](https://e-disclosure.ru/a_b_c?x=1&y=2)", `<p><a href="https://e-disclosure.ru/a_b_c?x=1&amp;y=2" rel="nofollow noopener" target="_blank">отчёт</a></p>` + "\n"},
+		{"image", "![график](/attachments/mk/sber/1.png)", `<p><img src="/attachments/mk/sber/1.png" alt="график" loading="lazy"></p>` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(HTML(tt.src)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLIsSanitised(t *testing.T) {
+	for _, src := range []string{
+		`<script>alert(1)</script>`,
+		`<img src=x onerror=alert(1)>`,
+		`[жми](javascript:alert(1))`,
+		`![x](data:text/html;base64,PHNjcmlwdD4=)`,
+		`[x](//evil.com/a)`,
+		`![" onerror="alert(1)](/a.png)`,
+	} {
+		got := string(HTML(src))
+		for _, bad := range []string{"<script", "<img src=x", "javascript:", "data:", "//evil.com", `" onerror`} {
+			if strings.Contains(got, bad) {
+				t.Errorf("%q rendered to %q, which has %q", src, got, bad)
+			}
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	src := "# Тезис\n\n**Сбер** растёт на [дивидендах](https://sber.ru), ROE выше 20%.\n\n```\nкод\n```"
+	if got, want := Plain(src), "Тезис Сбер растёт на дивидендах, ROE выше 20%."; got != want {
+		t.Errorf("got plain %q, want %q", got, want)
+	}
+	if got, want := Excerpt(src, 22), "Тезис Сбер растёт на…"; got != want {
+		t.Errorf("got excerpt %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are latency buckets in seconds, the same as the Prometheus client's defaults.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	name() string
+	write(ctx context.Context, w io.Writer) error
+}
+
+// Registry holds metrics and writes them in the Prometheus text exposition format.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// Default is the registry used by the New* constructors.
+var Default = &Registry{}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for n, rc := range r.collectors {
+		if rc.name() != c.name() {
+			continue
+		}
+		// gauges read whatever their latest owner has, so re-registering one just
+		// points it at the new owner
+		_, old := rc.(*GaugeFunc)
+		_, fresh := c.(*GaugeFunc)
+		if !old || !fresh {
+			panic(fmt.Sprintf("metric %q registered twice", c.name()))
+		}
+		r.collectors[n] = c
+		return
+	}
+
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText writes all registered metrics to w, sorted by name.
+func (r *Registry) WriteText(ctx context.Context, w io.Writer) error {
+	r.mu.Lock()
+	cc := make([]collector, len(r.collectors))
+	copy(cc, r.collectors)
+	r.mu.Unlock()
+
+	sort.Slice(cc, func(i, j int) bool { return cc[i].name() < cc[j].name() })
+
+	for _, c := range cc {
+		if err := c.write(ctx, w); err != nil {
+			return fmt.Errorf("couldn't write metric %q: %w", c.name(), err)
+		}
+	}
+
+	return nil
+}
+
+type desc struct {
+	Name   string
+	Help   string
+	Labels []string
+}
+
+func (d desc) name() string {
+	return d.Name
+}
+
+func (d desc) header(w io.Writer, typ string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.Name, d.Help, d.Name, typ)
+	return err
+}
+
+func (d desc) key(values []string) string {
+	if len(values) != len(d.Labels) {
+		panic(fmt.Sprintf("metric %q wants %d label values, got %d", d.Name, len(d.Labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelEscaper escapes label values the way the text format wants, which
+// unlike Go quoting leaves non-ASCII as is.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (d desc) labels(key string, extra ...string) string {
+	var pairs []string
+	if len(d.Labels) > 0 {
+		for i, v := range strings.Split(key, "\xff") {
+			pairs = append(pairs, d.Labels[i]+`="`+labelEscaper.Replace(v)+`"`)
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+labelEscaper.Replace(extra[i+1])+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a monotonically growing counter partitioned by labels.
+type CounterVec struct {
+	desc
+	mu   sync.Mutex
+	vals map[string]float64
+}
+
+// NewCounterVec registers a counter in the Default registry.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		desc: desc{Name: name, Help: help, Labels: labels},
+		vals: make(map[string]float64),
+	}
+	Default.register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	k := c.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vals[k] += v
+}
+
+func (c *CounterVec) write(_ context.Context, w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.header(w, "counter"); err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(c.vals) {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.Name, c.labels(k), formatFloat(c.vals[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec counts observations into cumulative buckets, partitioned by labels.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	vals    map[string]*histogram
+}
+
+// NewHistogramVec registers a histogram in the Default registry. Buckets must be sorted.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		desc:    desc{Name: name, Help: help, Labels: labels},
+		buckets: buckets,
+		vals:    make(map[string]*histogram),
+	}
+	Default.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	k := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist, ok := h.vals[k]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.vals[k] = hist
+	}
+
+	for i, b := range h.buckets {
+		if v <= b {
+			hist.counts[i]++
+		}
+	}
+	hist.count++
+	hist.sum += v
+}
+
+func (h *HistogramVec) write(_ context.Context, w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.header(w, "histogram"); err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(h.vals) {
+		hist := h.vals[k]
+		for i, b := range h.buckets {
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.Name, h.labels(k, "le", formatFloat(b)), hist.counts[i]); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.Name, h.labels(k, "le", "+Inf"), hist.count); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.Name, h.labels(k), formatFloat(hist.sum), h.Name, h.labels(k), hist.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GaugeFunc is a gauge whose value is computed on every scrape.
+type GaugeFunc struct {
+	desc
+	f func(ctx context.Context) (float64, error)
+}
+
+// NewGaugeFunc registers a gauge in the Default registry. If f fails, the sample is
+// omitted from the scrape instead of failing the whole exposition.
+func NewGaugeFunc(name, help string, f func(ctx context.Context) (float64, error)) *GaugeFunc {
+	g := &GaugeFunc{
+		desc: desc{Name: name, Help: help},
+		f:    f,
+	}
+	Default.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(ctx context.Context, w io.Writer) error {
+	v, err := g.f(ctx)
+	if err != nil {
+		return nil
+	}
+
+	if err := g.header(w, "gauge"); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s %s\n", g.Name, formatFloat(v))
+	return err
+}
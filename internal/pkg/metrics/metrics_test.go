@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := &Registry{}
+
+	c := &CounterVec{desc: desc{Name: "calls_total", Help: "Calls.", Labels: []string{"method"}}, vals: make(map[string]float64)}
+	h := &HistogramVec{desc: desc{Name: "latency_seconds", Help: "Latency."}, buckets: []float64{0.1, 1}, vals: make(map[string]*histogram)}
+	r.register(h)
+	r.register(c)
+
+	c.Inc("find")
+	c.Add(2, "find")
+	c.Inc("price")
+	h.Observe(0.05)
+	h.Observe(0.5)
+
+	var buf bytes.Buffer
+	if err := r.WriteText(context.Background(), &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# HELP calls_total Calls.
+# TYPE calls_total counter
+calls_total{method="find"} 3
+calls_total{method="price"} 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 2
+latency_seconds_sum 0.55
+latency_seconds_count 2
+`
+	if buf.String() != want {
+		t.Errorf("unexpected exposition:\nwant:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	r := &Registry{}
+	c := &CounterVec{desc: desc{Name: "views_total", Help: "Views.", Labels: []string{"page"}}, vals: make(map[string]float64)}
+	r.register(c)
+	c.Inc("Идея \"x\"\\\n")
+
+	var buf bytes.Buffer
+	if err := r.WriteText(context.Background(), &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `views_total{page="Идея \"x\"\\\n"} 1`; !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Errorf("want %s in:\n%s", want, buf.String())
+	}
+}
+
+func TestGaugeReregistered(t *testing.T) {
+	r := &Registry{}
+	r.register(&GaugeFunc{desc: desc{Name: "ideas", Help: "Ideas."}, f: func(context.Context) (float64, error) { return 1, nil }})
+	r.register(&GaugeFunc{desc: desc{Name: "ideas", Help: "Ideas."}, f: func(context.Context) (float64, error) { return 2, nil }})
+
+	var buf bytes.Buffer
+	if err := r.WriteText(context.Background(), &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "# HELP ideas Ideas.\n# TYPE ideas gauge\nideas 2\n"; buf.String() != want {
+		t.Errorf("unexpected exposition:\nwant:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
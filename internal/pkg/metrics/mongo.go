@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var mongoQueryDuration = NewHistogramVec(
+	"mongo_query_duration_seconds",
+	"Duration of MongoDB commands issued by the repositories.",
+	DefBuckets,
+	"collection", "command", "status",
+)
+
+// MongoMonitor returns a command monitor that records repository query timings
+// per collection and command. Pass it to options.Client().SetMonitor.
+func MongoMonitor() *event.CommandMonitor {
+	var collections sync.Map // request id -> collection name
+
+	finish := func(e event.CommandFinishedEvent, status string) {
+		coll, ok := collections.LoadAndDelete(e.RequestID)
+		if !ok {
+			return
+		}
+		mongoQueryDuration.Observe(e.Duration.Seconds(), coll.(string), e.CommandName, status)
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			coll, ok := e.Command.Lookup(e.CommandName).StringValueOK()
+			if !ok {
+				// ping, hello and the like aren't repository queries
+				return
+			}
+			collections.Store(e.RequestID, coll)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.CommandFinishedEvent, "ok")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.CommandFinishedEvent, "error")
+		},
+	}
+}
@@ -0,0 +1,61 @@
+// Package netguard keeps outgoing requests to addresses readers give us away
+// from our own network: loopback, private, link-local and the like.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrNotPublic = errors.New("address is not public")
+
+// cgnat is the shared address space carriers put between their clients and the internet.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublic tells if the address is routable on the internet.
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!cgnat.Contains(ip)
+}
+
+// Control refuses to connect to addresses that aren't public. It runs after the
+// name is resolved, so a host that resolves to an internal address is caught
+// whatever it looked like when it was saved.
+func Control(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("couldn't parse dialed address %q: %w", address, err)
+	}
+	if !IsPublic(ap.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNotPublic, ap.Addr())
+	}
+	return nil
+}
+
+// Client is an HTTP client that only connects to public addresses, redirects
+// included. It ignores the proxy settings, a proxy would hide where it connects.
+func Client(timeout time.Duration) *http.Client {
+	d := &net.Dialer{Timeout: timeout, Control: Control}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         d.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package netguard
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublic(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":                true,
+		"2a00:1450:4010::200e":   true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"::1":                    false,
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+	}
+
+	for s, want := range cases {
+		if got := IsPublic(netip.MustParseAddr(s)); got != want {
+			t.Errorf("IsPublic(%s): want %v, got %v", s, want, got)
+		}
+	}
+}
+
+func TestControl(t *testing.T) {
+	if err := Control("tcp4", "169.254.169.254:80", nil); !errors.Is(err, ErrNotPublic) {
+		t.Errorf("want ErrNotPublic for the metadata address, got %v", err)
+	}
+	if err := Control("tcp6", "[2a00:1450:4010::200e]:443", nil); err != nil {
+		t.Errorf("want a public address let through, got %v", err)
+	}
+}
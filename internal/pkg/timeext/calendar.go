@@ -0,0 +1,199 @@
+package timeext
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	_ "time/tzdata" // Europe/Moscow must load on hosts without a zoneinfo database
+)
+
+// Moscow is the exchange's time zone, all domain times are in it.
+var Moscow = loadMoscow()
+
+func loadMoscow() *time.Location {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		// Moscow has had no DST since 2014
+		return time.FixedZone("MSK", 3*60*60)
+	}
+	return loc
+}
+
+// Date is a calendar day, without a time or a zone.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func DateOf(t time.Time) Date {
+	y, m, d := t.In(Moscow).Date()
+	return Date{y, m, d}
+}
+
+func (d Date) At(clock time.Duration) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, Moscow).Add(clock)
+}
+
+// Calendar tells trading days from the others. Weekends are closed unless they
+// are in Open, which is for Saturdays made working days by a holiday transfer.
+type Calendar struct {
+	// Annual are the holidays that fall on the same day every year, only Day and Month are used.
+	Annual []Date
+	Closed []Date
+	Open   []Date
+	// Close is the time of day the main session closes at.
+	Close time.Duration
+}
+
+// moexOverrides are the days the exchange announced for the supported years.
+//
+//go:embed moex_calendar.json
+var moexOverrides []byte
+
+// MOEX is the Moscow Exchange stock market calendar: the main session closes at
+// 18:50, and the public holidays on weekdays are closed. The transferred working
+// Saturdays and the closures that aren't annual holidays come from
+// moex_calendar.json, LoadOverrides adds the ones announced after the build.
+var MOEX = mustOverride(Calendar{
+	Annual: []Date{
+		{Month: time.January, Day: 1},
+		{Month: time.January, Day: 2},
+		{Month: time.January, Day: 7},
+		{Month: time.February, Day: 23},
+		{Month: time.March, Day: 8},
+		{Month: time.May, Day: 1},
+		{Month: time.May, Day: 9},
+		{Month: time.June, Day: 12},
+		{Month: time.November, Day: 4},
+		{Month: time.December, Day: 31},
+	},
+	Close: 18*time.Hour + 50*time.Minute,
+}, moexOverrides)
+
+// Overrides are the days a calendar gets in Closed and Open, as 2006-01-02 dates.
+type Overrides struct {
+	Closed []string `json:"closed"`
+	Open   []string `json:"open"`
+}
+
+func parseDates(ss []string) ([]Date, error) {
+	var (
+		dd   []Date
+		errs error
+	)
+	for _, s := range ss {
+		t, err := time.ParseInLocation(time.DateOnly, s, Moscow)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("bad date %q: %w", s, err))
+			continue
+		}
+		dd = append(dd, DateOf(t))
+	}
+	return dd, errs
+}
+
+// WithOverrides returns the calendar with the days read from r added to its
+// Closed and Open.
+func (c Calendar) WithOverrides(r io.Reader) (Calendar, error) {
+	var o Overrides
+	if err := json.NewDecoder(r).Decode(&o); err != nil {
+		return c, fmt.Errorf("couldn't decode overrides: %w", err)
+	}
+
+	closed, cerr := parseDates(o.Closed)
+	open, oerr := parseDates(o.Open)
+	if err := errors.Join(cerr, oerr); err != nil {
+		return c, err
+	}
+
+	c.Closed = append(append([]Date(nil), c.Closed...), closed...)
+	c.Open = append(append([]Date(nil), c.Open...), open...)
+	return c, nil
+}
+
+func mustOverride(c Calendar, data []byte) Calendar {
+	c, err := c.WithOverrides(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// LoadOverrides adds the days from the file at path to MOEX. It's meant for the
+// schedule changes announced after the build and must run before anything reads
+// the calendar.
+func LoadOverrides(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("couldn't open calendar: %w", err)
+	}
+	defer f.Close()
+
+	c, err := MOEX.WithOverrides(f)
+	if err != nil {
+		return fmt.Errorf("couldn't read calendar %s: %w", path, err)
+	}
+	MOEX = c
+	return nil
+}
+
+func contains(dd []Date, d Date) bool {
+	for _, c := range dd {
+		if c == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Calendar) IsTradingDay(t time.Time) bool {
+	d := DateOf(t)
+	if contains(c.Open, d) {
+		return true
+	}
+	if wd := t.In(Moscow).Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	return !contains(c.Closed, d) && !contains(c.Annual, Date{Month: d.Month, Day: d.Day})
+}
+
+// SessionEnd is the end of the session on t's day, or on the first trading day after it.
+func (c Calendar) SessionEnd(t time.Time) time.Time {
+	for !c.IsTradingDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return DateOf(t).At(c.Close)
+}
+
+// TradingDaysLeft counts the sessions that haven't ended by now, up to the one
+// ending at deadline.
+func (c Calendar) TradingDaysLeft(now, deadline time.Time) int {
+	var n int
+	for d := DateOf(now).At(0); !d.After(deadline); d = d.AddDate(0, 0, 1) {
+		if c.IsTradingDay(d) && DateOf(d).At(c.Close).After(now) {
+			n++
+		}
+	}
+	return n
+}
+
+// ParseDate parses a 2.01.2006 date as Moscow midnight.
+func ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation("2.01.2006", s, Moscow)
+}
+
+// ParseDateTime parses a 2.01.2006 15:04 moment, or one from a datetime-local
+// input, in Moscow time.
+func ParseDateTime(s string) (time.Time, error) {
+	t, err := time.ParseInLocation("2.01.2006 15:04", s, Moscow)
+	if err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04", s, Moscow)
+}
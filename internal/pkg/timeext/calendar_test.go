@@ -0,0 +1,83 @@
+package timeext
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionEnd(t *testing.T) {
+	cases := []struct {
+		day, want string
+	}{
+		{"14.05.2024", "14.05.2024"},
+		// Saturday
+		{"18.05.2024", "20.05.2024"},
+		// Victory Day on a Thursday
+		{"9.05.2024", "10.05.2024"},
+		// New Year holidays run into a weekend
+		{"31.12.2025", "5.01.2026"},
+		// Women's Day on a Sunday moves to the Monday
+		{"9.03.2026", "10.03.2026"},
+	}
+	for _, c := range cases {
+		day, _ := ParseDate(c.day)
+		want, _ := ParseDate(c.want)
+		want = want.Add(MOEX.Close)
+
+		if got := MOEX.SessionEnd(day); !got.Equal(want) {
+			t.Errorf("SessionEnd(%s) = %s; want %s", c.day, got, want)
+		}
+	}
+}
+
+func TestSessionEndIgnoresServerZone(t *testing.T) {
+	// 23:00 on Friday in New York is already Saturday in Moscow
+	ny := time.FixedZone("EDT", -4*60*60)
+	got := MOEX.SessionEnd(time.Date(2024, 5, 17, 23, 0, 0, 0, ny))
+	if want := time.Date(2024, 5, 20, 18, 50, 0, 0, Moscow); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestTradingDaysLeft(t *testing.T) {
+	deadline := time.Date(2024, 5, 14, 18, 50, 0, 0, Moscow)
+	cases := []struct {
+		now  time.Time
+		want int
+	}{
+		// Wednesday before the session ends: 8, 10, 13, 14 May, the 9th is a holiday
+		{time.Date(2024, 5, 8, 12, 0, 0, 0, Moscow), 4},
+		{time.Date(2024, 5, 8, 19, 0, 0, 0, Moscow), 3},
+		{time.Date(2024, 5, 14, 19, 0, 0, 0, Moscow), 0},
+	}
+	for _, c := range cases {
+		if got := MOEX.TradingDaysLeft(c.now, deadline); got != c.want {
+			t.Errorf("TradingDaysLeft(%s) = %d; want %d", c.now, got, c.want)
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	c, err := MOEX.WithOverrides(strings.NewReader(`{"open": ["2024-04-27"], "closed": ["2024-04-29"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a Saturday made a working day by a transfer
+	sat := time.Date(2024, 4, 27, 10, 0, 0, 0, Moscow)
+	if got, want := c.SessionEnd(sat), time.Date(2024, 4, 27, 18, 50, 0, 0, Moscow); !got.Equal(want) {
+		t.Errorf("SessionEnd(Saturday) = %s; want %s", got, want)
+	}
+	// and the Monday it was transferred from is closed
+	if got, want := c.SessionEnd(sat.AddDate(0, 0, 1)), time.Date(2024, 4, 30, 18, 50, 0, 0, Moscow); !got.Equal(want) {
+		t.Errorf("SessionEnd(Sunday) = %s; want %s", got, want)
+	}
+	if MOEX.IsTradingDay(sat) {
+		t.Error("overrides leaked into MOEX")
+	}
+
+	if _, err := MOEX.WithOverrides(strings.NewReader(`{"open": ["27.04.2024"]}`)); err == nil {
+		t.Error("bad date accepted")
+	}
+}
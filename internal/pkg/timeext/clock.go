@@ -0,0 +1,52 @@
+package timeext
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return Now()
+}
+
+// Now is the wall clock time in Moscow.
+func Now() time.Time {
+	return time.Now().In(Moscow)
+}
+
+// SystemClock is the wall clock, in Moscow.
+var SystemClock Clock = systemClock{}
+
+// ManualClock only moves when told to, which makes time-dependent logic testable.
+type ManualClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{t: t}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
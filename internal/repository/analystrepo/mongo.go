@@ -20,6 +20,7 @@ const (
 type analystRepo interface {
 	Save(ctx context.Context, a *analyst.Analyst) error
 	FindBySlug(ctx context.Context, slug string) (*analyst.Analyst, error)
+	Count(ctx context.Context) (int64, error)
 }
 
 type mongoRepo struct {
@@ -75,3 +76,15 @@ func (r *mongoRepo) FindBySlug(ctx context.Context, slug string) (*analyst.Analy
 		return nil, fmt.Errorf("couldn't find analyst: %w", sr.Err())
 	}
 }
+
+func (r *mongoRepo) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	n, err := r.aa.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return 0, fmt.Errorf("couldn't count analysts: %w", err)
+	}
+
+	return n, nil
+}
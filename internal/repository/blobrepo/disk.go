@@ -0,0 +1,91 @@
+package blobrepo
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// diskRepo keeps the blobs as files under a directory, the key is the path.
+type diskRepo struct {
+	dir string
+}
+
+func NewDisk(dir string) (*diskRepo, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("couldn't create blob dir: %w", err)
+	}
+	return &diskRepo{dir: dir}, nil
+}
+
+// path rejects the keys that would get out of the directory.
+func (r *diskRepo) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." {
+		return "", idea.ErrAttachmentNotFound
+	}
+	return filepath.Join(r.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes the blob to a temporary file first so a half-written one is never served.
+func (r *diskRepo) Put(ctx context.Context, key string, data []byte) error {
+	p, err := r.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("couldn't create blob dir: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("couldn't create blob file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("couldn't write blob: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("couldn't write blob: %w", err)
+	}
+	if err := os.Rename(f.Name(), p); err != nil {
+		return fmt.Errorf("couldn't move blob: %w", err)
+	}
+	return nil
+}
+
+func (r *diskRepo) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	p, err := r.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, idea.ErrAttachmentNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't open blob: %w", err)
+	}
+	if fi, err := f.Stat(); err != nil || fi.IsDir() {
+		f.Close()
+		return nil, idea.ErrAttachmentNotFound
+	}
+	return f, nil
+}
+
+func (r *diskRepo) Delete(ctx context.Context, key string) error {
+	p, err := r.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("couldn't delete blob: %w", err)
+	}
+	return nil
+}
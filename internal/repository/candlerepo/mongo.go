@@ -0,0 +1,192 @@
+package candlerepo
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	dbName       = "ideax3"
+	queryTimeout = 2 * time.Second
+
+	codeNamespaceExists = 48
+)
+
+type mongoRepo struct {
+	client   *mongo.Client
+	candles  *mongo.Collection
+	coverage *mongo.Collection
+}
+
+func NewMongo(ctx context.Context, client *mongo.Client) (*mongoRepo, error) {
+	db := client.Database(dbName)
+	r := &mongoRepo{
+		client:   client,
+		candles:  db.Collection("candles"),
+		coverage: db.Collection("candle_coverage"),
+	}
+
+	if err := r.ensureCollections(ctx); err != nil {
+		return nil, fmt.Errorf("couldn't create candle collections: %w", err)
+	}
+
+	return r, nil
+}
+
+func (r *mongoRepo) ensureCollections(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*queryTimeout)
+	defer cancel()
+
+	ts := options.TimeSeries().SetTimeField("time").SetMetaField("meta").SetGranularity("minutes")
+	err := r.client.Database(dbName).CreateCollection(ctx, "candles", options.CreateCollection().SetTimeSeriesOptions(ts))
+	var ce mongo.CommandError
+	if err != nil && !(errors.As(err, &ce) && ce.Code == codeNamespaceExists) {
+		return err
+	}
+
+	if _, err := r.candles.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "meta.uid", Value: 1}, {Key: "meta.interval", Value: 1}, {Key: "time", Value: 1}},
+	}); err != nil {
+		return err
+	}
+
+	_, err = r.coverage.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "uid", Value: 1}, {Key: "interval", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+type meta struct {
+	UID      string         `bson:"uid"`
+	Interval chart.Interval `bson:"interval"`
+}
+
+type candleDoc struct {
+	Time      time.Time `bson:"time"`
+	Meta      meta      `bson:"meta"`
+	FetchedAt time.Time `bson:"fetched_at"`
+	Open      float64   `bson:"open"`
+	Close     float64   `bson:"close"`
+	High      float64   `bson:"high"`
+	Low       float64   `bson:"low"`
+}
+
+type coverageDoc struct {
+	UID            string         `bson:"uid"`
+	Interval       chart.Interval `bson:"interval"`
+	chart.Coverage `bson:",inline"`
+}
+
+func seriesFilter(uid string, interval chart.Interval) bson.D {
+	return bson.D{{Key: "uid", Value: uid}, {Key: "interval", Value: interval}}
+}
+
+func (r *mongoRepo) Coverage(ctx context.Context, uid string, interval chart.Interval) (chart.Coverage, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	var doc coverageDoc
+	err := r.coverage.FindOne(ctx, seriesFilter(uid, interval)).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return chart.Coverage{}, nil
+	} else if err != nil {
+		return chart.Coverage{}, fmt.Errorf("couldn't find candle coverage: %w", err)
+	}
+
+	return doc.Coverage, nil
+}
+
+// Add stores freshly fetched candles and the coverage they extend the series to.
+// Time-series collections can't be upserted into, so the stored candles in the
+// fetched range are deleted first. Candles still picks the latest copy if two
+// Adds of the same range race.
+func (r *mongoRepo) Add(ctx context.Context, uid string, interval chart.Interval, cc []chart.Candle, cov chart.Coverage) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if len(cc) > 0 {
+		var (
+			now         = time.Now()
+			docs        = make([]any, 0, len(cc))
+			first, last = cc[0].Time, cc[0].Time
+		)
+		for _, c := range cc {
+			first, last = min(first, c.Time), max(last, c.Time)
+			docs = append(docs, candleDoc{
+				Time:      time.Unix(c.Time, 0),
+				Meta:      meta{UID: uid, Interval: interval},
+				FetchedAt: now,
+				Open:      c.Open,
+				Close:     c.Close,
+				High:      c.High,
+				Low:       c.Low,
+			})
+		}
+
+		filter := bson.D{
+			{Key: "meta.uid", Value: uid},
+			{Key: "meta.interval", Value: interval},
+			{Key: "time", Value: bson.D{{Key: "$gte", Value: time.Unix(first, 0)}, {Key: "$lte", Value: time.Unix(last, 0)}}},
+		}
+		if _, err := r.candles.DeleteMany(ctx, filter); err != nil {
+			return fmt.Errorf("couldn't delete refetched candles: %w", err)
+		}
+		if _, err := r.candles.InsertMany(ctx, docs); err != nil {
+			return fmt.Errorf("couldn't insert candles: %w", err)
+		}
+	}
+
+	_, err := r.coverage.ReplaceOne(ctx,
+		seriesFilter(uid, interval),
+		coverageDoc{UID: uid, Interval: interval, Coverage: cov},
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't update candle coverage: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mongoRepo) Candles(ctx context.Context, uid string, interval chart.Interval, from, to time.Time) ([]chart.Candle, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "meta.uid", Value: uid},
+		{Key: "meta.interval", Value: interval},
+		{Key: "time", Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lte", Value: to}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: 1}, {Key: "fetched_at", Value: 1}})
+
+	cur, err := r.candles.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find candles: %w", err)
+	}
+
+	var docs []candleDoc
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("couldn't decode candles: %w", err)
+	}
+
+	cc := make([]chart.Candle, 0, len(docs))
+	for _, d := range docs {
+		c := chart.Candle{Time: d.Time.Unix(), Open: d.Open, Close: d.Close, High: d.High, Low: d.Low}
+		// copies of the same candle come latest last
+		if n := len(cc); n > 0 && cc[n-1].Time == c.Time {
+			cc[n-1] = c
+			continue
+		}
+		cc = append(cc, c)
+	}
+
+	return cc, nil
+}
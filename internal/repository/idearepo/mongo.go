@@ -86,11 +86,15 @@ func (r *mongoRepo) FindBySlug(ctx context.Context, analystSlug, ideaSlug string
 	return i, nil
 }
 
-func (r *mongoRepo) FindByAnalystSlug(ctx context.Context, analystSlug string) ([]*idea.Idea, error) {
+// FindByAnalystSlug finds the analyst's ideas with any of the statuses, all of them for none.
+func (r *mongoRepo) FindByAnalystSlug(ctx context.Context, analystSlug string, ss ...idea.Status) ([]*idea.Idea, error) {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
 
 	filter := bson.M{"author_slug": analystSlug}
+	if len(ss) > 0 {
+		filter["status"] = bson.M{"$in": ss}
+	}
 	cur, err := r.ideas.Find(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't find ideas: %w", err)
@@ -103,3 +107,84 @@ func (r *mongoRepo) FindByAnalystSlug(ctx context.Context, analystSlug string) (
 
 	return ii, nil
 }
+
+func (r *mongoRepo) CountActive(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	n, err := r.ideas.CountDocuments(ctx, bson.D{{Key: "status", Value: idea.Active}})
+	if err != nil {
+		return 0, fmt.Errorf("couldn't count active ideas: %w", err)
+	}
+
+	return n, nil
+}
+
+// FindScheduled finds the scheduled drafts and the ideas with pending positions.
+func (r *mongoRepo) FindScheduled(ctx context.Context) ([]*idea.Idea, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": idea.Draft, "publish_at": bson.M{"$gt": time.Time{}}},
+		bson.M{"pending_ids.0": bson.M{"$exists": true}},
+	}}
+	cur, err := r.ideas.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find scheduled ideas: %w", err)
+	}
+
+	var ii []*idea.Idea
+	if err := cur.All(ctx, &ii); err != nil {
+		return nil, fmt.Errorf("couldn't find scheduled ideas: %w", err)
+	}
+
+	return ii, nil
+}
+
+// FindUnannounced finds the published ideas the analysts' webhooks weren't told about.
+func (r *mongoRepo) FindUnannounced(ctx context.Context) ([]*idea.Idea, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cur, err := r.ideas.Find(ctx, bson.M{"unannounced": true})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find unannounced ideas: %w", err)
+	}
+
+	var ii []*idea.Idea
+	if err := cur.All(ctx, &ii); err != nil {
+		return nil, fmt.Errorf("couldn't find unannounced ideas: %w", err)
+	}
+
+	return ii, nil
+}
+
+func (r *mongoRepo) SetAnnounced(ctx context.Context, i *idea.Idea) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if _, err := r.ideas.UpdateOne(ctx, ideaFilter(i), bson.M{"$unset": bson.M{"unannounced": ""}}); err != nil {
+		return fmt.Errorf("couldn't mark idea announced: %w", err)
+	}
+
+	return nil
+}
+
+// FindByPosition finds the idea the position, open or pending, belongs to.
+func (r *mongoRepo) FindByPosition(ctx context.Context, id int) (*idea.Idea, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	i := new(idea.Idea)
+	filter := bson.M{"$or": bson.A{bson.M{"position_ids": id}, bson.M{"pending_ids": id}}}
+	err := r.ideas.FindOne(ctx, filter).Decode(i)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, idea.ErrNotFound
+		}
+		return nil, fmt.Errorf("could't find or decode idea: %w", err)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,89 @@
+package outboxrepo
+
+import (
+	"changemedaddy/internal/domain/notification"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	dbName         = "ideax3"
+	collectionName = "notification_outbox"
+	queryTimeout   = time.Second
+)
+
+var errNotFound = errors.New("delivery not found")
+
+type mongoRepo struct {
+	client *mongo.Client
+	dd     *mongo.Collection
+}
+
+func NewMongo(ctx context.Context, client *mongo.Client) *mongoRepo {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &mongoRepo{
+		client: client,
+		dd:     collection,
+	}
+}
+
+func (r *mongoRepo) Enqueue(ctx context.Context, dd []notification.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	docs := make([]any, len(dd))
+	for i, d := range dd {
+		docs[i] = d
+	}
+
+	if _, err := r.dd.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("couldn't insert deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// Claim moves the due delivery's next attempt past the lease in the same
+// operation that finds it, so no one else finds it until then.
+func (r *mongoRepo) Claim(ctx context.Context, now time.Time, lease time.Duration) (notification.Delivery, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	var (
+		d      notification.Delivery
+		filter = bson.D{
+			{Key: "status", Value: notification.Pending},
+			{Key: "next_attempt_at", Value: bson.D{{Key: "$lte", Value: now}}},
+		}
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: "next_attempt_at", Value: now.Add(lease)}}}}
+		opts   = options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).SetReturnDocument(options.After)
+	)
+	err := r.dd.FindOneAndUpdate(ctx, filter, update, opts).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return notification.Delivery{}, false, nil
+	} else if err != nil {
+		return notification.Delivery{}, false, fmt.Errorf("couldn't claim delivery: %w", err)
+	}
+
+	return d, true, nil
+}
+
+func (r *mongoRepo) Update(ctx context.Context, d notification.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	sr := r.dd.FindOneAndReplace(ctx, bson.D{{Key: "id", Value: d.ID}}, d)
+	if errors.Is(sr.Err(), mongo.ErrNoDocuments) {
+		return errNotFound
+	} else if sr.Err() != nil {
+		return fmt.Errorf("couldn't update delivery: %w", sr.Err())
+	}
+
+	return nil
+}
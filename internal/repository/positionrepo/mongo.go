@@ -17,12 +17,19 @@ const (
 	dbName         = "ideax3"
 	collectionName = "position"
 	queryTimeout   = time.Second
+	// migrationTimeout is for the updates of the whole collection at startup.
+	migrationTimeout = 30 * time.Second
 )
 
+// historySize is the length of a position's history in an aggregation.
+var historySize = bson.D{{Key: "$size", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$history", bson.A{}}}}}}
+
 type positionRepo interface {
 	Save(ctx context.Context, p *position.Position) error
 	Find(ctx context.Context, id int) (*position.Position, error)
 	Update(ctx context.Context, p *position.Position) error
+	Delete(ctx context.Context, id int) error
+	CountActive(ctx context.Context) (int64, error)
 }
 
 type priceProvider interface {
@@ -34,12 +41,34 @@ type mongoRepo struct {
 	pp     *mongo.Collection
 }
 
-func NewMongo(ctx context.Context, client *mongo.Client) *mongoRepo {
+func NewMongo(ctx context.Context, client *mongo.Client) (*mongoRepo, error) {
 	collection := client.Database(dbName).Collection(collectionName)
-	return &mongoRepo{
+	r := &mongoRepo{
 		client: client,
 		pp:     collection,
 	}
+
+	if err := r.markAnnounced(ctx); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// markAnnounced counts the history of the positions saved before announcements
+// were tracked as told, their events went out when they happened.
+func (r *mongoRepo) markAnnounced(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, migrationTimeout)
+	defer cancel()
+
+	_, err := r.pp.UpdateMany(ctx,
+		bson.D{{Key: "announced", Value: bson.D{{Key: "$exists", Value: false}}}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "announced", Value: historySize}}}}},
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't mark old positions announced: %w", err)
+	}
+	return nil
 }
 
 func positionFilter(id int) bson.D {
@@ -80,6 +109,44 @@ func (r *mongoRepo) Update(ctx context.Context, p *position.Position) error {
 	return nil
 }
 
+// UpdateUnchanged saves the position unless its history has grown past
+// history changes since it was read.
+func (r *mongoRepo) UpdateUnchanged(ctx context.Context, p *position.Position, history int) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := positionFilter(p.ID)
+	if history == 0 {
+		filter = append(filter, bson.E{Key: "history", Value: bson.D{{Key: "$in", Value: bson.A{nil, bson.A{}}}}})
+	} else {
+		filter = append(filter, bson.E{Key: "history", Value: bson.D{{Key: "$size", Value: history}}})
+	}
+	sr := r.pp.FindOneAndReplace(ctx, filter, p)
+
+	if errors.Is(sr.Err(), mongo.ErrNoDocuments) {
+		return position.ErrModified
+	} else if sr.Err() != nil {
+		return fmt.Errorf("couldn't update position: %w", sr.Err())
+	}
+
+	return nil
+}
+
+func (r *mongoRepo) Delete(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	res, err := r.pp.DeleteOne(ctx, positionFilter(id))
+	if err != nil {
+		return fmt.Errorf("couldn't delete position: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return position.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *mongoRepo) Find(ctx context.Context, id int) (*position.Position, error) {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
@@ -95,3 +162,68 @@ func (r *mongoRepo) Find(ctx context.Context, id int) (*position.Position, error
 
 	return p, nil
 }
+
+func (r *mongoRepo) CountActive(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	n, err := r.pp.CountDocuments(ctx, bson.D{{Key: "status", Value: position.Active}})
+	if err != nil {
+		return 0, fmt.Errorf("couldn't count active positions: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *mongoRepo) FindActive(ctx context.Context) ([]*position.Position, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cur, err := r.pp.Find(ctx, bson.D{{Key: "status", Value: position.Active}})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find active positions: %w", err)
+	}
+
+	var pp []*position.Position
+	if err := cur.All(ctx, &pp); err != nil {
+		return nil, fmt.Errorf("couldn't decode active positions: %w", err)
+	}
+
+	return pp, nil
+}
+
+// FindUnannounced finds the open and closed positions with changes no one was told about.
+func (r *mongoRepo) FindUnannounced(ctx context.Context) ([]*position.Position, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{position.Active, position.Closed}}}},
+		{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{"$announced", historySize}}}},
+	}
+	cur, err := r.pp.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find unannounced positions: %w", err)
+	}
+
+	var pp []*position.Position
+	if err := cur.All(ctx, &pp); err != nil {
+		return nil, fmt.Errorf("couldn't decode unannounced positions: %w", err)
+	}
+
+	return pp, nil
+}
+
+// SetAnnounced moves the position's count of announced changes to n, unless
+// it was moved since the position was read.
+func (r *mongoRepo) SetAnnounced(ctx context.Context, p *position.Position, n int) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.D{{Key: "id", Value: p.ID}, {Key: "announced", Value: p.Announced}}
+	if _, err := r.pp.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: bson.D{{Key: "announced", Value: n}}}}); err != nil {
+		return fmt.Errorf("couldn't mark position announced: %w", err)
+	}
+
+	return nil
+}
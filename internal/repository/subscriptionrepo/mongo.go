@@ -0,0 +1,123 @@
+package subscriptionrepo
+
+import (
+	"changemedaddy/internal/domain/subscription"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	dbName         = "ideax3"
+	collectionName = "subscription"
+	queryTimeout   = time.Second
+)
+
+type mongoRepo struct {
+	client *mongo.Client
+	ss     *mongo.Collection
+}
+
+func NewMongo(ctx context.Context, client *mongo.Client) *mongoRepo {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &mongoRepo{
+		client: client,
+		ss:     collection,
+	}
+}
+
+func (r *mongoRepo) Save(ctx context.Context, s *subscription.Subscription) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "channel", Value: s.Channel},
+		{Key: "address", Value: s.Address},
+		{Key: "analyst_slug", Value: s.AnalystSlug},
+		{Key: "idea_slug", Value: s.IdeaSlug},
+	}
+	sr := r.ss.FindOne(ctx, filter)
+	if sr.Err() == nil {
+		return subscription.ErrConflict
+	} else if !errors.Is(sr.Err(), mongo.ErrNoDocuments) {
+		return fmt.Errorf("couldn't verify that subscription is unique: %w", sr.Err())
+	}
+
+	_, err := r.ss.InsertOne(ctx, s)
+	if err != nil {
+		return fmt.Errorf("could't insert subscription to repo: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mongoRepo) Find(ctx context.Context, id string) (*subscription.Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	s := new(subscription.Subscription)
+	err := r.ss.FindOne(ctx, bson.D{{Key: "id", Value: id}}).Decode(s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, subscription.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't find subscription: %w", err)
+	}
+
+	return s, nil
+}
+
+func (r *mongoRepo) FindByTarget(ctx context.Context, analystSlug, ideaSlug string) ([]*subscription.Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"analyst_slug": analystSlug,
+		"idea_slug":    bson.M{"$in": bson.A{"", ideaSlug}},
+		"unconfirmed":  bson.M{"$ne": true},
+	}
+	cur, err := r.ss.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find subscriptions: %w", err)
+	}
+
+	var ss []*subscription.Subscription
+	if err := cur.All(ctx, &ss); err != nil {
+		return nil, fmt.Errorf("couldn't decode subscriptions: %w", err)
+	}
+
+	return ss, nil
+}
+
+func (r *mongoRepo) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	res, err := r.ss.DeleteOne(ctx, bson.D{{Key: "id", Value: id}})
+	if err != nil {
+		return fmt.Errorf("couldn't delete subscription: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return subscription.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *mongoRepo) Confirm(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	res, err := r.ss.UpdateOne(ctx, bson.D{{Key: "id", Value: id}}, bson.D{{Key: "$unset", Value: bson.D{{Key: "unconfirmed", Value: ""}}}})
+	if err != nil {
+		return fmt.Errorf("couldn't confirm subscription: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return subscription.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,290 @@
+package visitorsrepo
+
+import (
+	"changemedaddy/internal/domain/visit"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	dbName       = "ideax3"
+	queryTimeout = time.Second
+
+	// saltTTL bounds how long a day's salt (and with it the ability to tell
+	// whether a visitor was already counted) lives after the day starts.
+	saltTTL = 48 * time.Hour
+)
+
+type mongoRepo struct {
+	client    *mongo.Client
+	salts     *mongo.Collection
+	seen      *mongo.Collection
+	rollups   *mongo.Collection
+	referrers *mongo.Collection
+
+	mu     sync.Mutex
+	cached map[string][]byte
+}
+
+func NewMongo(ctx context.Context, client *mongo.Client) (*mongoRepo, error) {
+	db := client.Database(dbName)
+	r := &mongoRepo{
+		client:    client,
+		salts:     db.Collection("visitor_salts"),
+		seen:      db.Collection("visitor_seen"),
+		rollups:   db.Collection("daily_views"),
+		referrers: db.Collection("daily_referrers"),
+		cached:    make(map[string][]byte),
+	}
+
+	if err := r.ensureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("couldn't create visitor indexes: %w", err)
+	}
+
+	return r, nil
+}
+
+func (r *mongoRepo) ensureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*queryTimeout)
+	defer cancel()
+
+	expire := func() mongo.IndexModel {
+		return mongo.IndexModel{
+			Keys:    bson.D{{Key: "expire_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		}
+	}
+	unique := func(keys ...string) mongo.IndexModel {
+		var d bson.D
+		for _, k := range keys {
+			d = append(d, bson.E{Key: k, Value: 1})
+		}
+		return mongo.IndexModel{Keys: d, Options: options.Index().SetUnique(true)}
+	}
+
+	if _, err := r.salts.Indexes().CreateMany(ctx, []mongo.IndexModel{expire(), unique("day")}); err != nil {
+		return err
+	}
+	if _, err := r.seen.Indexes().CreateOne(ctx, expire()); err != nil {
+		return err
+	}
+	if _, err := r.rollups.Indexes().CreateOne(ctx, unique("day", "analyst_slug", "idea_slug", "position_id")); err != nil {
+		return err
+	}
+	if _, err := r.referrers.Indexes().CreateOne(ctx, unique("day", "analyst_slug", "host")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type saltDoc struct {
+	Day      string    `bson:"day"`
+	Salt     []byte    `bson:"salt"`
+	ExpireAt time.Time `bson:"expire_at"`
+}
+
+type seenDoc struct {
+	ID       string    `bson:"_id"`
+	ExpireAt time.Time `bson:"expire_at"`
+}
+
+type rollupDoc struct {
+	Day         string `bson:"day"`
+	AnalystSlug string `bson:"analyst_slug"`
+	IdeaSlug    string `bson:"idea_slug"`
+	PositionID  int    `bson:"position_id"`
+	Views       int    `bson:"views"`
+	Visitors    int    `bson:"visitors"`
+}
+
+type referrerDoc struct {
+	Day         string `bson:"day"`
+	AnalystSlug string `bson:"analyst_slug"`
+	Host        string `bson:"host"`
+	Views       int    `bson:"views"`
+}
+
+// Salt doesn't hold the lock over the query, instances racing for a new day's
+// salt all get the one inserted first anyway.
+func (r *mongoRepo) Salt(ctx context.Context, day string) ([]byte, error) {
+	r.mu.Lock()
+	s, ok := r.cached[day]
+	r.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	fresh := make([]byte, 32)
+	if _, err := rand.Read(fresh); err != nil {
+		return nil, fmt.Errorf("couldn't generate salt: %w", err)
+	}
+
+	var (
+		doc    saltDoc
+		filter = bson.D{{Key: "day", Value: day}}
+		update = bson.D{{Key: "$setOnInsert", Value: saltDoc{Day: day, Salt: fresh, ExpireAt: time.Now().Add(saltTTL)}}}
+		opts   = options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	)
+	err := r.salts.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+	if mongo.IsDuplicateKeyError(err) {
+		// another instance inserted the salt concurrently
+		err = r.salts.FindOne(ctx, filter).Decode(&doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get salt: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the cache only ever needs today's and maybe yesterday's salt
+	for d := range r.cached {
+		if d < day {
+			delete(r.cached, d)
+		}
+	}
+	r.cached[day] = doc.Salt
+
+	return doc.Salt, nil
+}
+
+func (r *mongoRepo) Add(ctx context.Context, v visit.View) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	seenID := strings.Join([]string{v.Day, v.AnalystSlug, v.IdeaSlug, strconv.Itoa(v.PositionID), v.VisitorID}, "|")
+	_, err := r.seen.InsertOne(ctx, seenDoc{ID: seenID, ExpireAt: time.Now().Add(saltTTL)})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("couldn't mark visitor as seen: %w", err)
+	}
+
+	newVisitor := 0
+	if err == nil {
+		newVisitor = 1
+	}
+
+	upsert := options.Update().SetUpsert(true)
+
+	_, err = r.rollups.UpdateOne(ctx,
+		bson.D{
+			{Key: "day", Value: v.Day},
+			{Key: "analyst_slug", Value: v.AnalystSlug},
+			{Key: "idea_slug", Value: v.IdeaSlug},
+			{Key: "position_id", Value: v.PositionID},
+		},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "views", Value: 1}, {Key: "visitors", Value: newVisitor}}}},
+		upsert,
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't update daily views: %w", err)
+	}
+
+	_, err = r.referrers.UpdateOne(ctx,
+		bson.D{
+			{Key: "day", Value: v.Day},
+			{Key: "analyst_slug", Value: v.AnalystSlug},
+			{Key: "host", Value: v.Referrer},
+		},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "views", Value: 1}}}},
+		upsert,
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't update daily referrers: %w", err)
+	}
+
+	return nil
+}
+
+func dayRange(from, to time.Time) bson.D {
+	return bson.D{{Key: "day", Value: bson.D{
+		{Key: "$gte", Value: from.In(timeext.Moscow).Format(visit.DayFormat)},
+		{Key: "$lte", Value: to.In(timeext.Moscow).Format(visit.DayFormat)},
+	}}}
+}
+
+func (r *mongoRepo) find(ctx context.Context, filter bson.D) (map[string][]visit.Rollup, map[string][]visit.ReferrerRollup, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cur, err := r.rollups.Find(ctx, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't find daily views: %w", err)
+	}
+	var rr []rollupDoc
+	if err := cur.All(ctx, &rr); err != nil {
+		return nil, nil, fmt.Errorf("couldn't decode daily views: %w", err)
+	}
+
+	cur, err = r.referrers.Find(ctx, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't find daily referrers: %w", err)
+	}
+	var ff []referrerDoc
+	if err := cur.All(ctx, &ff); err != nil {
+		return nil, nil, fmt.Errorf("couldn't decode daily referrers: %w", err)
+	}
+
+	rollups := make(map[string][]visit.Rollup)
+	for _, d := range rr {
+		rollups[d.AnalystSlug] = append(rollups[d.AnalystSlug], visit.Rollup{
+			Day:         d.Day,
+			AnalystSlug: d.AnalystSlug,
+			IdeaSlug:    d.IdeaSlug,
+			PositionID:  d.PositionID,
+			Counts:      visit.Counts{Views: d.Views, Visitors: d.Visitors},
+		})
+	}
+
+	refs := make(map[string][]visit.ReferrerRollup)
+	for _, d := range ff {
+		refs[d.AnalystSlug] = append(refs[d.AnalystSlug], visit.ReferrerRollup{
+			Day:         d.Day,
+			AnalystSlug: d.AnalystSlug,
+			Host:        d.Host,
+			Views:       d.Views,
+		})
+	}
+
+	return rollups, refs, nil
+}
+
+func (r *mongoRepo) Stats(ctx context.Context, analystSlug string, from, to time.Time) (visit.Stats, error) {
+	filter := append(dayRange(from, to), bson.E{Key: "analyst_slug", Value: analystSlug})
+
+	rollups, refs, err := r.find(ctx, filter)
+	if err != nil {
+		return visit.Stats{}, err
+	}
+
+	return visit.Summarize(analystSlug, rollups[analystSlug], refs[analystSlug]), nil
+}
+
+func (r *mongoRepo) AllStats(ctx context.Context, from, to time.Time) ([]visit.Stats, error) {
+	rollups, refs, err := r.find(ctx, dayRange(from, to))
+	if err != nil {
+		return nil, err
+	}
+
+	ss := make([]visit.Stats, 0, len(rollups))
+	for slug, rr := range rollups {
+		ss = append(ss, visit.Summarize(slug, rr, refs[slug]))
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].Total.Views > ss[j].Total.Views })
+
+	return ss, nil
+}
@@ -0,0 +1,170 @@
+package webhookrepo
+
+import (
+	"changemedaddy/internal/domain/webhook"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	dbName       = "ideax3"
+	queryTimeout = time.Second
+)
+
+type mongoRepo struct {
+	client     *mongo.Client
+	endpoints  *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+func NewMongo(ctx context.Context, client *mongo.Client) *mongoRepo {
+	db := client.Database(dbName)
+	return &mongoRepo{
+		client:     client,
+		endpoints:  db.Collection("webhook_endpoint"),
+		deliveries: db.Collection("webhook_delivery"),
+	}
+}
+
+func (r *mongoRepo) SaveEndpoint(ctx context.Context, e *webhook.Endpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	sr := r.endpoints.FindOne(ctx, bson.D{{Key: "analyst_slug", Value: e.AnalystSlug}, {Key: "url", Value: e.URL}})
+	if sr.Err() == nil {
+		return webhook.ErrConflict
+	} else if !errors.Is(sr.Err(), mongo.ErrNoDocuments) {
+		return fmt.Errorf("couldn't verify that endpoint is unique: %w", sr.Err())
+	}
+
+	if _, err := r.endpoints.InsertOne(ctx, e); err != nil {
+		return fmt.Errorf("could't insert endpoint to repo: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mongoRepo) DeleteEndpoint(ctx context.Context, analystSlug, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	res, err := r.endpoints.DeleteOne(ctx, bson.D{{Key: "analyst_slug", Value: analystSlug}, {Key: "id", Value: id}})
+	if err != nil {
+		return fmt.Errorf("couldn't delete endpoint: %w", err)
+	}
+	if res.DeletedCount == 0 {
+		return webhook.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *mongoRepo) FindEndpoint(ctx context.Context, id string) (*webhook.Endpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	e := new(webhook.Endpoint)
+	err := r.endpoints.FindOne(ctx, bson.D{{Key: "id", Value: id}}).Decode(e)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, webhook.ErrNotFound
+		}
+		return nil, fmt.Errorf("could't find or decode endpoint: %w", err)
+	}
+
+	return e, nil
+}
+
+func (r *mongoRepo) EndpointsByAnalyst(ctx context.Context, analystSlug string) ([]*webhook.Endpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	cur, err := r.endpoints.Find(ctx, bson.D{{Key: "analyst_slug", Value: analystSlug}})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find endpoints: %w", err)
+	}
+
+	var ee []*webhook.Endpoint
+	if err := cur.All(ctx, &ee); err != nil {
+		return nil, fmt.Errorf("couldn't decode endpoints: %w", err)
+	}
+
+	return ee, nil
+}
+
+func (r *mongoRepo) Enqueue(ctx context.Context, dd []webhook.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	docs := make([]any, len(dd))
+	for i, d := range dd {
+		docs[i] = d
+	}
+
+	if _, err := r.deliveries.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("couldn't insert webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mongoRepo) Due(ctx context.Context, now time.Time, limit int) ([]webhook.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "status", Value: webhook.Pending},
+		{Key: "next_attempt_at", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).SetLimit(int64(limit))
+
+	cur, err := r.deliveries.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find due webhook deliveries: %w", err)
+	}
+
+	var dd []webhook.Delivery
+	if err := cur.All(ctx, &dd); err != nil {
+		return nil, fmt.Errorf("couldn't decode webhook deliveries: %w", err)
+	}
+
+	return dd, nil
+}
+
+func (r *mongoRepo) UpdateDelivery(ctx context.Context, d webhook.Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	sr := r.deliveries.FindOneAndReplace(ctx, bson.D{{Key: "id", Value: d.ID}}, d)
+	if errors.Is(sr.Err(), mongo.ErrNoDocuments) {
+		return webhook.ErrNotFound
+	} else if sr.Err() != nil {
+		return fmt.Errorf("couldn't update webhook delivery: %w", sr.Err())
+	}
+
+	return nil
+}
+
+func (r *mongoRepo) DeliveriesByAnalyst(ctx context.Context, analystSlug string, limit int) ([]webhook.Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	cur, err := r.deliveries.Find(ctx, bson.D{{Key: "analyst_slug", Value: analystSlug}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find webhook deliveries: %w", err)
+	}
+
+	var dd []webhook.Delivery
+	if err := cur.All(ctx, &dd); err != nil {
+		return nil, fmt.Errorf("couldn't decode webhook deliveries: %w", err)
+	}
+
+	return dd, nil
+}
@@ -0,0 +1,225 @@
+// Package announce tells the subscribers and the analysts' webhooks about what
+// happens to public ideas and their positions. It reads what to tell from the
+// positions' history, so a change saved is a change announced, even if the
+// process stops right after saving it.
+package announce
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/notification"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/domain/webhook"
+	"changemedaddy/internal/ui"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+const (
+	checkInterval = 5 * time.Second
+	checkTimeout  = time.Minute
+)
+
+type notifier interface {
+	Publish(ctx context.Context, e notification.Event) error
+}
+
+type hookSender interface {
+	Publish(ctx context.Context, analystSlug string, ev webhook.Event, data any) error
+}
+
+type ideaRepo interface {
+	FindUnannounced(ctx context.Context) ([]*idea.Idea, error)
+	SetAnnounced(ctx context.Context, i *idea.Idea) error
+	FindByPosition(ctx context.Context, id int) (*idea.Idea, error)
+}
+
+type positionRepo interface {
+	FindUnannounced(ctx context.Context) ([]*position.Position, error)
+	SetAnnounced(ctx context.Context, p *position.Position, n int) error
+}
+
+type quoteProvider interface {
+	Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error)
+	Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error)
+}
+
+var webhookEvents = map[notification.Kind]webhook.Event{
+	notification.PositionOpened:  webhook.PositionOpened,
+	notification.PositionChanged: webhook.PositionChanged,
+	notification.PositionClosed:  webhook.PositionClosed,
+}
+
+type Announcer struct {
+	log *slog.Logger
+	nd  notifier
+	hs  hookSender
+	ir  ideaRepo
+	pr  positionRepo
+	qp  quoteProvider
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+func New(log *slog.Logger, nd notifier, hs hookSender, ir ideaRepo, pr positionRepo, qp quoteProvider) *Announcer {
+	return &Announcer{
+		log:  log,
+		nd:   nd,
+		hs:   hs,
+		ir:   ir,
+		pr:   pr,
+		qp:   qp,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+type ideaData struct {
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+	SourceLink string `json:"source_link"`
+	Link       string `json:"link"`
+}
+
+type positionData struct {
+	Idea     ideaData             `json:"idea"`
+	Position ui.PositionComponent `json:"position"`
+}
+
+func ideaPayload(i *idea.Idea) ideaData {
+	return ideaData{
+		Name:       i.Name,
+		Slug:       i.Slug,
+		SourceLink: i.SourceLink,
+		Link:       "https://idea-x3.ru/analyst/" + i.AuthorSlug + "/idea/" + i.Slug,
+	}
+}
+
+// AnnounceDue announces the published ideas and the position changes no one
+// was told about yet. What fails is left unannounced and retried next time.
+func (a *Announcer) AnnounceDue(ctx context.Context) error {
+	ii, err := a.ir.FindUnannounced(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't find unannounced ideas: %w", err)
+	}
+	// the idea goes first, its positions opened with it
+	for _, i := range ii {
+		if err := a.hs.Publish(ctx, i.AuthorSlug, webhook.IdeaCreated, ideaPayload(i)); err != nil {
+			a.log.Warn("couldn't announce idea", "idea", i.Slug, "analyst", i.AuthorSlug, "err", err)
+			continue
+		}
+		if err := a.ir.SetAnnounced(ctx, i); err != nil {
+			a.log.Warn("couldn't mark idea announced", "idea", i.Slug, "analyst", i.AuthorSlug, "err", err)
+		}
+	}
+
+	pp, err := a.pr.FindUnannounced(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't find unannounced positions: %w", err)
+	}
+	for _, p := range pp {
+		if err := a.announce(ctx, p); err != nil {
+			a.log.Warn("couldn't announce position", "id", p.ID, "err", err)
+		}
+	}
+
+	return nil
+}
+
+// announce tells about the changes of the position past the announced ones as
+// one event, or just marks them if the position isn't public.
+func (a *Announcer) announce(ctx context.Context, p *position.Position) error {
+	i, err := a.ir.FindByPosition(ctx, p.ID)
+	if errors.Is(err, idea.ErrNotFound) {
+		// closed in a draft and dropped on publication, it never was public
+		return a.pr.SetAnnounced(ctx, p, len(p.History))
+	} else if err != nil {
+		return fmt.Errorf("couldn't find idea of position: %w", err)
+	}
+
+	switch {
+	case i.Status == idea.Draft && p.Status == position.Active:
+		// the idea is being published, its positions are saved before it
+		return nil
+	case !i.IsPublic():
+		return a.pr.SetAnnounced(ctx, p, len(p.History))
+	}
+
+	kind := notification.PositionChanged
+	for _, c := range p.History[min(p.Announced, len(p.History)):] {
+		switch c.Kind {
+		case position.CloseChange:
+			kind = notification.PositionClosed
+		case position.OpenedChange:
+			if kind != notification.PositionClosed {
+				kind = notification.PositionOpened
+			}
+		}
+	}
+	if p.Status == position.Closed {
+		kind = notification.PositionClosed
+	}
+
+	wp, err := p.WithProfit(ctx, a.qp)
+	if err != nil {
+		return fmt.Errorf("couldn't get profit of position: %w", err)
+	}
+
+	e := notification.PositionEvent(kind, i.AuthorSlug, i.AuthorName, i.Slug, i.Name, wp.Position)
+	if err := a.nd.Publish(ctx, e); err != nil {
+		return fmt.Errorf("couldn't publish position event: %w", err)
+	}
+	err = a.hs.Publish(ctx, i.AuthorSlug, webhookEvents[kind], positionData{
+		Idea:     ideaPayload(i),
+		Position: ui.Position(false, i.AuthorSlug, i.Slug, wp),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't publish webhook event: %w", err)
+	}
+
+	return a.pr.SetAnnounced(ctx, p, len(p.History))
+}
+
+// Start announces until Shutdown is called.
+func (a *Announcer) Start() {
+	go func() {
+		defer close(a.done)
+
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			if err := a.AnnounceDue(ctx); err != nil {
+				a.log.Error("couldn't announce", "err", err)
+			}
+			cancel()
+
+			select {
+			case <-a.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the loop, waiting for the current announcements to finish.
+func (a *Announcer) Shutdown(ctx context.Context) error {
+	a.once.Do(func() { close(a.stop) })
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("announcer didn't stop: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,161 @@
+package announce
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/notification"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/domain/webhook"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type fixedPrice struct{}
+
+func (fixedPrice) Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error) {
+	return decimal.NewFromInt(110), nil
+}
+
+func (fixedPrice) Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error) {
+	return nil, nil
+}
+
+type memIdeas []*idea.Idea
+
+func (ii memIdeas) FindUnannounced(ctx context.Context) ([]*idea.Idea, error) {
+	var due []*idea.Idea
+	for _, i := range ii {
+		if i.Unannounced {
+			due = append(due, i)
+		}
+	}
+	return due, nil
+}
+
+func (ii memIdeas) SetAnnounced(ctx context.Context, i *idea.Idea) error {
+	i.Unannounced = false
+	return nil
+}
+
+func (ii memIdeas) FindByPosition(ctx context.Context, id int) (*idea.Idea, error) {
+	for _, i := range ii {
+		if slices.Contains(i.PositionIDs, id) || slices.Contains(i.PendingIDs, id) {
+			return i, nil
+		}
+	}
+	return nil, idea.ErrNotFound
+}
+
+type memPositions map[int]*position.Position
+
+func (pp memPositions) FindUnannounced(ctx context.Context) ([]*position.Position, error) {
+	var due []*position.Position
+	for _, p := range pp {
+		if (p.Status == position.Active || p.Status == position.Closed) && p.Announced < len(p.History) {
+			due = append(due, p)
+		}
+	}
+	return due, nil
+}
+
+func (pp memPositions) SetAnnounced(ctx context.Context, p *position.Position, n int) error {
+	pp[p.ID].Announced = n
+	return nil
+}
+
+type events map[int]notification.Kind
+
+func (ee events) Publish(ctx context.Context, e notification.Event) error {
+	ee[e.PositionID] = e.Kind
+	return nil
+}
+
+type hooks struct {
+	sent []webhook.Event
+	err  error
+}
+
+func (h *hooks) Publish(ctx context.Context, analystSlug string, ev webhook.Event, data any) error {
+	if h.err != nil {
+		return h.err
+	}
+	h.sent = append(h.sent, ev)
+	return nil
+}
+
+func TestAnnounceDueFromHistory(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		now  = time.Now()
+		sber = func(id int, status position.Status, kk ...position.ChangeKind) *position.Position {
+			p := &position.Position{
+				ID:          id,
+				Instrument:  &instrument.Instrument{Ticker: "SBER"},
+				Type:        position.Long,
+				Status:      status,
+				OpenPrice:   decimal.NewFromInt(100),
+				ClosedPrice: decimal.NewFromInt(120),
+				OpenDate:    now.AddDate(0, 0, -1),
+				Deadline:    now.AddDate(0, 1, 0),
+				TargetPrice: decimal.NewFromInt(150),
+			}
+			for _, k := range kk {
+				p.History = append(p.History, position.Change{Kind: k, At: now})
+			}
+			return p
+		}
+		pr = memPositions{
+			1: sber(1, position.Active, position.OpenedChange),
+			2: sber(2, position.Closed, position.OpenedChange, position.CloseChange),
+			3: sber(3, position.Active, position.OpenedChange, position.TargetChange),
+			// being published with its draft
+			4: sber(4, position.Active, position.OpenedChange),
+			// closed in a draft, dropped on publication
+			5: sber(5, position.Closed, position.OpenedChange, position.CloseChange),
+		}
+		ir = memIdeas{
+			{Slug: "active", Status: idea.Active, PositionIDs: []int{1, 2, 3}, Unannounced: true},
+			{Slug: "draft", Status: idea.Draft, PositionIDs: []int{4}},
+		}
+		nd = events{}
+		hs = &hooks{err: errors.New("endpoint is down")}
+		a  = New(slog.New(slog.NewTextHandler(io.Discard, nil)), nd, hs, ir, pr, fixedPrice{})
+	)
+	pr[2].Announced = 1
+	pr[3].Announced = 1
+
+	if err := a.AnnounceDue(ctx); err != nil {
+		t.Fatalf("couldn't announce: %v", err)
+	}
+	if !ir[0].Unannounced || pr[1].Announced != 0 || pr[5].Announced != 2 {
+		t.Fatalf("got idea unannounced %v, positions announced %d and %d, want only the private one marked while hooks fail",
+			ir[0].Unannounced, pr[1].Announced, pr[5].Announced)
+	}
+
+	hs.err = nil
+	if err := a.AnnounceDue(ctx); err != nil {
+		t.Fatalf("couldn't announce: %v", err)
+	}
+	if ir[0].Unannounced || hs.sent[0] != webhook.IdeaCreated {
+		t.Errorf("got idea unannounced %v and hooks %v, want the idea announced first", ir[0].Unannounced, hs.sent)
+	}
+	want := events{1: notification.PositionOpened, 2: notification.PositionClosed, 3: notification.PositionChanged}
+	for id, kind := range want {
+		if nd[id] != kind || pr[id].Announced != len(pr[id].History) {
+			t.Errorf("position %d: got %q with %d announced, want %q with all of them", id, nd[id], pr[id].Announced, kind)
+		}
+	}
+	if _, ok := nd[4]; ok || pr[4].Announced != 0 {
+		t.Errorf("got the position of the draft being published announced")
+	}
+	if _, ok := nd[5]; ok {
+		t.Errorf("got the position dropped from the draft announced")
+	}
+}
@@ -0,0 +1,222 @@
+package candles
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+const (
+	syncInterval = 5 * time.Minute
+	syncTimeout  = time.Minute
+	// freshness is how long a synced series is served without asking the provider
+	// for its latest candle.
+	freshness = time.Minute
+)
+
+type provider interface {
+	Find(ctx context.Context, ticker string) (*instrument.Instrument, error)
+	FindIndex(ctx context.Context, ticker string) (*instrument.Instrument, error)
+	Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error)
+	Candles(ctx context.Context, i *instrument.Instrument, interval chart.Interval, from, to time.Time) ([]chart.Candle, error)
+	Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error)
+}
+
+type candleRepo interface {
+	Coverage(ctx context.Context, uid string, interval chart.Interval) (chart.Coverage, error)
+	Add(ctx context.Context, uid string, interval chart.Interval, cc []chart.Candle, cov chart.Coverage) error
+	Candles(ctx context.Context, uid string, interval chart.Interval, from, to time.Time) ([]chart.Candle, error)
+}
+
+type activePositionFinder interface {
+	FindActive(ctx context.Context) ([]*position.Position, error)
+}
+
+// Store serves candles from the local store, fetching only what is missing from the
+// provider. It passes the other provider calls through, so it can stand in for it.
+type Store struct {
+	provider
+
+	log  *slog.Logger
+	repo candleRepo
+	pf   activePositionFinder
+	now  func() time.Time
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewStore(log *slog.Logger, p provider, repo candleRepo, pf activePositionFinder) *Store {
+	return &Store{
+		provider: p,
+		log:      log,
+		repo:     repo,
+		pf:       pf,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// seriesID identifies the instrument's series, instruments of fake providers have no uid.
+func seriesID(i *instrument.Instrument) string {
+	if i.Uid != "" {
+		return i.Uid
+	}
+	return i.Ticker
+}
+
+// GetCandles backfills the series if needed and serves it locally. If the provider
+// is down, whatever is stored is served.
+func (s *Store) GetCandles(ctx context.Context, wi *instrument.WithInterval) ([]chart.Candle, error) {
+	from, to := wi.Span(s.now())
+
+	syncErr := s.sync(ctx, wi.Instrument, wi.Interval, from, to)
+	if syncErr != nil {
+		s.log.Warn("couldn't sync candles, serving stored ones", "ticker", wi.Ticker, "interval", wi.Interval, "err", syncErr)
+	}
+
+	cc, err := s.repo.Candles(ctx, seriesID(wi.Instrument), wi.Interval, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get stored candles: %w", err)
+	}
+	if len(cc) == 0 && syncErr != nil {
+		return nil, syncErr
+	}
+
+	return cc, nil
+}
+
+// sync fetches the parts of [from, to] that aren't stored yet. The coverage only
+// ever grows at its ends, so the stored range stays contiguous.
+func (s *Store) sync(ctx context.Context, i *instrument.Instrument, interval chart.Interval, from, to time.Time) error {
+	cov, err := s.repo.Coverage(ctx, seriesID(i), interval)
+	if err != nil {
+		return fmt.Errorf("couldn't get coverage: %w", err)
+	}
+
+	now := s.now()
+	type span struct{ from, to time.Time }
+	var missing []span
+	switch {
+	case cov.IsZero():
+		missing = append(missing, span{from, to})
+	default:
+		if from.Before(cov.From) {
+			missing = append(missing, span{from, cov.From})
+		}
+		if to.After(cov.To) && now.Sub(cov.SyncedAt) >= freshness {
+			missing = append(missing, span{cov.To, to})
+		}
+	}
+
+	for _, m := range missing {
+		cc, err := s.provider.Candles(ctx, i, interval, m.from, m.to)
+		if err != nil {
+			return fmt.Errorf("couldn't fetch candles from %s to %s: %w", m.from, m.to, err)
+		}
+
+		// the current candle isn't finished, so the next sync refetches it
+		watermark := m.to
+		if current := interval.Start(now, timeext.Moscow); !m.to.Before(current) {
+			watermark = current
+		}
+
+		if cov.IsZero() {
+			cov = chart.Coverage{From: m.from, To: watermark}
+		} else {
+			cov.From = timeext.Min(cov.From, m.from)
+			if watermark.After(cov.To) {
+				cov.To = watermark
+			}
+		}
+		cov.SyncedAt = now
+
+		if err := s.repo.Add(ctx, seriesID(i), interval, cc, cov); err != nil {
+			return fmt.Errorf("couldn't store candles: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Start keeps the charts of active positions up to date until Shutdown is called.
+func (s *Store) Start() {
+	go func() {
+		defer close(s.done)
+
+		t := time.NewTicker(syncInterval)
+		defer t.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+			if err := s.SyncActive(ctx); err != nil {
+				s.log.Error("couldn't sync candles of active positions", "err", err)
+			}
+			cancel()
+
+			select {
+			case <-s.stop:
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the sync loop, waiting for the current sync to finish.
+func (s *Store) Shutdown(ctx context.Context) error {
+	s.once.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("candle store didn't stop: %w", ctx.Err())
+	}
+}
+
+// SyncActive syncs the series that the charts of active positions show.
+func (s *Store) SyncActive(ctx context.Context) error {
+	pp, err := s.pf.FindActive(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't find active positions: %w", err)
+	}
+
+	now := s.now()
+	seen := make(map[string]bool)
+	for _, p := range pp {
+		interval := chart.AutoInterval(p.OpenDate, timeext.Min(p.Deadline, now))
+		// spreads are charted from the candles of their legs
+		for _, i := range p.Instruments() {
+			key := seriesID(i) + " " + string(interval)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			wi, err := i.WithInterval(ctx, p.OpenDate, p.Deadline, interval)
+			if err != nil {
+				s.log.Warn("skipping position with a bad lifespan", "id", p.ID, "err", err)
+				continue
+			}
+
+			from, to := wi.Span(now)
+			if err := s.sync(ctx, i, interval, from, to); err != nil {
+				// one instrument failing shouldn't stop the rest from syncing
+				s.log.Warn("couldn't sync candles", "ticker", i.Ticker, "interval", interval, "err", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package candles
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type fetch struct{ from, to time.Time }
+
+type fakeProvider struct {
+	fetches []fetch
+	err     error
+}
+
+func (p *fakeProvider) Find(ctx context.Context, ticker string) (*instrument.Instrument, error) {
+	return nil, instrument.ErrNotFound
+}
+
+func (p *fakeProvider) FindIndex(ctx context.Context, ticker string) (*instrument.Instrument, error) {
+	return nil, instrument.ErrNotFound
+}
+
+func (p *fakeProvider) Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error) {
+	return decimal.Zero, nil
+}
+
+func (p *fakeProvider) Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error) {
+	return nil, nil
+}
+
+func (p *fakeProvider) Candles(ctx context.Context, i *instrument.Instrument, interval chart.Interval, from, to time.Time) ([]chart.Candle, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.fetches = append(p.fetches, fetch{from, to})
+
+	var cc []chart.Candle
+	for t := interval.Start(from, timeext.Moscow); !t.After(to); t = t.Add(interval.Duration()) {
+		cc = append(cc, chart.Candle{Time: t.Unix(), Open: 1, Close: 1, High: 1, Low: 1})
+	}
+	return cc, nil
+}
+
+type memRepo struct {
+	cov     chart.Coverage
+	candles []chart.Candle
+}
+
+func (r *memRepo) Coverage(ctx context.Context, uid string, interval chart.Interval) (chart.Coverage, error) {
+	return r.cov, nil
+}
+
+func (r *memRepo) Add(ctx context.Context, uid string, interval chart.Interval, cc []chart.Candle, cov chart.Coverage) error {
+	r.candles = chart.Merge(r.candles, cc)
+	r.cov = cov
+	return nil
+}
+
+func (r *memRepo) Candles(ctx context.Context, uid string, interval chart.Interval, from, to time.Time) ([]chart.Candle, error) {
+	var cc []chart.Candle
+	for _, c := range r.candles {
+		if c.Time >= from.Unix() && c.Time <= to.Unix() {
+			cc = append(cc, c)
+		}
+	}
+	return cc, nil
+}
+
+func TestStoreBackfillsIncrementally(t *testing.T) {
+	var (
+		p    = &fakeProvider{}
+		repo = &memRepo{}
+		s    = NewStore(slog.New(slog.NewTextHandler(io.Discard, nil)), p, repo, nil)
+		now  = time.Date(2024, 5, 15, 12, 30, 0, 0, timeext.Moscow)
+		wi   = &instrument.WithInterval{
+			Instrument: &instrument.Instrument{Ticker: "SBER"},
+			OpenedAt:   now.Add(-24 * time.Hour),
+			Deadline:   now.Add(24 * time.Hour),
+			Interval:   chart.Hour,
+		}
+		ctx = context.Background()
+	)
+	s.now = func() time.Time { return now }
+
+	if _, err := s.GetCandles(ctx, wi); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.fetches) != 1 {
+		t.Fatalf("got %d fetches on a cold store, want 1", len(p.fetches))
+	}
+	if want := now.Truncate(time.Hour); !repo.cov.To.Equal(want) {
+		t.Errorf("coverage ends at %s, want the start of the unfinished candle %s", repo.cov.To, want)
+	}
+
+	// still fresh, nothing is fetched
+	if _, err := s.GetCandles(ctx, wi); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.fetches) != 1 {
+		t.Fatalf("got %d fetches for a fresh series, want 1", len(p.fetches))
+	}
+
+	// later on only the tail is fetched
+	now = now.Add(2 * time.Hour)
+	if _, err := s.GetCandles(ctx, wi); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.fetches) != 2 {
+		t.Fatalf("got %d fetches, want 2", len(p.fetches))
+	}
+	if tail := p.fetches[1]; !tail.from.Equal(now.Add(-2*time.Hour).Truncate(time.Hour)) || !tail.to.Equal(now) {
+		t.Errorf("fetched %s - %s, want only the tail", tail.from, tail.to)
+	}
+
+	// the provider is down, stored candles are served
+	p.err = errors.New("upstream is down")
+	now = now.Add(time.Hour)
+	cc, err := s.GetCandles(ctx, wi)
+	if err != nil {
+		t.Fatalf("got %v, want stored candles", err)
+	}
+	if len(cc) == 0 {
+		t.Error("got no candles from the store")
+	}
+}
@@ -0,0 +1,143 @@
+package catalog
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	refreshInterval = 24 * time.Hour
+	// retryInterval is used instead while the catalog couldn't be loaded.
+	retryInterval  = 5 * time.Minute
+	refreshTimeout = time.Minute
+)
+
+type lister interface {
+	// Listings returns every instrument that positions can be opened on.
+	Listings(ctx context.Context) ([]instrument.Listing, error)
+}
+
+// Catalog keeps the provider's instrument list in memory for searches,
+// refreshing it daily.
+type Catalog struct {
+	log *slog.Logger
+	src lister
+
+	mu       sync.RWMutex
+	listings []instrument.Listing
+	byTicker map[string]instrument.Listing
+	loadedAt time.Time
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+func New(log *slog.Logger, src lister) *Catalog {
+	return &Catalog{
+		log:  log,
+		src:  src,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+func (c *Catalog) Refresh(ctx context.Context) error {
+	ll, err := c.src.Listings(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't list instruments: %w", err)
+	}
+
+	byTicker := make(map[string]instrument.Listing, len(ll))
+	for _, l := range ll {
+		byTicker[strings.ToUpper(l.Ticker)] = l
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listings, c.byTicker, c.loadedAt = ll, byTicker, time.Now()
+
+	return nil
+}
+
+func (c *Catalog) loaded() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.loadedAt.IsZero()
+}
+
+// ensure loads the catalog on the first use if the background refresh hasn't yet.
+func (c *Catalog) ensure(ctx context.Context) error {
+	if c.loaded() {
+		return nil
+	}
+	return c.Refresh(ctx)
+}
+
+func (c *Catalog) Search(ctx context.Context, query string, limit int) ([]instrument.Listing, error) {
+	if err := c.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return instrument.Search(c.listings, query, limit), nil
+}
+
+// Listing returns the catalog entry with exactly this ticker.
+func (c *Catalog) Listing(ctx context.Context, ticker string) (instrument.Listing, error) {
+	if err := c.ensure(ctx); err != nil {
+		return instrument.Listing{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	l, ok := c.byTicker[strings.ToUpper(strings.TrimSpace(ticker))]
+	if !ok {
+		return instrument.Listing{}, instrument.ErrNotFound
+	}
+	return l, nil
+}
+
+// Start refreshes the catalog daily until Shutdown is called.
+func (c *Catalog) Start() {
+	go func() {
+		defer close(c.done)
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+			err := c.Refresh(ctx)
+			cancel()
+
+			wait := refreshInterval
+			if err != nil {
+				c.log.Error("couldn't refresh instrument catalog", "err", err)
+				wait = retryInterval
+			}
+
+			select {
+			case <-c.stop:
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// Shutdown stops the refresh loop, waiting for the current refresh to finish.
+func (c *Catalog) Shutdown(ctx context.Context) error {
+	c.once.Do(func() { close(c.stop) })
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("instrument catalog didn't stop: %w", ctx.Err())
+	}
+}
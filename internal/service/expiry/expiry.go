@@ -0,0 +1,147 @@
+// Package expiry closes positions in futures and options once the contracts expire.
+package expiry
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+const (
+	checkInterval = 10 * time.Minute
+	checkTimeout  = time.Minute
+)
+
+type positionRepo interface {
+	FindActive(ctx context.Context) ([]*position.Position, error)
+	Update(ctx context.Context, p *position.Position) error
+}
+
+type marketProvider interface {
+	Find(ctx context.Context, ticker string) (*instrument.Instrument, error)
+	Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error)
+}
+
+type Expirer struct {
+	log   *slog.Logger
+	pr    positionRepo
+	mp    marketProvider
+	clock timeext.Clock
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New makes an expirer that checks the contracts' expiry against the clock.
+func New(log *slog.Logger, pr positionRepo, mp marketProvider, clock timeext.Clock) *Expirer {
+	return &Expirer{
+		log:   log,
+		pr:    pr,
+		mp:    mp,
+		clock: clock,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// ExpireDue closes the active positions whose contracts have expired.
+func (e *Expirer) ExpireDue(ctx context.Context) error {
+	pp, err := e.pr.FindActive(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't find active positions: %w", err)
+	}
+
+	now := e.clock.Now()
+	for _, p := range pp {
+		if !p.Instrument.Expired(now) {
+			continue
+		}
+
+		if err := e.expire(ctx, p); err != nil {
+			// one contract failing shouldn't keep the others open
+			e.log.Warn("couldn't expire position", "id", p.ID, "ticker", p.Instrument.Ticker, "err", err)
+		}
+	}
+
+	return nil
+}
+
+func (e *Expirer) expire(ctx context.Context, p *position.Position) error {
+	price, err := e.settlement(ctx, p.Instrument)
+	if err != nil {
+		return err
+	}
+
+	if err := p.Expire(ctx, e.pr, price); err != nil {
+		return fmt.Errorf("couldn't close position: %w", err)
+	}
+
+	e.log.Info("position expired", "id", p.ID, "ticker", p.Instrument.Ticker, "price", price)
+	return nil
+}
+
+// settlement is the last price of a future, and the payoff at the underlying's
+// last price for an option.
+func (e *Expirer) settlement(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error) {
+	if !i.IsOption() {
+		price, err := e.mp.Price(ctx, i)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("couldn't get settlement price: %w", err)
+		}
+		return price, nil
+	}
+
+	u, err := e.mp.Find(ctx, i.Underlying)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("couldn't find underlying %s: %w", i.Underlying, err)
+	}
+	price, err := e.mp.Price(ctx, u)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("couldn't get underlying price: %w", err)
+	}
+	return i.Payoff(price), nil
+}
+
+// Start checks for expired contracts until Shutdown is called.
+func (e *Expirer) Start() {
+	go func() {
+		defer close(e.done)
+
+		t := time.NewTicker(checkInterval)
+		defer t.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			if err := e.ExpireDue(ctx); err != nil {
+				e.log.Error("couldn't expire positions", "err", err)
+			}
+			cancel()
+
+			select {
+			case <-e.stop:
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the loop, waiting for the current check to finish.
+func (e *Expirer) Shutdown(ctx context.Context) error {
+	e.once.Do(func() { close(e.stop) })
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("expirer didn't stop: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,81 @@
+package expiry
+
+import (
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"changemedaddy/internal/service/market/fake"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type memPositions map[int]*position.Position
+
+func (pp memPositions) FindActive(ctx context.Context) ([]*position.Position, error) {
+	var active []*position.Position
+	for _, p := range pp {
+		if p.Status == position.Active {
+			active = append(active, p)
+		}
+	}
+	return active, nil
+}
+
+func (pp memPositions) Update(ctx context.Context, p *position.Position) error {
+	pp[p.ID] = p
+	return nil
+}
+
+func TestExpireDueByClock(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		m, clock = fake.NewTest(t, time.Date(2024, 5, 14, 12, 0, 0, 0, timeext.Moscow))
+		long     = func(id int, ticker string, price int64) *position.Position {
+			i, err := m.Find(ctx, ticker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return &position.Position{
+				ID:          id,
+				Instrument:  i,
+				Type:        position.Long,
+				Status:      position.Active,
+				OpenPrice:   decimal.NewFromInt(price),
+				TargetPrice: decimal.NewFromInt(price * 2),
+				OpenDate:    time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow),
+				Deadline:    time.Date(2024, 7, 1, 18, 50, 0, 0, timeext.Moscow),
+			}
+		}
+		pr = memPositions{
+			// a call on GAZP at 130 expiring at the close of 14.05
+			1: long(1, "GZ130CE4", 2),
+			2: long(2, "GZM4", 13000),
+		}
+		e = New(slog.New(slog.NewTextHandler(io.Discard, nil)), pr, m, clock)
+	)
+
+	if err := e.ExpireDue(ctx); err != nil || pr[1].Status != position.Active {
+		t.Fatalf("got %v and the option %s, want it active before its expiry", err, pr[1].Status)
+	}
+
+	clock.Advance(7 * time.Hour)
+	if err := e.ExpireDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// GAZP closed at 133.5
+	opt := pr[1]
+	if opt.Status != position.Closed || !opt.ClosedPrice.Equal(decimal.RequireFromString("3.5")) || !opt.Deadline.Equal(opt.Instrument.Expiry) {
+		t.Errorf("got the option %s at %s on %v, want it closed at the payoff of 3.5 at its expiry", opt.Status, opt.ClosedPrice, opt.Deadline)
+	}
+	// subscribers are told from the history
+	if h := opt.History; len(h) == 0 || h[len(h)-1].Kind != position.CloseChange {
+		t.Errorf("got history %v, want the close in it", h)
+	}
+	if pr[2].Status != position.Active {
+		t.Errorf("got the June future %s, want it active", pr[2].Status)
+	}
+}
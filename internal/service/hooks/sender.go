@@ -0,0 +1,219 @@
+package hooks
+
+import (
+	"bytes"
+	"changemedaddy/internal/domain/webhook"
+	"changemedaddy/internal/pkg/netguard"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	batchSize    = 50
+	sendTimeout  = 10 * time.Second
+	maxAttempts  = 8
+	baseBackoff  = 15 * time.Second
+)
+
+type repo interface {
+	EndpointsByAnalyst(ctx context.Context, analystSlug string) ([]*webhook.Endpoint, error)
+	FindEndpoint(ctx context.Context, id string) (*webhook.Endpoint, error)
+	Enqueue(ctx context.Context, dd []webhook.Delivery) error
+	Due(ctx context.Context, now time.Time, limit int) ([]webhook.Delivery, error)
+	UpdateDelivery(ctx context.Context, d webhook.Delivery) error
+}
+
+// Sender delivers analysts' webhook events in the background. Every delivery
+// is persisted first, retried with exponential backoff and kept as a log entry.
+type Sender struct {
+	log    *slog.Logger
+	repo   repo
+	client *http.Client
+	now    func() time.Time
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewSender(log *slog.Logger, r repo) *Sender {
+	return &Sender{
+		log:    log,
+		repo:   r,
+		client: netguard.Client(sendTimeout),
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Publish enqueues the event for every endpoint of the analyst.
+func (s *Sender) Publish(ctx context.Context, analystSlug string, ev webhook.Event, data any) error {
+	ee, err := s.repo.EndpointsByAnalyst(ctx, analystSlug)
+	if err != nil {
+		return fmt.Errorf("couldn't get endpoints: %w", err)
+	}
+	if len(ee) == 0 {
+		return nil
+	}
+
+	dd := make([]webhook.Delivery, 0, len(ee))
+	for _, e := range ee {
+		d, err := webhook.NewDelivery(e, ev, data, s.now())
+		if err != nil {
+			return err
+		}
+		dd = append(dd, d)
+	}
+
+	if err := s.repo.Enqueue(ctx, dd); err != nil {
+		return fmt.Errorf("couldn't enqueue webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// SendTest delivers a test event to the endpoint right away, so the analyst
+// sees the result in the log without waiting for the background loop.
+func (s *Sender) SendTest(ctx context.Context, e *webhook.Endpoint) (webhook.Delivery, error) {
+	d, err := webhook.NewDelivery(e, webhook.Test, map[string]string{"message": "test event from idea-x3"}, s.now())
+	if err != nil {
+		return webhook.Delivery{}, err
+	}
+
+	if err := s.repo.Enqueue(ctx, []webhook.Delivery{d}); err != nil {
+		return webhook.Delivery{}, fmt.Errorf("couldn't enqueue test delivery: %w", err)
+	}
+
+	s.deliver(ctx, &d, e)
+	if err := s.repo.UpdateDelivery(ctx, d); err != nil {
+		return d, fmt.Errorf("couldn't update test delivery: %w", err)
+	}
+
+	return d, nil
+}
+
+// Start runs the delivery loop until Shutdown is called.
+func (s *Sender) Start() {
+	go func() {
+		defer close(s.done)
+
+		t := time.NewTicker(pollInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-t.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 2*pollInterval)
+				if err := s.Flush(ctx); err != nil {
+					s.log.Error("couldn't flush webhook deliveries", "err", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Shutdown stops the delivery loop, waiting for the current batch to finish.
+func (s *Sender) Shutdown(ctx context.Context) error {
+	s.once.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("webhook sender didn't stop: %w", ctx.Err())
+	}
+}
+
+// Flush sends every delivery that is due now.
+func (s *Sender) Flush(ctx context.Context) error {
+	dd, err := s.repo.Due(ctx, s.now(), batchSize)
+	if err != nil {
+		return fmt.Errorf("couldn't get due webhook deliveries: %w", err)
+	}
+
+	for i := range dd {
+		d := &dd[i]
+
+		e, err := s.repo.FindEndpoint(ctx, d.EndpointID)
+		if errors.Is(err, webhook.ErrNotFound) {
+			d.Status = webhook.Failed
+			d.LastError = "endpoint was deleted"
+		} else if err != nil {
+			return fmt.Errorf("couldn't find endpoint %s: %w", d.EndpointID, err)
+		} else {
+			s.deliver(ctx, d, e)
+		}
+
+		if err := s.repo.UpdateDelivery(ctx, *d); err != nil {
+			return fmt.Errorf("couldn't update webhook delivery %s: %w", d.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func backoff(attempts int) time.Duration {
+	return baseBackoff << (attempts - 1)
+}
+
+func (s *Sender) post(ctx context.Context, d *webhook.Delivery, e *webhook.Endpoint) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return 0, fmt.Errorf("couldn't create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "idea-x3-webhooks")
+	req.Header.Set("X-Event", string(d.Event))
+	req.Header.Set("X-Delivery", d.ID)
+	now := s.now()
+	req.Header.Set("X-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("X-Signature", e.Sign(now, d.Body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (s *Sender) deliver(ctx context.Context, d *webhook.Delivery, e *webhook.Endpoint) {
+	d.Attempts++
+
+	code, err := s.post(ctx, d, e)
+	d.ResponseCode = code
+
+	switch {
+	case err == nil:
+		d.Status = webhook.Sent
+		d.LastError = ""
+	case d.Attempts >= maxAttempts || d.Event == webhook.Test:
+		d.Status = webhook.Failed
+		d.LastError = err.Error()
+	default:
+		d.NextAttemptAt = s.now().Add(backoff(d.Attempts))
+		d.LastError = err.Error()
+		s.log.Warn("webhook delivery failed, will retry", "delivery", d.ID, "url", d.URL, "attempt", d.Attempts, "err", err)
+	}
+}
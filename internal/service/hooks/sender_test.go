@@ -0,0 +1,132 @@
+package hooks
+
+import (
+	"changemedaddy/internal/domain/webhook"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type memRepo struct {
+	ee []*webhook.Endpoint
+	dd []webhook.Delivery
+}
+
+func (m *memRepo) EndpointsByAnalyst(_ context.Context, analystSlug string) ([]*webhook.Endpoint, error) {
+	var ee []*webhook.Endpoint
+	for _, e := range m.ee {
+		if e.AnalystSlug == analystSlug {
+			ee = append(ee, e)
+		}
+	}
+	return ee, nil
+}
+
+func (m *memRepo) FindEndpoint(_ context.Context, id string) (*webhook.Endpoint, error) {
+	for _, e := range m.ee {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return nil, webhook.ErrNotFound
+}
+
+func (m *memRepo) Enqueue(_ context.Context, dd []webhook.Delivery) error {
+	m.dd = append(m.dd, dd...)
+	return nil
+}
+
+func (m *memRepo) Due(_ context.Context, now time.Time, limit int) ([]webhook.Delivery, error) {
+	var due []webhook.Delivery
+	for _, d := range m.dd {
+		if d.Status == webhook.Pending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (m *memRepo) UpdateDelivery(_ context.Context, d webhook.Delivery) error {
+	for i := range m.dd {
+		if m.dd[i].ID == d.ID {
+			m.dd[i] = d
+		}
+	}
+	return nil
+}
+
+func TestSenderSignsAndRetries(t *testing.T) {
+	var (
+		fail = true
+		e    = &webhook.Endpoint{ID: "e1", AnalystSlug: "mk", Secret: "s3cr3t"}
+		now  = time.Date(2024, 5, 5, 12, 0, 0, 0, time.UTC)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !e.Verify(r.Header.Get("X-Timestamp"), body, r.Header.Get("X-Signature"), now) {
+			t.Errorf("wrong signature %q at %q", r.Header.Get("X-Signature"), r.Header.Get("X-Timestamp"))
+		}
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	e.URL = srv.URL
+
+	var (
+		ctx  = context.Background()
+		repo = &memRepo{ee: []*webhook.Endpoint{e}}
+		s    = NewSender(slog.New(slog.NewTextHandler(io.Discard, nil)), repo)
+	)
+	s.now = func() time.Time { return now }
+	// the test server is on loopback, which the real client refuses
+	s.client = srv.Client()
+
+	if err := s.Publish(ctx, "mk", webhook.IdeaCreated, map[string]string{"name": "Сбер"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := repo.dd[0]; d.Status != webhook.Pending || d.ResponseCode != http.StatusBadGateway || d.Attempts != 1 {
+		t.Fatalf("failed delivery must be retried later, got %+v", d)
+	}
+
+	fail = false
+	now = now.Add(backoff(1))
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := repo.dd[0]; d.Status != webhook.Sent || d.ResponseCode != http.StatusNoContent {
+		t.Fatalf("delivery wasn't sent after backoff: %+v", d)
+	}
+}
+
+func TestVerifyRejectsReplays(t *testing.T) {
+	var (
+		e    = &webhook.Endpoint{Secret: "s3cr3t"}
+		body = []byte(`{"event":"idea.created"}`)
+		at   = time.Date(2024, 5, 5, 12, 0, 0, 0, time.UTC)
+		ts   = strconv.FormatInt(at.Unix(), 10)
+		sig  = e.Sign(at, body)
+	)
+
+	if !e.Verify(ts, body, sig, at.Add(time.Minute)) {
+		t.Error("want a fresh delivery verified")
+	}
+	if e.Verify(ts, body, sig, at.Add(webhook.SignatureTolerance+time.Second)) {
+		t.Error("want a replayed delivery rejected")
+	}
+	// moving the timestamp forward breaks the signature
+	if e.Verify(strconv.FormatInt(at.Add(time.Hour).Unix(), 10), body, sig, at.Add(time.Hour)) {
+		t.Error("want a delivery with a forged timestamp rejected")
+	}
+}
@@ -0,0 +1,159 @@
+// Package fake is a scriptable market provider for local development and tests.
+package fake
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type service struct {
+	clock       timeext.Clock
+	instruments map[string]*instrument.Instrument
+	listings    []instrument.Listing
+	dividends   map[string][]instrument.Dividend
+	sources     map[string]source
+}
+
+// New builds a market from fixtures. Prices and candles are given as of the
+// clock's time, so a manual clock can move the market forward in tests.
+func New(f Fixtures, clock timeext.Clock) (*service, error) {
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("bad fixtures: %w", err)
+	}
+
+	s := &service{
+		clock:       clock,
+		instruments: make(map[string]*instrument.Instrument, len(f.Instruments)),
+		sources:     make(map[string]source, len(f.Instruments)),
+		dividends:   make(map[string][]instrument.Dividend),
+	}
+	for _, fi := range f.Instruments {
+		ticker := strings.ToUpper(fi.Ticker)
+		l := fi.listing()
+		s.instruments[ticker] = &l.Instrument
+		if l.Type != instrument.Index {
+			s.listings = append(s.listings, l)
+		}
+		s.dividends[ticker] = fi.Dividends
+
+		switch {
+		case fi.Walk != nil:
+			s.sources[ticker] = newWalkSource(*fi.Walk, f.Seed, ticker)
+		case len(fi.Prices) > 0:
+			s.sources[ticker] = newPathSource(fi.Prices)
+		default:
+			s.sources[ticker] = &seriesSource{series: fi.series}
+		}
+	}
+
+	return s, nil
+}
+
+func (s *service) Find(ctx context.Context, ticker string) (*instrument.Instrument, error) {
+	return s.find(ticker, func(t instrument.Type) bool { return t != instrument.Index })
+}
+
+func (s *service) FindIndex(ctx context.Context, ticker string) (*instrument.Instrument, error) {
+	return s.find(ticker, func(t instrument.Type) bool { return t == instrument.Index })
+}
+
+func (s *service) find(ticker string, ok func(instrument.Type) bool) (*instrument.Instrument, error) {
+	i, found := s.instruments[strings.ToUpper(ticker)]
+	if !found || !ok(i.Type) {
+		return nil, instrument.ErrNotFound
+	}
+
+	cp := *i
+	return &cp, nil
+}
+
+func (s *service) Listings(ctx context.Context) ([]instrument.Listing, error) {
+	return append([]instrument.Listing(nil), s.listings...), nil
+}
+
+func (s *service) Coupons(ctx context.Context, i *instrument.Instrument) ([]instrument.Coupon, error) {
+	in, ok := s.instruments[strings.ToUpper(i.Ticker)]
+	if !ok {
+		return nil, instrument.ErrNotFound
+	}
+	return append([]instrument.Coupon(nil), in.Coupons...), nil
+}
+
+// Dividends never returns dividends from the future of the clock, as if they
+// weren't declared yet.
+func (s *service) Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error) {
+	to = timeext.Min(to, s.clock.Now())
+
+	var dd []instrument.Dividend
+	for _, d := range s.dividends[strings.ToUpper(i.Ticker)] {
+		if !d.ExDate.Before(from) && !d.ExDate.After(to) {
+			dd = append(dd, d)
+		}
+	}
+	return dd, nil
+}
+
+func (s *service) source(i *instrument.Instrument) (source, error) {
+	src, ok := s.sources[strings.ToUpper(i.Ticker)]
+	if !ok {
+		return nil, instrument.ErrNotFound
+	}
+	return src, nil
+}
+
+func (s *service) Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error) {
+	src, err := s.source(i)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	p, ok := src.price(s.clock.Now())
+	if !ok {
+		return decimal.Zero, fmt.Errorf("no price for %s at %s", i.Ticker, s.clock.Now())
+	}
+
+	// quotes are always whole ticks, as on the exchange
+	return s.instruments[strings.ToUpper(i.Ticker)].RoundToTick(decimal.NewFromFloat(p)), nil
+}
+
+func (s *service) GetCandles(ctx context.Context, i *instrument.WithInterval) ([]chart.Candle, error) {
+	from, to := i.Span(s.clock.Now())
+	return s.Candles(ctx, i.Instrument, i.Interval, from, to)
+}
+
+// Candles never returns candles from the future of the clock.
+func (s *service) Candles(ctx context.Context, i *instrument.Instrument, interval chart.Interval, from, to time.Time) ([]chart.Candle, error) {
+	if !interval.Valid() {
+		return nil, fmt.Errorf("%w: %q", chart.ErrInterval, interval)
+	}
+
+	src, err := s.source(i)
+	if err != nil {
+		return nil, err
+	}
+
+	cc, err := src.candles(interval, from, timeext.Min(to, s.clock.Now()))
+	if err != nil {
+		return nil, err
+	}
+	if cc == nil {
+		cc = []chart.Candle{}
+	}
+
+	return cc, nil
+}
+
+func (s *service) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *service) Shutdown(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,154 @@
+package fake
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+func find(t *testing.T, s *service, ticker string) *instrument.Instrument {
+	t.Helper()
+
+	i, err := s.Find(context.Background(), ticker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+func TestWalkIsDeterministic(t *testing.T) {
+	var (
+		now  = time.Date(2024, 5, 15, 12, 0, 0, 0, timeext.Moscow)
+		from = now.Add(-72 * time.Hour)
+		ctx  = context.Background()
+	)
+	s1, _ := NewTest(t, now)
+	s2, _ := NewTest(t, now)
+
+	c1, err := s1.Candles(ctx, find(t, s1, "SBER"), chart.Hour, from, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, _ := s2.Candles(ctx, find(t, s2, "SBER"), chart.Hour, from, now)
+	// 72 full hours and the one that has just started
+	if len(c1) != 73 || !reflect.DeepEqual(c1, c2) {
+		t.Fatalf("got %d and %d candles, want the same 73", len(c1), len(c2))
+	}
+
+	// coarser candles are made of the same path
+	minutes, _ := s1.Candles(ctx, find(t, s1, "SBER"), chart.Minute, from, now)
+	resampled, err := chart.Resample(minutes, chart.Hour, timeext.Moscow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resampled, c1) {
+		t.Error("hourly candles don't match the resampled minute ones")
+	}
+
+	f, _ := LoadFixtures("testdata/fixtures.json")
+	f.Seed++
+	s3, _ := New(f, timeext.NewManualClock(now))
+	c3, _ := s3.Candles(ctx, find(t, s3, "SBER"), chart.Hour, from, now)
+	if reflect.DeepEqual(c1, c3) {
+		t.Error("a different seed gave the same walk")
+	}
+}
+
+func TestCandlesStopAtClock(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, timeext.Moscow)
+	s, _ := NewTest(t, now)
+
+	cc, err := s.Candles(context.Background(), find(t, s, "SBER"), chart.Hour, now.Add(-time.Hour), now.Add(48*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := cc[len(cc)-1].Time; last > now.Unix() {
+		t.Errorf("got a candle at %s, after the clock", time.Unix(last, 0))
+	}
+}
+
+func TestTargetHitAlongPricePath(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		s, clock = NewTest(t, time.Date(2024, 5, 2, 12, 0, 0, 0, timeext.Moscow))
+		p        = &position.Position{
+			Instrument:  find(t, s, "MGNT"),
+			Type:        position.Long,
+			Status:      position.Active,
+			OpenPrice:   decimal.NewFromInt(8000),
+			TargetPrice: decimal.NewFromInt(8500),
+		}
+	)
+
+	wp, err := p.WithProfit(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wp.ProfitP.IsZero() || wp.Instrument.Price.GreaterThanOrEqual(p.TargetPrice) {
+		t.Fatalf("got profit %s at %s, want 0 and the target not hit", wp.ProfitP, wp.Instrument.Price)
+	}
+
+	clock.Advance(10 * 24 * time.Hour)
+	wp, err = p.WithProfit(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wp.ProfitP.Equal(decimal.NewFromInt(10)) || wp.Instrument.Price.LessThan(p.TargetPrice) {
+		t.Errorf("got profit %s at %s, want 10%% and the target hit", wp.ProfitP, wp.Instrument.Price)
+	}
+}
+
+func TestCandlesFromCSV(t *testing.T) {
+	now := time.Date(2024, 5, 15, 0, 0, 0, 0, timeext.Moscow)
+	s, _ := NewTest(t, now)
+	gazp := find(t, s, "GAZP")
+
+	price, err := s.Price(context.Background(), gazp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !price.Equal(decimal.NewFromFloat(133.5)) {
+		t.Errorf("got price %s, want the last close 133.5", price)
+	}
+
+	cc, err := s.Candles(context.Background(), gazp, chart.Day, now.Add(-72*time.Hour), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc) == 0 || cc[0].Open != 130 || cc[0].High < 133 {
+		t.Errorf("got %+v, want the CSV candles folded into days", cc)
+	}
+}
+
+func TestIndicesOutOfFind(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		s, _ = NewTest(t, time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow))
+	)
+	if _, err := s.Find(ctx, "IMOEX"); !errors.Is(err, instrument.ErrNotFound) {
+		t.Errorf("got %v, want indices out of Find", err)
+	}
+	if _, err := s.FindIndex(ctx, "IMOEX"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBadFixtures(t *testing.T) {
+	f := Fixtures{Instruments: []Instrument{
+		{Ticker: "A"},
+		{Ticker: "B", Walk: &Walk{Start: 1}, Prices: []PricePoint{{Price: 1}}},
+	}}
+
+	err := f.validate()
+	if !errors.Is(err, errNoSource) || !errors.Is(err, errManySources) {
+		t.Errorf("got %v, want both fixture errors", err)
+	}
+}
@@ -0,0 +1,290 @@
+package fake
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+var (
+	errNoSource    = errors.New("instrument has neither a walk, nor prices, nor candles")
+	errManySources = errors.New("instrument has more than one of walk, prices and candles")
+	errBondTerms   = errors.New("bond needs a nominal, a maturity and a coupon period")
+	// errDerivativeTerms also covers options without an underlying or a kind.
+	errDerivativeTerms = errors.New("derivative needs an expiry")
+)
+
+// Fixtures describe the instruments of the fake market and how their prices move.
+type Fixtures struct {
+	// Seed makes random walks reproducible, the same seed gives the same charts.
+	Seed        uint64       `json:"seed"`
+	Instruments []Instrument `json:"instruments"`
+}
+
+// Instrument has exactly one price source: a seeded random walk, a price path or
+// a candle series loaded from a CSV file.
+type Instrument struct {
+	Ticker string `json:"ticker"`
+	Name   string `json:"name"`
+	Uid    string `json:"uid"`
+	Isin   string `json:"isin,omitempty"`
+
+	// Listing details default to a rouble share on the main board, lot 1, tick 0.01.
+	Type     instrument.Type `json:"type,omitempty"`
+	Currency string          `json:"currency,omitempty"`
+	Lot      int             `json:"lot,omitempty"`
+	Tick     decimal.Decimal `json:"tick,omitempty"`
+	Board    string          `json:"board,omitempty"`
+
+	// Bonds only, their walks and price paths are in percent of the nominal.
+	Nominal  decimal.Decimal `json:"nominal,omitempty"`
+	Maturity time.Time       `json:"maturity,omitempty"`
+	Coupon   *CouponPlan     `json:"coupon,omitempty"`
+
+	// Futures and options only, prices are in points.
+	Expiry     time.Time       `json:"expiry,omitempty"`
+	Underlying string          `json:"underlying,omitempty"`
+	PointValue decimal.Decimal `json:"point_value,omitempty"`
+	Margin     decimal.Decimal `json:"margin,omitempty"`
+
+	// Options only.
+	OptionKind instrument.OptionKind `json:"option_kind,omitempty"`
+	Strike     decimal.Decimal       `json:"strike,omitempty"`
+
+	Dividends []instrument.Dividend `json:"dividends,omitempty"`
+
+	Walk    *Walk        `json:"walk,omitempty"`
+	Prices  []PricePoint `json:"prices,omitempty"`
+	Candles string       `json:"candles,omitempty"`
+
+	series []chart.Candle
+}
+
+func (i Instrument) listing() instrument.Listing {
+	l := instrument.Listing{
+		Instrument: instrument.Instrument{
+			Name:     i.Name,
+			Ticker:   strings.ToUpper(i.Ticker),
+			Uid:      i.Uid,
+			Type:     i.Type,
+			Currency: i.Currency,
+			Lot:      i.Lot,
+			Tick:     i.Tick,
+			Exchange: "MOEX",
+			Board:    i.Board,
+			Status:   instrument.Trading,
+		},
+		Isin: i.Isin,
+	}
+	if l.Type == instrument.Bond {
+		l.Nominal, l.Maturity = i.Nominal, i.Maturity
+		if i.Coupon != nil {
+			l.Coupons = i.Coupon.schedule(i.Maturity)
+		}
+		if i.Board == "" {
+			l.Board = "TQCB"
+		}
+	}
+	if l.IsDerivative() {
+		l.Expiry, l.Underlying, l.PointValue, l.Margin = i.Expiry, strings.ToUpper(i.Underlying), i.PointValue, i.Margin
+		l.OptionKind, l.Strike = i.OptionKind, i.Strike
+		if i.Board == "" {
+			l.Board = "SPBFUT"
+			if l.IsOption() {
+				l.Board = "SPBOPT"
+			}
+		}
+	}
+	if l.Type == "" {
+		l.Type = instrument.Share
+	}
+	if l.Currency == "" {
+		l.Currency = "rub"
+	}
+	if l.Lot == 0 {
+		l.Lot = 1
+	}
+	if !l.Tick.IsPositive() {
+		l.Tick = decimal.New(1, -2)
+	}
+	if l.Board == "" {
+		l.Board = "TQBR"
+	}
+	return l
+}
+
+// CouponPlan pays the same coupon every few months from the first coupon until maturity.
+type CouponPlan struct {
+	First       time.Time       `json:"first"`
+	EveryMonths int             `json:"every_months"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+func (p CouponPlan) schedule(maturity time.Time) []instrument.Coupon {
+	var cc []instrument.Coupon
+	for n := 0; ; n++ {
+		at := p.First.AddDate(0, n*p.EveryMonths, 0)
+		if at.After(maturity) {
+			return cc
+		}
+		cc = append(cc, instrument.Coupon{Date: at, Amount: p.Amount})
+	}
+}
+
+type Walk struct {
+	Start float64 `json:"start"`
+	// Volatility is the standard deviation of daily log returns, e.g. 0.02.
+	Volatility float64 `json:"volatility"`
+	// Drift is the mean daily log return.
+	Drift float64 `json:"drift"`
+}
+
+// PricePoint sets the price from At until the next point.
+type PricePoint struct {
+	At    time.Time `json:"at"`
+	Price float64   `json:"price"`
+}
+
+// LoadFixtures reads fixtures from a JSON file. Candle CSV files are resolved
+// relative to it, inside its directory, and have a time,open,high,low,close
+// header, time being either RFC 3339 or a unix timestamp.
+func LoadFixtures(file string) (Fixtures, error) {
+	return loadFixtures(os.DirFS(filepath.Dir(file)), filepath.Base(file))
+}
+
+func loadFixtures(fsys fs.FS, name string) (Fixtures, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("couldn't read fixtures: %w", err)
+	}
+
+	var f Fixtures
+	if err := json.Unmarshal(b, &f); err != nil {
+		return Fixtures{}, fmt.Errorf("couldn't parse fixtures: %w", err)
+	}
+
+	for n := range f.Instruments {
+		i := &f.Instruments[n]
+		if i.Candles == "" {
+			continue
+		}
+
+		i.series, err = loadCandles(fsys, path.Join(path.Dir(name), i.Candles))
+		if err != nil {
+			return Fixtures{}, fmt.Errorf("couldn't load candles of %s: %w", i.Ticker, err)
+		}
+	}
+
+	if err := f.validate(); err != nil {
+		return Fixtures{}, err
+	}
+
+	return f, nil
+}
+
+func (f Fixtures) validate() error {
+	var err error
+	for _, i := range f.Instruments {
+		sources := 0
+		if i.Walk != nil {
+			sources++
+		}
+		if len(i.Prices) > 0 {
+			sources++
+		}
+		if i.Candles != "" {
+			sources++
+		}
+
+		switch {
+		case i.Ticker == "":
+			err = errors.Join(err, errors.New("instrument without a ticker"))
+		case sources == 0:
+			err = errors.Join(err, fmt.Errorf("%s: %w", i.Ticker, errNoSource))
+		case sources > 1:
+			err = errors.Join(err, fmt.Errorf("%s: %w", i.Ticker, errManySources))
+		case i.Type == instrument.Bond && (!i.Nominal.IsPositive() || i.Maturity.IsZero() || i.Coupon != nil && i.Coupon.EveryMonths <= 0):
+			err = errors.Join(err, fmt.Errorf("%s: %w", i.Ticker, errBondTerms))
+		case (i.Type == instrument.Future || i.Type == instrument.Option) && i.Expiry.IsZero(),
+			i.Type == instrument.Option && (i.Underlying == "" || i.OptionKind != instrument.Call && i.OptionKind != instrument.Put):
+			err = errors.Join(err, fmt.Errorf("%s: %w", i.Ticker, errDerivativeTerms))
+		}
+	}
+
+	return err
+}
+
+func loadCandles(fsys fs.FS, name string) ([]chart.Candle, error) {
+	fd, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	return parseCandles(fd)
+}
+
+func parseCandles(r io.Reader) ([]chart.Candle, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("empty candle file")
+	}
+
+	col := make(map[string]int)
+	for n, name := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(name))] = n
+	}
+	for _, name := range []string{"time", "open", "high", "low", "close"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("no %q column", name)
+		}
+	}
+
+	cc := make([]chart.Candle, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		var (
+			c    chart.Candle
+			perr error
+			num  = func(name string) float64 {
+				v, err := strconv.ParseFloat(strings.TrimSpace(row[col[name]]), 64)
+				perr = errors.Join(perr, err)
+				return v
+			}
+		)
+
+		ts := strings.TrimSpace(row[col["time"]])
+		if unix, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			c.Time = unix
+		} else if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			c.Time = t.Unix()
+		} else {
+			perr = errors.Join(perr, fmt.Errorf("bad time %q", ts))
+		}
+		c.Open, c.High, c.Low, c.Close = num("open"), num("high"), num("low"), num("close")
+
+		if perr != nil {
+			return nil, fmt.Errorf("line %d: %w", n+2, perr)
+		}
+		cc = append(cc, c)
+	}
+
+	sort.Slice(cc, func(i, j int) bool { return cc[i].Time < cc[j].Time })
+	return cc, nil
+}
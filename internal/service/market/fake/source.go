@@ -0,0 +1,199 @@
+package fake
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/pkg/timeext"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxSpan bounds how much minute data a single request may generate.
+const maxSpan = 5 * 365 * 24 * time.Hour
+
+type source interface {
+	// price is the last price at t, false if there's no price yet.
+	price(t time.Time) (float64, bool)
+	candles(interval chart.Interval, from, to time.Time) ([]chart.Candle, error)
+}
+
+func round(p float64) float64 {
+	return math.Round(p*100) / 100
+}
+
+// fromMinutes aggregates a per-minute price function into candles of the interval.
+func fromMinutes(interval chart.Interval, from, to time.Time, price func(t time.Time) (float64, bool)) []chart.Candle {
+	if to.Sub(from) > maxSpan {
+		from = to.Add(-maxSpan)
+	}
+
+	var (
+		cc     []chart.Candle
+		bucket = interval.Start(from, timeext.Moscow)
+		next   = interval.Next(from, timeext.Moscow)
+		prev   float64
+		cur    *chart.Candle
+	)
+	if p, ok := price(chart.Minute.Start(from, timeext.Moscow).Add(-time.Minute)); ok {
+		prev = p
+	}
+
+	for m := chart.Minute.Start(from, timeext.Moscow); !m.After(to); m = m.Add(time.Minute) {
+		p, ok := price(m)
+		if !ok {
+			continue
+		}
+		if prev == 0 {
+			prev = p
+		}
+
+		if !m.Before(next) {
+			bucket, next = interval.Start(m, timeext.Moscow), interval.Next(m, timeext.Moscow)
+			cur = nil
+		}
+		if cur == nil {
+			cc = append(cc, chart.Candle{Time: bucket.Unix(), Open: prev, High: max(prev, p), Low: min(prev, p), Close: p})
+			cur = &cc[len(cc)-1]
+		} else {
+			cur.Close = p
+			cur.High = max(cur.High, p)
+			cur.Low = min(cur.Low, p)
+		}
+
+		prev = p
+	}
+
+	return cc
+}
+
+// walkSource is a geometric random walk that is a pure function of the seed and
+// time: daily closes follow a seeded walk from the epoch, and each day's minutes
+// bridge the previous close to the day's close.
+type walkSource struct {
+	w    Walk
+	seed uint64
+
+	mu      sync.Mutex
+	closes  []float64 // log closes by days since epoch
+	lastDay int
+	minutes []float64
+}
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const minutesPerDay = 24 * 60
+
+func newWalkSource(w Walk, seed uint64, ticker string) *walkSource {
+	h := fnv.New64a()
+	h.Write([]byte(ticker))
+
+	return &walkSource{
+		w:       w,
+		seed:    seed ^ h.Sum64(),
+		closes:  []float64{math.Log(w.Start)},
+		lastDay: -1,
+	}
+}
+
+func (s *walkSource) rng(day int) *rand.Rand {
+	return rand.New(rand.NewPCG(s.seed, uint64(day)))
+}
+
+func (s *walkSource) logClose(day int) float64 {
+	for len(s.closes) <= day {
+		n := len(s.closes)
+		s.closes = append(s.closes, s.closes[n-1]+s.w.Drift+s.w.Volatility*s.rng(n).NormFloat64())
+	}
+	return s.closes[day]
+}
+
+func (s *walkSource) dayMinutes(day int) []float64 {
+	if day == s.lastDay {
+		return s.minutes
+	}
+
+	var (
+		open  = s.logClose(day - 1)
+		close = s.logClose(day)
+		r     = s.rng(-day - 1) // a stream of its own, not the one of daily closes
+		sigma = s.w.Volatility / math.Sqrt(minutesPerDay)
+		path  = make([]float64, minutesPerDay)
+		sum   float64
+	)
+	for m := range path {
+		sum += sigma * r.NormFloat64()
+		path[m] = sum
+	}
+	for m := range path {
+		frac := float64(m+1) / minutesPerDay
+		path[m] = round(math.Exp(open + path[m] - frac*(sum-(close-open))))
+	}
+
+	s.lastDay, s.minutes = day, path
+	return path
+}
+
+func (s *walkSource) price(t time.Time) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := t.Sub(epoch)
+	if since < 24*time.Hour {
+		return round(s.w.Start), true
+	}
+
+	day := int(since / (24 * time.Hour))
+	minute := int(since % (24 * time.Hour) / time.Minute)
+	return s.dayMinutes(day)[minute], true
+}
+
+func (s *walkSource) candles(interval chart.Interval, from, to time.Time) ([]chart.Candle, error) {
+	return fromMinutes(interval, from, to, s.price), nil
+}
+
+// pathSource steps between the given prices, which is handy to script a target
+// being hit or missed at a known time.
+type pathSource struct {
+	points []PricePoint
+}
+
+func newPathSource(pp []PricePoint) *pathSource {
+	sorted := make([]PricePoint, len(pp))
+	copy(sorted, pp)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].At.Before(sorted[j].At) })
+	return &pathSource{points: sorted}
+}
+
+func (s *pathSource) price(t time.Time) (float64, bool) {
+	n := sort.Search(len(s.points), func(i int) bool { return s.points[i].At.After(t) })
+	if n == 0 {
+		return 0, false
+	}
+	return s.points[n-1].Price, true
+}
+
+func (s *pathSource) candles(interval chart.Interval, from, to time.Time) ([]chart.Candle, error) {
+	return fromMinutes(interval, from, to, s.price), nil
+}
+
+// seriesSource serves recorded candles, resampled to coarser intervals on request.
+type seriesSource struct {
+	series []chart.Candle
+}
+
+func (s *seriesSource) price(t time.Time) (float64, bool) {
+	n := sort.Search(len(s.series), func(i int) bool { return s.series[i].Time > t.Unix() })
+	if n == 0 {
+		return 0, false
+	}
+	return s.series[n-1].Close, true
+}
+
+func (s *seriesSource) candles(interval chart.Interval, from, to time.Time) ([]chart.Candle, error) {
+	lo := sort.Search(len(s.series), func(i int) bool { return s.series[i].Time >= from.Unix() })
+	hi := sort.Search(len(s.series), func(i int) bool { return s.series[i].Time > to.Unix() })
+	return chart.Resample(s.series[lo:hi], interval, timeext.Moscow)
+}
@@ -0,0 +1,29 @@
+package fake
+
+import (
+	"changemedaddy/internal/pkg/timeext"
+	"embed"
+	"testing"
+	"time"
+)
+
+//go:embed testdata
+var testdata embed.FS
+
+// NewTest is the fake market on the fixtures in testdata, as of now, for the
+// tests of the packages that price positions.
+func NewTest(t testing.TB, now time.Time) (*service, *timeext.ManualClock) {
+	t.Helper()
+
+	f, err := loadFixtures(testdata, "testdata/fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := timeext.NewManualClock(now)
+	s, err := New(f, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, clock
+}
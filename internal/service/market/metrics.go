@@ -0,0 +1,95 @@
+package market
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/pkg/metrics"
+	"context"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+var (
+	providerCalls = metrics.NewCounterVec(
+		"market_provider_calls_total",
+		"Calls to the market provider by method and outcome.",
+		"method", "status",
+	)
+	providerDuration = metrics.NewHistogramVec(
+		"market_provider_call_duration_seconds",
+		"Latency of market provider calls by method.",
+		metrics.DefBuckets,
+		"method",
+	)
+)
+
+type provider interface {
+	Find(ctx context.Context, ticker string) (*instrument.Instrument, error)
+	FindIndex(ctx context.Context, ticker string) (*instrument.Instrument, error)
+	Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error)
+	GetCandles(ctx context.Context, i *instrument.WithInterval) ([]chart.Candle, error)
+	Candles(ctx context.Context, i *instrument.Instrument, interval chart.Interval, from, to time.Time) ([]chart.Candle, error)
+	Listings(ctx context.Context) ([]instrument.Listing, error)
+	Coupons(ctx context.Context, i *instrument.Instrument) ([]instrument.Coupon, error)
+	Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error)
+}
+
+type instrumented struct {
+	p provider
+}
+
+// WithMetrics wraps a provider so that every call is counted and timed.
+func WithMetrics(p provider) *instrumented {
+	return &instrumented{p: p}
+}
+
+func observe(method string, start time.Time, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+
+	providerCalls.Inc(method, status)
+	providerDuration.Observe(time.Since(start).Seconds(), method)
+}
+
+func (m *instrumented) Find(ctx context.Context, ticker string) (i *instrument.Instrument, err error) {
+	defer func(start time.Time) { observe("find", start, err) }(time.Now())
+	return m.p.Find(ctx, ticker)
+}
+
+func (m *instrumented) FindIndex(ctx context.Context, ticker string) (i *instrument.Instrument, err error) {
+	defer func(start time.Time) { observe("find_index", start, err) }(time.Now())
+	return m.p.FindIndex(ctx, ticker)
+}
+
+func (m *instrumented) Price(ctx context.Context, i *instrument.Instrument) (p decimal.Decimal, err error) {
+	defer func(start time.Time) { observe("price", start, err) }(time.Now())
+	return m.p.Price(ctx, i)
+}
+
+func (m *instrumented) GetCandles(ctx context.Context, i *instrument.WithInterval) (cc []chart.Candle, err error) {
+	defer func(start time.Time) { observe("get_candles", start, err) }(time.Now())
+	return m.p.GetCandles(ctx, i)
+}
+
+func (m *instrumented) Candles(ctx context.Context, i *instrument.Instrument, interval chart.Interval, from, to time.Time) (cc []chart.Candle, err error) {
+	defer func(start time.Time) { observe("candles", start, err) }(time.Now())
+	return m.p.Candles(ctx, i, interval, from, to)
+}
+
+func (m *instrumented) Listings(ctx context.Context) (ll []instrument.Listing, err error) {
+	defer func(start time.Time) { observe("listings", start, err) }(time.Now())
+	return m.p.Listings(ctx)
+}
+
+func (m *instrumented) Coupons(ctx context.Context, i *instrument.Instrument) (cc []instrument.Coupon, err error) {
+	defer func(start time.Time) { observe("coupons", start, err) }(time.Now())
+	return m.p.Coupons(ctx, i)
+}
+
+func (m *instrumented) Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) (dd []instrument.Dividend, err error) {
+	defer func(start time.Time) { observe("dividends", start, err) }(time.Now())
+	return m.p.Dividends(ctx, i, from, to)
+}
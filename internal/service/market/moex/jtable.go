@@ -4,7 +4,9 @@ import (
 	"changemedaddy/internal/domain/instrument"
 	"errors"
 	"fmt"
+	"strconv"
 
+	"github.com/greatcloak/decimal"
 	"github.com/tidwall/gjson"
 )
 
@@ -73,13 +75,51 @@ func parseTable(json string) (*table, error) {
 	return tab, nil
 }
 
+// col returns the value of the column in the row, or "" if the table doesn't have it.
+func (t *table) col(name string, row int) string {
+	vals := t.ColVals[name]
+	if row >= len(vals) {
+		return ""
+	}
+	return vals[row]
+}
+
+// moexCurrencies maps ISS currency ids to ISO codes, SUR being the pre-1998 rouble code.
+var moexCurrencies = map[string]string{
+	"SUR": "rub",
+	"RUB": "rub",
+	"USD": "usd",
+	"EUR": "eur",
+	"CNY": "cny",
+}
+
 func instruments(t *table) ([]*instrument.Instrument, error) {
 	instr := make([]*instrument.Instrument, t.NRows)
 	for i := range instr {
-		instr[i] = &instrument.Instrument{
-			Name:   t.ColVals["BOARDNAME"][i],
-			Ticker: t.ColVals["SECID"][i],
+		in := &instrument.Instrument{
+			Name:     t.col("BOARDNAME", i),
+			Ticker:   t.col("SECID", i),
+			Exchange: "MOEX",
+			Board:    t.col("BOARDID", i),
+			Currency: moexCurrencies[t.col("CURRENCYID", i)],
 		}
+
+		if raw := t.col("LOTSIZE", i); raw != "" {
+			lot, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("bad lot size %q of %s: %w", raw, in.Ticker, err)
+			}
+			in.Lot = lot
+		}
+		if raw := t.col("MINSTEP", i); raw != "" {
+			tick, err := decimal.NewFromString(raw)
+			if err != nil {
+				return nil, fmt.Errorf("bad min step %q of %s: %w", raw, in.Ticker, err)
+			}
+			in.Tick = tick
+		}
+
+		instr[i] = in
 	}
 	return instr, nil
 }
@@ -4,7 +4,6 @@ import (
 	"changemedaddy/internal/domain/chart"
 	"changemedaddy/internal/domain/instrument"
 	"changemedaddy/internal/pkg/collection"
-	"changemedaddy/internal/pkg/timeext"
 	"context"
 	"fmt"
 	"github.com/greatcloak/decimal"
@@ -25,26 +24,56 @@ var (
 		"TQDE", "TQIF", "TQLI",
 		"TQLV", "TQNE", "TQNL",
 		"TQPI", "TQTF",
+		// corporate and government bonds
+		"TQCB", "TQOB",
+		// futures and options
+		"SPBFUT", "SPBOPT",
 	)
 
-	MarkerIntervalToDuration = map[int]time.Duration{
-		1:  1 * time.Minute,
-		10: 2 * 60 * time.Minute,
-		6:  2 * time.Minute,
-		7:  3 * time.Minute,
-		11: 4 * 60 * time.Minute,
-		4:  60 * time.Minute,
-		2:  5 * time.Minute,
-		8:  10 * time.Minute,
-		3:  15 * time.Minute,
-		9:  30 * time.Minute,
-		5:  24 * 60 * time.Minute,
-		13: 4 * 7 * 24 * 60 * time.Minute,
-		0:  7 * 24 * 60 * time.Minute,
-		12: 7 * 24 * 60 * time.Minute,
+	tinkoffIntervals = map[chart.Interval]pb.CandleInterval{
+		chart.Minute:         pb.CandleInterval_CANDLE_INTERVAL_1_MIN,
+		chart.FiveMinutes:    pb.CandleInterval_CANDLE_INTERVAL_5_MIN,
+		chart.FifteenMinutes: pb.CandleInterval_CANDLE_INTERVAL_15_MIN,
+		chart.Hour:           pb.CandleInterval_CANDLE_INTERVAL_HOUR,
+		chart.FourHours:      pb.CandleInterval_CANDLE_INTERVAL_4_HOUR,
+		chart.Day:            pb.CandleInterval_CANDLE_INTERVAL_DAY,
+		chart.Week:           pb.CandleInterval_CANDLE_INTERVAL_WEEK,
+		chart.Month:          pb.CandleInterval_CANDLE_INTERVAL_MONTH,
+	}
+
+	tinkoffTypes = map[pb.InstrumentType]instrument.Type{
+		pb.InstrumentType_INSTRUMENT_TYPE_SHARE:    instrument.Share,
+		pb.InstrumentType_INSTRUMENT_TYPE_BOND:     instrument.Bond,
+		pb.InstrumentType_INSTRUMENT_TYPE_ETF:      instrument.ETF,
+		pb.InstrumentType_INSTRUMENT_TYPE_FUTURES:  instrument.Future,
+		pb.InstrumentType_INSTRUMENT_TYPE_OPTION:   instrument.Option,
+		pb.InstrumentType_INSTRUMENT_TYPE_CURRENCY: instrument.Currency,
+	}
+
+	tinkoffOptionKinds = map[pb.OptionDirection]instrument.OptionKind{
+		pb.OptionDirection_OPTION_DIRECTION_CALL: instrument.Call,
+		pb.OptionDirection_OPTION_DIRECTION_PUT:  instrument.Put,
+	}
+
+	tinkoffStatuses = map[pb.SecurityTradingStatus]instrument.TradingStatus{
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_NORMAL_TRADING:                   instrument.Trading,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_NORMAL_TRADING:            instrument.Trading,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_NOT_AVAILABLE_FOR_TRADING:        instrument.NotTrading,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_BREAK_IN_TRADING:                 instrument.NotTrading,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_NOT_AVAILABLE_FOR_TRADING: instrument.NotTrading,
+		pb.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_BREAK_IN_TRADING:          instrument.NotTrading,
 	}
 )
 
+// quotation converts the API's fixed-point number without going through a float.
+func quotation(q *pb.Quotation) decimal.Decimal {
+	return decimal.New(q.GetUnits(), 0).Add(decimal.New(int64(q.GetNano()), -9))
+}
+
+func money(m *pb.MoneyValue) decimal.Decimal {
+	return decimal.New(m.GetUnits(), 0).Add(decimal.New(int64(m.GetNano()), -9))
+}
+
 type service struct {
 	logger             *slog.Logger
 	client             *investgo.Client
@@ -95,27 +124,287 @@ func (s *service) Find(ctx context.Context, ticker string) (*instrument.Instrume
 		return &instrument.Instrument{}, instrument.ErrNotFound
 	}
 
-	ins := instrResp.GetInstruments()
-	found := false
-	for _, in := range ins {
-		if found == true {
-			break
+	// a ticker can be listed on several boards, only the allowed ones are tradeable
+	for _, in := range instrResp.GetInstruments() {
+		if in.GetTicker() == ticker && allowedClassCodes.Contains(in.GetClassCode()) {
+			return s.instrumentByUid(ctx, in.GetUid())
 		}
-		if in.Ticker == ticker && allowedClassCodes.Contains(in.GetClassCode()) {
-			found = true
+	}
+
+	return &instrument.Instrument{}, instrument.ErrNotFound
+}
+
+// FindIndex finds a MOEX index by its ticker.
+func (s *service) FindIndex(ctx context.Context, ticker string) (*instrument.Instrument, error) {
+	ticker = strings.ToUpper(ticker)
+
+	resp, err := s.instrumentsService.FindInstrument(ticker)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find index %s: %w", ticker, err)
+	}
+
+	for _, in := range resp.GetInstruments() {
+		if in.GetTicker() == ticker && in.GetInstrumentKind() == pb.InstrumentType_INSTRUMENT_TYPE_INDEX {
 			return &instrument.Instrument{
 				Name:   in.GetName(),
 				Ticker: in.GetTicker(),
 				Uid:    in.GetUid(),
+				Type:   instrument.Index,
+				Board:  in.GetClassCode(),
 			}, nil
 		}
 	}
 
-	return &instrument.Instrument{
-		Name:   ins[0].GetName(),
-		Ticker: ins[0].GetTicker(),
-		Uid:    ins[0].GetUid(),
-	}, nil
+	return nil, instrument.ErrNotFound
+}
+
+// instrumentByUid gets the details that search results don't have: currency, lot, tick and status.
+func (s *service) instrumentByUid(ctx context.Context, uid string) (*instrument.Instrument, error) {
+	resp, err := s.instrumentsService.InstrumentByUid(uid)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get instrument %s: %w", uid, err)
+	}
+
+	in := resp.GetInstrument()
+	i := &instrument.Instrument{
+		Name:     in.GetName(),
+		Ticker:   in.GetTicker(),
+		Uid:      in.GetUid(),
+		Type:     tinkoffTypes[in.GetInstrumentKind()],
+		Currency: in.GetCurrency(),
+		Lot:      int(in.GetLot()),
+		Tick:     quotation(in.GetMinPriceIncrement()),
+		Exchange: in.GetExchange(),
+		Board:    in.GetClassCode(),
+		Status:   tinkoffStatuses[in.GetTradingStatus()],
+	}
+
+	switch i.Type {
+	case instrument.Bond:
+		if err := s.addBondTerms(ctx, i); err != nil {
+			return nil, err
+		}
+	case instrument.Future:
+		if err := s.addFutureTerms(ctx, i); err != nil {
+			return nil, err
+		}
+	case instrument.Option:
+		if err := s.addOptionTerms(ctx, i); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+func (s *service) addFutureTerms(ctx context.Context, i *instrument.Instrument) error {
+	resp, err := s.instrumentsService.FutureByUid(i.Uid)
+	if err != nil {
+		return fmt.Errorf("couldn't get future %s: %w", i.Uid, err)
+	}
+	f := resp.GetInstrument()
+	i.Expiry = f.GetExpirationDate().AsTime()
+	i.Underlying = f.GetBasicAsset()
+
+	mr, err := s.instrumentsService.GetFuturesMargin(f.GetFigi())
+	if err != nil {
+		return fmt.Errorf("couldn't get margin of future %s: %w", i.Ticker, err)
+	}
+	// the point value is given as the money of one price increment
+	if step := quotation(mr.GetMinPriceIncrement()); step.IsPositive() {
+		i.PointValue = quotation(mr.GetMinPriceIncrementAmount()).Div(step)
+	}
+	i.Margin = money(mr.GetInitialMarginOnBuy())
+	return nil
+}
+
+func (s *service) addOptionTerms(ctx context.Context, i *instrument.Instrument) error {
+	resp, err := s.instrumentsService.OptionByUid(i.Uid)
+	if err != nil {
+		return fmt.Errorf("couldn't get option %s: %w", i.Uid, err)
+	}
+
+	o := resp.GetInstrument()
+	i.Expiry = o.GetExpirationDate().AsTime()
+	i.Underlying = o.GetBasicAsset()
+	i.PointValue = quotation(o.GetBasicAssetSize())
+	i.Strike = money(o.GetStrikePrice())
+	i.OptionKind = tinkoffOptionKinds[o.GetDirection()]
+	return nil
+}
+
+func (s *service) addBondTerms(ctx context.Context, i *instrument.Instrument) error {
+	resp, err := s.instrumentsService.BondByUid(i.Uid)
+	if err != nil {
+		return fmt.Errorf("couldn't get bond %s: %w", i.Uid, err)
+	}
+
+	b := resp.GetInstrument()
+	i.Nominal = money(b.GetNominal())
+	i.Maturity = b.GetMaturityDate().AsTime()
+
+	i.Coupons, err = s.coupons(i, b.GetFigi())
+	return err
+}
+
+// Coupons returns the bond's coupon schedule up to maturity, past coupons included.
+func (s *service) Coupons(ctx context.Context, i *instrument.Instrument) ([]instrument.Coupon, error) {
+	// coupons are only looked up by FIGI, which instruments don't keep
+	resp, err := s.instrumentsService.BondByUid(i.Uid)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get bond %s: %w", i.Uid, err)
+	}
+	return s.coupons(i, resp.GetInstrument().GetFigi())
+}
+
+func (s *service) coupons(i *instrument.Instrument, figi string) ([]instrument.Coupon, error) {
+	resp, err := s.instrumentsService.GetBondCoupons(figi, time.Unix(0, 0), i.Maturity)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get coupons of %s: %w", i.Ticker, err)
+	}
+
+	var cc []instrument.Coupon
+	for _, c := range resp.GetEvents() {
+		cc = append(cc, instrument.Coupon{
+			Date:   c.GetCouponDate().AsTime(),
+			Amount: money(c.GetPayOneBond()),
+		})
+	}
+	return cc, nil
+}
+
+// Dividends returns the dividends with ex-dates from from to to.
+func (s *service) Dividends(ctx context.Context, i *instrument.Instrument, from, to time.Time) ([]instrument.Dividend, error) {
+	// the API filters by record date, which is a day or two after the ex-date
+	resp, err := s.instrumentsService.GetDividents(i.Uid, from, to.AddDate(0, 0, 7))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get dividends of %s: %w", i.Ticker, err)
+	}
+
+	var dd []instrument.Dividend
+	for _, d := range resp.GetDividends() {
+		// buying on the last buy date still settles before the record date
+		exDate := d.GetLastBuyDate().AsTime().AddDate(0, 0, 1)
+		if exDate.Before(from) || exDate.After(to) {
+			continue
+		}
+		dd = append(dd, instrument.Dividend{
+			ExDate: exDate,
+			Amount: money(d.GetDividendNet()),
+		})
+	}
+	return dd, nil
+}
+
+// Listings returns the shares, ETFs, bonds and futures traded on the allowed boards.
+// Bonds and futures are listed without their terms, Find fills them in. Options
+// are too many to list, they are only found by ticker.
+func (s *service) Listings(ctx context.Context) ([]instrument.Listing, error) {
+	shares, err := s.instrumentsService.Shares(pb.InstrumentStatus_INSTRUMENT_STATUS_BASE)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get shares: %w", err)
+	}
+	etfs, err := s.instrumentsService.Etfs(pb.InstrumentStatus_INSTRUMENT_STATUS_BASE)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get etfs: %w", err)
+	}
+	bonds, err := s.instrumentsService.Bonds(pb.InstrumentStatus_INSTRUMENT_STATUS_BASE)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get bonds: %w", err)
+	}
+	futures, err := s.instrumentsService.Futures(pb.InstrumentStatus_INSTRUMENT_STATUS_BASE)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get futures: %w", err)
+	}
+
+	var ll []instrument.Listing
+	for _, sh := range shares.GetInstruments() {
+		if !allowedClassCodes.Contains(sh.GetClassCode()) {
+			continue
+		}
+		ll = append(ll, instrument.Listing{
+			Instrument: instrument.Instrument{
+				Name:     sh.GetName(),
+				Ticker:   sh.GetTicker(),
+				Uid:      sh.GetUid(),
+				Type:     instrument.Share,
+				Currency: sh.GetCurrency(),
+				Lot:      int(sh.GetLot()),
+				Tick:     quotation(sh.GetMinPriceIncrement()),
+				Exchange: sh.GetExchange(),
+				Board:    sh.GetClassCode(),
+				Status:   tinkoffStatuses[sh.GetTradingStatus()],
+			},
+			Isin: sh.GetIsin(),
+		})
+	}
+	for _, e := range etfs.GetInstruments() {
+		if !allowedClassCodes.Contains(e.GetClassCode()) {
+			continue
+		}
+		ll = append(ll, instrument.Listing{
+			Instrument: instrument.Instrument{
+				Name:     e.GetName(),
+				Ticker:   e.GetTicker(),
+				Uid:      e.GetUid(),
+				Type:     instrument.ETF,
+				Currency: e.GetCurrency(),
+				Lot:      int(e.GetLot()),
+				Tick:     quotation(e.GetMinPriceIncrement()),
+				Exchange: e.GetExchange(),
+				Board:    e.GetClassCode(),
+				Status:   tinkoffStatuses[e.GetTradingStatus()],
+			},
+			Isin: e.GetIsin(),
+		})
+	}
+
+	for _, b := range bonds.GetInstruments() {
+		if !allowedClassCodes.Contains(b.GetClassCode()) {
+			continue
+		}
+		ll = append(ll, instrument.Listing{
+			Instrument: instrument.Instrument{
+				Name:     b.GetName(),
+				Ticker:   b.GetTicker(),
+				Uid:      b.GetUid(),
+				Type:     instrument.Bond,
+				Currency: b.GetCurrency(),
+				Lot:      int(b.GetLot()),
+				Tick:     quotation(b.GetMinPriceIncrement()),
+				Exchange: b.GetExchange(),
+				Board:    b.GetClassCode(),
+				Status:   tinkoffStatuses[b.GetTradingStatus()],
+				Nominal:  money(b.GetNominal()),
+				Maturity: b.GetMaturityDate().AsTime(),
+			},
+			Isin: b.GetIsin(),
+		})
+	}
+
+	for _, f := range futures.GetInstruments() {
+		if !allowedClassCodes.Contains(f.GetClassCode()) {
+			continue
+		}
+		ll = append(ll, instrument.Listing{
+			Instrument: instrument.Instrument{
+				Name:       f.GetName(),
+				Ticker:     f.GetTicker(),
+				Uid:        f.GetUid(),
+				Type:       instrument.Future,
+				Currency:   f.GetCurrency(),
+				Lot:        int(f.GetLot()),
+				Tick:       quotation(f.GetMinPriceIncrement()),
+				Exchange:   f.GetExchange(),
+				Board:      f.GetClassCode(),
+				Status:     tinkoffStatuses[f.GetTradingStatus()],
+				Expiry:     f.GetExpirationDate().AsTime(),
+				Underlying: f.GetBasicAsset(),
+			},
+		})
+	}
+
+	return ll, nil
 }
 
 func (s *service) Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error) {
@@ -131,14 +420,21 @@ func (s *service) Price(ctx context.Context, i *instrument.Instrument) (decimal.
 }
 
 func (s *service) GetCandles(ctx context.Context, i *instrument.WithInterval) ([]chart.Candle, error) {
-	from := i.OpenedAt.Local().Add(-120 * MarkerIntervalToDuration[i.MarketInterval])
-	to := timeext.Min(i.Deadline.Local(), time.Now().Local())
+	from, to := i.Span(time.Now())
+	return s.Candles(ctx, i.Instrument, i.Interval, from, to)
+}
+
+func (s *service) Candles(ctx context.Context, i *instrument.Instrument, ci chart.Interval, from, to time.Time) ([]chart.Candle, error) {
+	interval, ok := tinkoffIntervals[ci]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", chart.ErrInterval, ci)
+	}
 
 	req := &investgo.GetHistoricCandlesRequest{
 		Instrument: i.Uid,
-		Interval:   pb.CandleInterval(i.MarketInterval),
-		From:       from,
-		To:         to,
+		Interval:   interval,
+		From:       from.Local(),
+		To:         to.Local(),
 		File:       false,
 		FileName:   "",
 		Source:     pb.GetCandlesRequest_CANDLE_SOURCE_UNSPECIFIED,
@@ -163,6 +459,15 @@ func (s *service) GetCandles(ctx context.Context, i *instrument.WithInterval) ([
 	return chartCandles, nil
 }
 
+// Ping checks that the Tinkoff API is reachable with a cheap instrument lookup.
+func (s *service) Ping(ctx context.Context) error {
+	if _, err := s.instrumentsService.FindInstrument("SBER"); err != nil {
+		return fmt.Errorf("tinkoff api is unreachable: %w", err)
+	}
+
+	return nil
+}
+
 func (s *service) Shutdown(ctx context.Context) error {
 	s.logger.Info("closing client connection")
 	if err := s.client.Stop(); err != nil {
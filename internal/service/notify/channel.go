@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"changemedaddy/internal/domain/notification"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Message is what a channel delivers to a single address.
+type Message struct {
+	Subject string
+	Text    string
+	Link    string
+	Event   notification.Event
+}
+
+// Channel delivers messages to subscribers. Implementations must be safe for concurrent use.
+type Channel interface {
+	Send(ctx context.Context, address string, m Message) error
+}
+
+const baseURL = "https://idea-x3.ru"
+
+func message(e notification.Event, subscriptionID string) Message {
+	link := fmt.Sprintf("%s/analyst/%s/idea/%s", baseURL, e.AnalystSlug, e.IdeaSlug)
+	ticker := strings.ToUpper(e.Ticker)
+	typ := strings.ToUpper(string(e.Type))
+
+	if e.Kind == notification.SubscriptionConfirmation {
+		confirm := fmt.Sprintf("%s/subscription/%s/confirm", baseURL, subscriptionID)
+		subject := "Подтвердите подписку на idea-x3.ru"
+		return Message{
+			Subject: subject,
+			Text:    fmt.Sprintf("Кто-то подписал этот адрес на обновления аналитика на idea-x3.ru. Если это вы, подтвердите подписку:\n\n%s\n\nЕсли нет, просто не обращайте внимания на письмо.", confirm),
+			Link:    confirm,
+			Event:   e,
+		}
+	}
+
+	var subject, body string
+	switch e.Kind {
+	case notification.PositionOpened:
+		subject = fmt.Sprintf("%s: новая позиция %s %s", e.AnalystName, typ, ticker)
+		body = fmt.Sprintf("%s открыл позицию %s %s по %s в идее «%s». Цель %s до %s.",
+			e.AnalystName, typ, ticker, e.OpenPrice, e.IdeaName, e.TargetPrice, e.Deadline.In(timeext.Moscow).Format("2.01.2006"))
+	case notification.PositionChanged:
+		subject = fmt.Sprintf("%s: изменена позиция %s %s", e.AnalystName, typ, ticker)
+		body = fmt.Sprintf("%s изменил позицию %s %s в идее «%s». Теперь цель %s до %s.",
+			e.AnalystName, typ, ticker, e.IdeaName, e.TargetPrice, e.Deadline.In(timeext.Moscow).Format("2.01.2006"))
+	case notification.PositionClosed:
+		subject = fmt.Sprintf("%s: закрыта позиция %s %s", e.AnalystName, typ, ticker)
+		body = fmt.Sprintf("%s закрыл позицию %s %s в идее «%s» по %s (открыта по %s).",
+			e.AnalystName, typ, ticker, e.IdeaName, e.ClosedPrice, e.OpenPrice)
+	default:
+		subject = fmt.Sprintf("%s: обновление идеи «%s»", e.AnalystName, e.IdeaName)
+		body = subject + "."
+	}
+
+	text := fmt.Sprintf("%s\n\n%s\n\nОтписаться: %s/unsubscribe/%s", body, link, baseURL, subscriptionID)
+
+	return Message{
+		Subject: subject,
+		Text:    text,
+		Link:    link,
+		Event:   e,
+	}
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"changemedaddy/internal/domain/notification"
+	"changemedaddy/internal/domain/subscription"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	batchSize    = 50
+	batchTimeout = 30 * time.Second
+	// sendTimeout is a slice of the batch, a slow channel only costs its own sends.
+	sendTimeout = 5 * time.Second
+	// claimLease is how long a claimed delivery is hidden from other flushes. It
+	// outlasts a send and its update, a claim only expires if its holder died.
+	claimLease  = 4 * sendTimeout
+	maxAttempts = 6
+	baseBackoff = 30 * time.Second
+)
+
+type subscriberFinder interface {
+	// FindByTarget returns subscriptions to the analyst and to the idea.
+	FindByTarget(ctx context.Context, analystSlug, ideaSlug string) ([]*subscription.Subscription, error)
+	Find(ctx context.Context, id string) (*subscription.Subscription, error)
+}
+
+type outbox interface {
+	Enqueue(ctx context.Context, dd []notification.Delivery) error
+	// Claim atomically takes a delivery due at now, hiding it from other claims
+	// until now+lease. It returns false when nothing is due.
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (notification.Delivery, bool, error)
+	Update(ctx context.Context, d notification.Delivery) error
+}
+
+// Dispatcher fans events out to subscribers through the outbox and delivers
+// them in the background, retrying failed sends with exponential backoff.
+type Dispatcher struct {
+	log      *slog.Logger
+	subs     subscriberFinder
+	ob       outbox
+	channels map[subscription.Channel]Channel
+	now      func() time.Time
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewDispatcher(log *slog.Logger, sf subscriberFinder, ob outbox, channels map[subscription.Channel]Channel) *Dispatcher {
+	return &Dispatcher{
+		log:      log,
+		subs:     sf,
+		ob:       ob,
+		channels: channels,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Publish persists a delivery for every subscriber of the event's analyst or idea.
+// It doesn't send anything itself, so it is cheap to call from request handlers.
+func (d *Dispatcher) Publish(ctx context.Context, e notification.Event) error {
+	ss, err := d.subs.FindByTarget(ctx, e.AnalystSlug, e.IdeaSlug)
+	if err != nil {
+		return fmt.Errorf("couldn't find subscribers: %w", err)
+	}
+
+	var (
+		dd   = make([]notification.Delivery, 0, len(ss))
+		seen = make(map[string]bool)
+	)
+	for _, s := range ss {
+		// someone following both the analyst and the idea gets a single message
+		key := string(s.Channel) + " " + s.Address
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		dd = append(dd, notification.NewDelivery(e, s))
+	}
+
+	if len(dd) == 0 {
+		return nil
+	}
+
+	if err := d.ob.Enqueue(ctx, dd); err != nil {
+		return fmt.Errorf("couldn't enqueue deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// RequestConfirmation sends the subscription's address the link that confirms it.
+func (d *Dispatcher) RequestConfirmation(ctx context.Context, s *subscription.Subscription) error {
+	dl := notification.NewDelivery(notification.ConfirmationEvent(s), s)
+	if err := d.ob.Enqueue(ctx, []notification.Delivery{dl}); err != nil {
+		return fmt.Errorf("couldn't enqueue confirmation: %w", err)
+	}
+	return nil
+}
+
+// Start runs the delivery loop until Shutdown is called.
+func (d *Dispatcher) Start() {
+	go func() {
+		defer close(d.done)
+
+		t := time.NewTicker(pollInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-t.C:
+				ctx, cancel := context.WithTimeout(context.Background(), batchTimeout)
+				if err := d.Flush(ctx); err != nil {
+					d.log.Error("couldn't flush notification outbox", "err", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Shutdown stops the delivery loop, waiting for the current batch to finish.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.once.Do(func() { close(d.stop) })
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("notification dispatcher didn't stop: %w", ctx.Err())
+	}
+}
+
+// Flush sends the deliveries that are due now, up to a batch. Every delivery is
+// claimed before it's sent, so other replicas and overlapping flushes skip it.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	for n := 0; n < batchSize; n++ {
+		select {
+		case <-d.stop:
+			return nil
+		default:
+		}
+		// don't claim what there's no time left to send
+		if dl, ok := ctx.Deadline(); ok && time.Until(dl) < sendTimeout {
+			return nil
+		}
+
+		dl, ok, err := d.ob.Claim(ctx, d.now(), claimLease)
+		if err != nil {
+			return fmt.Errorf("couldn't claim due delivery: %w", err)
+		} else if !ok {
+			return nil
+		}
+
+		d.deliver(ctx, &dl)
+		if err := d.ob.Update(ctx, dl); err != nil {
+			return fmt.Errorf("couldn't update delivery %s: %w", dl.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func backoff(attempts int) time.Duration {
+	return baseBackoff << (attempts - 1)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, dl *notification.Delivery) {
+	dl.Attempts++
+
+	ch, ok := d.channels[dl.Channel]
+	if !ok {
+		dl.Status = notification.Failed
+		dl.LastError = fmt.Sprintf("channel %q is not configured", dl.Channel)
+		d.log.Warn("dropping notification for unconfigured channel", "channel", dl.Channel, "delivery", dl.ID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	// what was queued for an address stops with its subscription
+	_, err := d.subs.Find(ctx, dl.SubscriptionID)
+	if errors.Is(err, subscription.ErrNotFound) {
+		dl.Status = notification.Failed
+		dl.LastError = "unsubscribed"
+		return
+	} else if err == nil {
+		err = ch.Send(ctx, dl.Address, message(dl.Event, dl.SubscriptionID))
+	}
+
+	switch {
+	case err == nil:
+		dl.Status = notification.Sent
+		dl.LastError = ""
+	case dl.Attempts >= maxAttempts:
+		dl.Status = notification.Failed
+		dl.LastError = err.Error()
+		d.log.Error("giving up on notification", "delivery", dl.ID, "channel", dl.Channel, "err", err)
+	default:
+		dl.NextAttemptAt = d.now().Add(backoff(dl.Attempts))
+		dl.LastError = err.Error()
+		d.log.Warn("couldn't send notification, will retry", "delivery", dl.ID, "channel", dl.Channel, "attempt", dl.Attempts, "err", err)
+	}
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/notification"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/domain/subscription"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type memSubs []*subscription.Subscription
+
+func (m memSubs) FindByTarget(_ context.Context, analystSlug, ideaSlug string) ([]*subscription.Subscription, error) {
+	var ss []*subscription.Subscription
+	for _, s := range m {
+		if s.AnalystSlug == analystSlug && (s.IdeaSlug == "" || s.IdeaSlug == ideaSlug) && !s.Unconfirmed {
+			ss = append(ss, s)
+		}
+	}
+	return ss, nil
+}
+
+func (m memSubs) Find(_ context.Context, id string) (*subscription.Subscription, error) {
+	for _, s := range m {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, subscription.ErrNotFound
+}
+
+type memOutbox struct {
+	dd []notification.Delivery
+}
+
+func (m *memOutbox) Enqueue(_ context.Context, dd []notification.Delivery) error {
+	m.dd = append(m.dd, dd...)
+	return nil
+}
+
+func (m *memOutbox) Claim(_ context.Context, now time.Time, lease time.Duration) (notification.Delivery, bool, error) {
+	for i := range m.dd {
+		if d := &m.dd[i]; d.Status == notification.Pending && !d.NextAttemptAt.After(now) {
+			d.NextAttemptAt = now.Add(lease)
+			return *d, true, nil
+		}
+	}
+	return notification.Delivery{}, false, nil
+}
+
+func (m *memOutbox) Update(_ context.Context, d notification.Delivery) error {
+	for i := range m.dd {
+		if m.dd[i].ID == d.ID {
+			m.dd[i] = d
+		}
+	}
+	return nil
+}
+
+func TestDispatcherRetriesAndDedupes(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		fake = NewFakeChannel()
+		ob   = &memOutbox{}
+		subs = memSubs{
+			{ID: "1", Channel: subscription.Email, Address: "a@b.c", AnalystSlug: "mk"},
+			{ID: "2", Channel: subscription.Email, Address: "a@b.c", AnalystSlug: "mk", IdeaSlug: "sber"},
+			{ID: "3", Channel: subscription.Email, Address: "x@y.z", AnalystSlug: "mk", IdeaSlug: "other"},
+		}
+		now = time.Date(2024, 5, 5, 12, 0, 0, 0, time.UTC)
+	)
+
+	d := NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), subs, ob, map[subscription.Channel]Channel{subscription.Email: fake})
+	d.now = func() time.Time { return now }
+
+	p := &position.Position{ID: 1, Instrument: &instrument.Instrument{Ticker: "SBER"}, Type: position.Long}
+	e := notification.PositionEvent(notification.PositionOpened, "mk", "MK", "sber", "Сбер", p)
+	e.At = now
+
+	if err := d.Publish(ctx, e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ob.dd) != 1 {
+		t.Fatalf("want 1 delivery for a deduplicated subscriber, got %d", len(ob.dd))
+	}
+
+	fake.FailNext = 1
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.Sent()) != 0 || ob.dd[0].Status != notification.Pending || ob.dd[0].Attempts != 1 {
+		t.Fatalf("failed send must be rescheduled, got %+v", ob.dd[0])
+	}
+
+	// not due yet
+	_ = d.Flush(ctx)
+	if len(fake.Sent()) != 0 {
+		t.Fatalf("delivery retried before backoff elapsed")
+	}
+
+	now = now.Add(backoff(1))
+	_ = d.Flush(ctx)
+	if len(fake.Sent()) != 1 || ob.dd[0].Status != notification.Sent {
+		t.Fatalf("delivery wasn't sent after backoff: %+v", ob.dd[0])
+	}
+	if got := fake.Sent()[0].Address; got != "a@b.c" {
+		t.Errorf("sent to %q, want %q", got, "a@b.c")
+	}
+}
+
+func TestUnconfirmedGetOnlyTheConfirmation(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		fake = NewFakeChannel()
+		ob   = &memOutbox{}
+		s    = &subscription.Subscription{ID: "1", Channel: subscription.Email, Address: "a@b.c", AnalystSlug: "mk", Unconfirmed: true}
+	)
+
+	d := NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), memSubs{s}, ob, map[subscription.Channel]Channel{subscription.Email: fake})
+
+	p := &position.Position{ID: 1, Instrument: &instrument.Instrument{Ticker: "SBER"}, Type: position.Long}
+	if err := d.Publish(ctx, notification.PositionEvent(notification.PositionOpened, "mk", "MK", "sber", "Сбер", p)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.RequestConfirmation(ctx, s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent := fake.Sent()
+	if len(sent) != 1 || !strings.Contains(sent[0].Message.Text, "/subscription/1/confirm") {
+		t.Fatalf("want the confirmation only, got %+v", sent)
+	}
+}
+
+func TestClaimedDeliveryIsSentOnce(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		fake = NewFakeChannel()
+		ob   = &memOutbox{}
+		subs = memSubs{{ID: "1", Channel: subscription.Email, Address: "a@b.c", AnalystSlug: "mk"}}
+		now  = time.Date(2024, 5, 5, 12, 0, 0, 0, time.UTC)
+	)
+
+	d := NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), subs, ob, map[subscription.Channel]Channel{subscription.Email: fake})
+	d.now = func() time.Time { return now }
+
+	p := &position.Position{ID: 1, Instrument: &instrument.Instrument{Ticker: "SBER"}, Type: position.Long}
+	e := notification.PositionEvent(notification.PositionOpened, "mk", "MK", "sber", "Сбер", p)
+	e.At = now
+	if err := d.Publish(ctx, e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// another replica holds it
+	if _, ok, _ := ob.Claim(ctx, now, claimLease); !ok {
+		t.Fatal("want the delivery claimed")
+	}
+	_ = d.Flush(ctx)
+	if len(fake.Sent()) != 0 {
+		t.Fatalf("claimed delivery was sent again")
+	}
+
+	// its holder died, the lease ran out
+	now = now.Add(claimLease)
+	_ = d.Flush(ctx)
+	_ = d.Flush(ctx)
+	if len(fake.Sent()) != 1 {
+		t.Fatalf("want the delivery sent once after the lease, got %d", len(fake.Sent()))
+	}
+}
+
+func TestUnsubscribedGetNothingQueued(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		fake = NewFakeChannel()
+		ob   = &memOutbox{}
+		subs = memSubs{{ID: "1", Channel: subscription.Email, Address: "a@b.c", AnalystSlug: "mk"}}
+	)
+
+	d := NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), subs, ob, map[subscription.Channel]Channel{subscription.Email: fake})
+
+	p := &position.Position{ID: 1, Instrument: &instrument.Instrument{Ticker: "SBER"}, Type: position.Long}
+	if err := d.Publish(ctx, notification.PositionEvent(notification.PositionOpened, "mk", "MK", "sber", "Сбер", p)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d.subs = memSubs{}
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.Sent()) != 0 || ob.dd[0].Status != notification.Failed {
+		t.Fatalf("want nothing sent after unsubscribing, got %d sent and %+v", len(fake.Sent()), ob.dd[0])
+	}
+}
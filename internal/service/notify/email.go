@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type emailChannel struct {
+	addr string
+	host string
+	from string
+	auth smtp.Auth
+}
+
+// NewEmailChannel sends messages through an SMTP server at addr (host:port) with
+// PLAIN auth, or none if there's no user.
+func NewEmailChannel(addr, from, user, password string) *emailChannel {
+	host, _, _ := net.SplitHostPort(addr)
+	ch := &emailChannel{
+		addr: addr,
+		host: host,
+		from: from,
+	}
+	if user != "" {
+		ch.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return ch
+}
+
+func (ch *emailChannel) Send(ctx context.Context, address string, m Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", ch.from)
+	fmt.Fprintf(&b, "To: %s\r\n", address)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(m.Text, "\n", "\r\n"))
+
+	if err := ch.send(ctx, address, []byte(b.String())); err != nil {
+		return fmt.Errorf("couldn't send email: %w", err)
+	}
+
+	return nil
+}
+
+// send is smtp.SendMail bounded by ctx: net/smtp knows nothing of contexts, so
+// the connection gets the ctx's deadline and is closed if ctx is cancelled.
+func (ch *emailChannel) send(ctx context.Context, to string, msg []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", ch.addr)
+	if err != nil {
+		return fmt.Errorf("couldn't dial smtp server: %w", err)
+	}
+	defer conn.Close()
+
+	if dl, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(dl); err != nil {
+			return fmt.Errorf("couldn't set smtp deadline: %w", err)
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, ch.host)
+	if err != nil {
+		return fmt.Errorf("couldn't greet smtp server: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: ch.host}); err != nil {
+			return fmt.Errorf("couldn't start tls: %w", err)
+		}
+	}
+	if ch.auth != nil {
+		if err := c.Auth(ch.auth); err != nil {
+			return fmt.Errorf("couldn't authenticate: %w", err)
+		}
+	}
+	if err := c.Mail(ch.from); err != nil {
+		return fmt.Errorf("smtp MAIL: %w", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp RCPT: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("couldn't write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("couldn't finish message: %w", err)
+	}
+
+	return c.Quit()
+}
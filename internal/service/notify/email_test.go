@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestEmailGivesUpOnStalledServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// accepts and never greets
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ch := NewEmailChannel(l.Addr().String(), "from@x3.ru", "", "")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := ch.Send(ctx, "a@b.c", Message{Subject: "s", Text: "t"}); err == nil {
+		t.Fatal("want an error from a stalled server")
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("send took %v, want it bounded by the context", took)
+	}
+}
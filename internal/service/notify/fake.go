@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var errFake = errors.New("fake channel failure")
+
+type Sent struct {
+	Address string
+	Message Message
+}
+
+// FakeChannel records messages instead of sending them. It fails the next
+// FailNext sends, which is handy for testing retries.
+type FakeChannel struct {
+	mu       sync.Mutex
+	sent     []Sent
+	FailNext int
+	Err      error
+}
+
+func NewFakeChannel() *FakeChannel {
+	return &FakeChannel{}
+}
+
+func (ch *FakeChannel) Send(ctx context.Context, address string, m Message) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.FailNext > 0 {
+		ch.FailNext--
+		if ch.Err == nil {
+			return errFake
+		}
+		return ch.Err
+	}
+
+	ch.sent = append(ch.sent, Sent{Address: address, Message: m})
+	return nil
+}
+
+func (ch *FakeChannel) Sent() []Sent {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	sent := make([]Sent, len(ch.sent))
+	copy(sent, ch.sent)
+	return sent
+}
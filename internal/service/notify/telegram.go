@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type telegramChannel struct {
+	token  string
+	client *http.Client
+}
+
+// NewTelegramChannel sends messages as the bot with the given token. Addresses are chat ids or @channel names.
+func NewTelegramChannel(token string) *telegramChannel {
+	return &telegramChannel{
+		token:  token,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (ch *telegramChannel) Send(ctx context.Context, address string, m Message) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id": address,
+		"text":    m.Text,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't marshal telegram message: %w", err)
+	}
+
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", ch.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("couldn't create telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ch.client.Do(req)
+	if err != nil {
+		// the error contains the url and with it the bot token
+		return fmt.Errorf("couldn't reach telegram")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram responded with %s", resp.Status)
+	}
+
+	return nil
+}
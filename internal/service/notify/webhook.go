@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"bytes"
+	"changemedaddy/internal/pkg/netguard"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type webhookChannel struct {
+	client *http.Client
+}
+
+// NewWebhookChannel POSTs the event as JSON to the subscriber's URL. Anyone can
+// subscribe a URL, so it only connects to public addresses.
+func NewWebhookChannel() *webhookChannel {
+	return &webhookChannel{
+		client: netguard.Client(5 * time.Second),
+	}
+}
+
+func (ch *webhookChannel) Send(ctx context.Context, address string, m Message) error {
+	body, err := json.Marshal(map[string]any{
+		"subject": m.Subject,
+		"text":    m.Text,
+		"link":    m.Link,
+		"event":   m.Event,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("couldn't create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ch.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("couldn't call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
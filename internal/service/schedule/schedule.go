@@ -0,0 +1,136 @@
+// Package schedule publishes the ideas and positions analysts have scheduled,
+// at the time they asked for.
+package schedule
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+const (
+	// checkInterval picks up the schedules made since the last check.
+	checkInterval = time.Minute
+	checkTimeout  = time.Minute
+)
+
+type ideaRepo interface {
+	FindScheduled(ctx context.Context) ([]*idea.Idea, error)
+	Update(ctx context.Context, i *idea.Idea) error
+}
+
+type positionRepo interface {
+	Find(ctx context.Context, id int) (*position.Position, error)
+	Update(ctx context.Context, p *position.Position) error
+	Delete(ctx context.Context, id int) error
+}
+
+type priceProvider interface {
+	Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error)
+}
+
+type Publisher struct {
+	log   *slog.Logger
+	ir    ideaRepo
+	pr    positionRepo
+	pp    priceProvider
+	clock timeext.Clock
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New makes a publisher that tells the time by the clock, the one the prices are of.
+func New(log *slog.Logger, ir ideaRepo, pr positionRepo, pp priceProvider, clock timeext.Clock) *Publisher {
+	return &Publisher{
+		log:   log,
+		ir:    ir,
+		pr:    pr,
+		pp:    pp,
+		clock: clock,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// PublishDue publishes everything whose time has come and returns when the next
+// publication is due, zero if nothing is scheduled.
+func (p *Publisher) PublishDue(ctx context.Context) (time.Time, error) {
+	ii, err := p.ir.FindScheduled(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("couldn't find scheduled ideas: %w", err)
+	}
+
+	var next time.Time
+	for _, i := range ii {
+		pub, err := i.PublishDue(ctx, p.clock.Now(), p.pp, p.pr, p.ir)
+		if err != nil {
+			// one idea failing shouldn't hold the others back
+			p.log.Warn("couldn't publish scheduled", "idea", i.Slug, "analyst", i.AuthorSlug, "err", err)
+		}
+
+		if pub.Idea {
+			p.log.Info("published scheduled idea", "idea", i.Slug, "analyst", i.AuthorSlug, "positions", len(pub.Positions))
+		}
+		if len(pub.Positions) > 0 && !pub.Idea {
+			p.log.Info("opened pending positions", "idea", i.Slug, "analyst", i.AuthorSlug, "positions", len(pub.Positions))
+		}
+
+		if !pub.Next.IsZero() && (next.IsZero() || pub.Next.Before(next)) {
+			next = pub.Next
+		}
+	}
+
+	return next, nil
+}
+
+// Start publishes until Shutdown is called, waking up right at the next
+// publication so the open prices are of that moment.
+func (p *Publisher) Start() {
+	go func() {
+		defer close(p.done)
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			next, err := p.PublishDue(ctx)
+			if err != nil {
+				p.log.Error("couldn't publish scheduled", "err", err)
+			}
+			cancel()
+
+			wait := checkInterval
+			if !next.IsZero() {
+				wait = min(wait, max(next.Sub(p.clock.Now()), 0))
+			}
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-p.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the loop, waiting for the current publication to finish.
+func (p *Publisher) Shutdown(ctx context.Context) error {
+	p.once.Do(func() { close(p.stop) })
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("publisher didn't stop: %w", ctx.Err())
+	}
+}
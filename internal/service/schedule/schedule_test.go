@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+type fixedPrice struct{}
+
+func (fixedPrice) Price(ctx context.Context, i *instrument.Instrument) (decimal.Decimal, error) {
+	return decimal.NewFromInt(110), nil
+}
+
+type memIdeas []*idea.Idea
+
+func (ii memIdeas) FindScheduled(ctx context.Context) ([]*idea.Idea, error) {
+	var due []*idea.Idea
+	for _, i := range ii {
+		if i.IsScheduled() || len(i.PendingIDs) > 0 {
+			due = append(due, i)
+		}
+	}
+	return due, nil
+}
+
+func (ii memIdeas) Update(ctx context.Context, i *idea.Idea) error {
+	return nil
+}
+
+type memPositions map[int]*position.Position
+
+func (pp memPositions) Find(ctx context.Context, id int) (*position.Position, error) {
+	p, ok := pp[id]
+	if !ok {
+		return nil, position.ErrNotFound
+	}
+	return p, nil
+}
+
+func (pp memPositions) Update(ctx context.Context, p *position.Position) error {
+	pp[p.ID] = p
+	return nil
+}
+
+func (pp memPositions) Delete(ctx context.Context, id int) error {
+	delete(pp, id)
+	return nil
+}
+
+func TestPublishDueByClock(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		now   = time.Date(2024, 5, 12, 12, 0, 0, 0, timeext.Moscow)
+		clock = timeext.NewManualClock(now)
+		sber  = func(id int, status position.Status, at time.Time) *position.Position {
+			return &position.Position{
+				ID:          id,
+				Instrument:  &instrument.Instrument{Ticker: "SBER"},
+				Type:        position.Long,
+				Status:      status,
+				OpenPrice:   decimal.NewFromInt(100),
+				Deadline:    now.AddDate(0, 1, 0),
+				TargetPrice: decimal.NewFromInt(150),
+				PublishAt:   at,
+			}
+		}
+		pr = memPositions{
+			1: sber(1, position.Active, time.Time{}),
+			2: sber(2, position.Pending, now.Add(2*time.Hour)),
+		}
+		ir = memIdeas{
+			{Slug: "draft", Status: idea.Draft, PositionIDs: []int{1}, PublishAt: now.Add(time.Hour)},
+			{Slug: "active", Status: idea.Active, PendingIDs: []int{2}},
+		}
+		p = New(slog.New(slog.NewTextHandler(io.Discard, nil)), ir, pr, fixedPrice{}, clock)
+	)
+
+	next, err := p.PublishDue(ctx)
+	if err != nil || !next.Equal(now.Add(time.Hour)) || ir[0].Status != idea.Draft {
+		t.Fatalf("got next %v, %v and the draft %s, want nothing due before an hour", next, err, ir[0].Status)
+	}
+
+	clock.Advance(time.Hour)
+	next, err = p.PublishDue(ctx)
+	if err != nil || !next.Equal(now.Add(2*time.Hour)) || ir[0].Status != idea.Active || pr[2].Status != position.Pending {
+		t.Fatalf("got next %v, %v and the draft %s, want it published", next, err, ir[0].Status)
+	}
+	if p := pr[1]; !p.OpenDate.Equal(clock.Now()) || !p.OpenPrice.Equal(decimal.NewFromInt(110)) {
+		t.Errorf("got the position opened at %s on %v, want 110 at the clock's time", p.OpenPrice, p.OpenDate)
+	}
+
+	clock.Advance(time.Hour)
+	if next, err = p.PublishDue(ctx); err != nil || !next.IsZero() || pr[2].Status != position.Active {
+		t.Errorf("got next %v, %v and the pending position %s, want it opened", next, err, pr[2].Status)
+	}
+}
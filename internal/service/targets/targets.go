@@ -0,0 +1,123 @@
+// Package targets tracks the staged targets of active positions and exits their
+// fractions once the price reaches them.
+package targets
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	checkInterval = 10 * time.Minute
+	checkTimeout  = 2 * time.Minute
+)
+
+type positionRepo interface {
+	FindActive(ctx context.Context) ([]*position.Position, error)
+	UpdateUnchanged(ctx context.Context, p *position.Position, history int) error
+}
+
+// unchanged saves the position only if no one has changed it since the
+// tracker read it, so an edit made meanwhile isn't overwritten.
+type unchanged struct {
+	pr      positionRepo
+	history int
+}
+
+func (u *unchanged) Update(ctx context.Context, p *position.Position) error {
+	if err := u.pr.UpdateUnchanged(ctx, p, u.history); err != nil {
+		return err
+	}
+	u.history = len(p.History)
+	return nil
+}
+
+type candleProvider interface {
+	GetCandles(ctx context.Context, i *instrument.WithInterval) ([]chart.Candle, error)
+}
+
+type Tracker struct {
+	log *slog.Logger
+	pr  positionRepo
+	cp  candleProvider
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+func New(log *slog.Logger, pr positionRepo, cp candleProvider) *Tracker {
+	return &Tracker{
+		log:  log,
+		pr:   pr,
+		cp:   cp,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// TrackAll checks the targets of every active position.
+func (t *Tracker) TrackAll(ctx context.Context) error {
+	pp, err := t.pr.FindActive(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't find active positions: %w", err)
+	}
+
+	for _, p := range pp {
+		n, err := p.TrackTargets(ctx, t.cp, &unchanged{pr: t.pr, history: len(p.History)})
+		if errors.Is(err, position.ErrModified) {
+			t.log.Info("position changed while tracking targets, tracked next time", "id", p.ID)
+		} else if err != nil {
+			// one position failing shouldn't keep the others from being tracked
+			t.log.Warn("couldn't track targets", "id", p.ID, "ticker", p.Instrument.Ticker, "err", err)
+		}
+		if n > 0 {
+			t.log.Info("targets hit", "id", p.ID, "ticker", p.Instrument.Ticker, "hits", n, "status", p.Status)
+		}
+	}
+
+	return nil
+}
+
+// Start checks the targets until Shutdown is called.
+func (t *Tracker) Start() {
+	go func() {
+		defer close(t.done)
+
+		tick := time.NewTicker(checkInterval)
+		defer tick.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			if err := t.TrackAll(ctx); err != nil {
+				t.log.Error("couldn't track targets", "err", err)
+			}
+			cancel()
+
+			select {
+			case <-t.stop:
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the loop, waiting for the current check to finish.
+func (t *Tracker) Shutdown(ctx context.Context) error {
+	t.once.Do(func() { close(t.stop) })
+
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("target tracker didn't stop: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,109 @@
+// Package visits records the page views off the request path, a slow database
+// shouldn't slow the pages down.
+package visits
+
+import (
+	"changemedaddy/internal/domain/visit"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// bufferSize is how many views may wait to be recorded, the ones past it are dropped.
+	bufferSize    = 1024
+	recordTimeout = 2 * time.Second
+)
+
+type viewRepo interface {
+	Salt(ctx context.Context, day string) ([]byte, error)
+	Add(ctx context.Context, v visit.View) error
+}
+
+type Recorder struct {
+	log   *slog.Logger
+	repo  viewRepo
+	views chan visit.ViewOptions
+	// dropped is logged by the worker, not by each request that drops.
+	dropped atomic.Int64
+
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+func New(log *slog.Logger, repo viewRepo) *Recorder {
+	return &Recorder{
+		log:   log,
+		repo:  repo,
+		views: make(chan visit.ViewOptions, bufferSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Record queues the view without waiting, it's dropped if the queue is full.
+func (r *Recorder) Record(opt visit.ViewOptions) {
+	select {
+	case r.views <- opt:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+func (r *Recorder) record(opt visit.ViewOptions) {
+	if n := r.dropped.Swap(0); n > 0 {
+		r.log.Warn("dropped page views, too many waiting", "count", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+
+	v, err := visit.NewView(ctx, r.repo, opt)
+	if err != nil {
+		r.log.Error("couldn't create page view", "err", err)
+		return
+	}
+	if err := r.repo.Add(ctx, v); err != nil {
+		r.log.Error("couldn't record page view", "err", err, "analyst", v.AnalystSlug, "idea", v.IdeaSlug)
+	}
+}
+
+// Start records the queued views until Shutdown is called.
+func (r *Recorder) Start() {
+	go func() {
+		defer close(r.done)
+
+		for {
+			select {
+			case opt := <-r.views:
+				r.record(opt)
+			case <-r.stop:
+				// the views queued by the last requests are still recorded
+				for {
+					select {
+					case opt := <-r.views:
+						r.record(opt)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown stops the loop once the queued views are recorded.
+func (r *Recorder) Shutdown(ctx context.Context) error {
+	r.once.Do(func() { close(r.stop) })
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("visit recorder didn't stop: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,43 @@
+package visits
+
+import (
+	"changemedaddy/internal/domain/visit"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type memViews struct {
+	vv []visit.View
+}
+
+func (m *memViews) Salt(ctx context.Context, day string) ([]byte, error) {
+	return []byte("salt"), nil
+}
+
+func (m *memViews) Add(ctx context.Context, v visit.View) error {
+	m.vv = append(m.vv, v)
+	return nil
+}
+
+func TestRecordDropsPastBuffer(t *testing.T) {
+	var (
+		repo memViews
+		r    = New(slog.New(slog.NewTextHandler(io.Discard, nil)), &repo)
+	)
+
+	// nothing records yet, the queue fills up
+	for range bufferSize + 10 {
+		r.Record(visit.ViewOptions{IdeaSlug: "sber", At: time.Now()})
+	}
+
+	r.Start()
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.vv) != bufferSize {
+		t.Errorf("got %d views recorded, want the %d queued before the rest were dropped", len(repo.vv), bufferSize)
+	}
+}
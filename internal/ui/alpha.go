@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/position"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AlphaComponent is the mean alpha of an idea's or an analyst's positions,
+// with a row per idea for analysts.
+type AlphaComponent struct {
+	Title     string
+	AlphaP    string
+	AlphaUp   bool
+	Positions int
+	Rows      []AlphaRow
+}
+
+type AlphaRow struct {
+	Name      string
+	Link      string
+	Benchmark string
+	AlphaP    string
+	AlphaUp   bool
+	Positions int
+}
+
+func alpha(rr []position.Relative) (string, bool) {
+	m, ok := position.MeanAlpha(rr)
+	if !ok {
+		return "", false
+	}
+	return withSign(m.Round(2)), !m.IsNegative()
+}
+
+func IdeaAlpha(i *idea.Idea, rr []position.Relative) AlphaComponent {
+	ac := AlphaComponent{
+		Title:     "Альфа к " + i.BenchmarkTicker(),
+		Positions: len(rr),
+	}
+	ac.AlphaP, ac.AlphaUp = alpha(rr)
+	return ac
+}
+
+// AnalystAlpha weighs every position equally, so ideas with more positions count more.
+func AnalystAlpha(analystSlug string, ii []*idea.Idea, rr map[string][]position.Relative) AlphaComponent {
+	var all []position.Relative
+	ac := AlphaComponent{Title: "Альфа"}
+	for _, i := range ii {
+		if len(rr[i.Slug]) == 0 {
+			continue
+		}
+		all = append(all, rr[i.Slug]...)
+
+		row := AlphaRow{
+			Name:      i.Name,
+			Link:      "/analyst/" + analystSlug + "/idea/" + i.Slug,
+			Benchmark: i.BenchmarkTicker(),
+			Positions: len(rr[i.Slug]),
+		}
+		row.AlphaP, row.AlphaUp = alpha(rr[i.Slug])
+		ac.Rows = append(ac.Rows, row)
+	}
+
+	ac.Positions = len(all)
+	ac.AlphaP, ac.AlphaUp = alpha(all)
+	return ac
+}
+
+func (ac AlphaComponent) Render(c echo.Context) error {
+	return c.Render(200, "alpha.html", ac)
+}
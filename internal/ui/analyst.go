@@ -14,6 +14,9 @@ type AnalystComponent struct {
 	Name  string
 	Slug  string
 	Ideas []IdeaComponent
+	// Drafts and Archived are shown to the owner only.
+	Drafts   []IdeaComponent
+	Archived []IdeaComponent
 
 	IsOwner bool
 }
@@ -32,17 +35,23 @@ func Analyst(a *analyst.Analyst, ideas []*idea.Idea) AnalystComponent {
 }
 
 func Owner(a *analyst.Analyst, ideas []*idea.Idea) AnalystComponent {
-	var ii []IdeaComponent
-	for _, i := range ideas {
-		ii = append(ii, Idea(i, true))
-	}
-
-	return AnalystComponent{
+	ac := AnalystComponent{
 		Name:    a.Name,
 		Slug:    a.Slug,
-		Ideas:   ii,
 		IsOwner: true,
 	}
+	for _, i := range ideas {
+		switch i.Status {
+		case idea.Draft:
+			ac.Drafts = append(ac.Drafts, Idea(i, true))
+		case idea.Archived:
+			ac.Archived = append(ac.Archived, Idea(i, true))
+		default:
+			ac.Ideas = append(ac.Ideas, Idea(i, true))
+		}
+	}
+
+	return ac
 }
 
 func (a AnalystComponent) Render(c echo.Context) error {
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"changemedaddy/internal/domain/visit"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StatsComponent struct {
+	AnalystSlug string
+	Views       int
+	Visitors    int
+
+	Days      []visit.DayStats
+	MaxViews  int
+	Ideas     []visit.IdeaStats
+	Referrers []visit.ReferrerStats
+}
+
+func AnalystStats(s visit.Stats) StatsComponent {
+	var maxViews int
+	for _, d := range s.Days {
+		maxViews = max(maxViews, d.Views)
+	}
+
+	return StatsComponent{
+		AnalystSlug: s.AnalystSlug,
+		Views:       s.Total.Views,
+		Visitors:    s.Total.Visitors,
+		Days:        s.Days,
+		MaxViews:    maxViews,
+		Ideas:       s.Ideas,
+		Referrers:   s.Referrers,
+	}
+}
+
+func (sc StatsComponent) Render(c echo.Context) error {
+	return c.Render(200, "analyst_stats.html", sc)
+}
+
+type AnalyticsComponent struct {
+	Analysts []StatsComponent
+}
+
+func Analytics(ss []visit.Stats) AnalyticsComponent {
+	var aa []StatsComponent
+	for _, s := range ss {
+		aa = append(aa, AnalystStats(s))
+	}
+
+	return AnalyticsComponent{Analysts: aa}
+}
+
+func (ac AnalyticsComponent) Render(c echo.Context) error {
+	return c.Render(200, "analytics.html", ac)
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"changemedaddy/internal/domain/backtest"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/greatcloak/decimal"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	equityWidth  = 600
+	equityHeight = 150
+)
+
+// BacktestComponent is the equity of a portfolio that followed the analyst.
+type BacktestComponent struct {
+	Link      string
+	JSONLink  string
+	Sizing    string
+	Capital   string
+	ReturnP   string
+	ReturnUp  bool
+	Final     string
+	DrawdownP string
+	Followed  int
+	Skipped   int
+	// Points is the equity curve as an SVG polyline.
+	Points string
+	Width  int
+	Height int
+}
+
+func rubles(f float64) string {
+	return decimal.NewFromFloat(f).Round(0).String() + " ₽"
+}
+
+// equityPoints scales the equity to the chart, the lowest value at the bottom.
+func equityPoints(pp []backtest.Point) string {
+	if len(pp) < 2 {
+		return ""
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range pp {
+		lo, hi = math.Min(lo, p.Equity), math.Max(hi, p.Equity)
+	}
+	span := hi - lo
+	if span == 0 {
+		span = 1
+	}
+
+	ss := make([]string, len(pp))
+	for n, p := range pp {
+		x := float64(n) / float64(len(pp)-1) * equityWidth
+		y := equityHeight - (p.Equity-lo)/span*equityHeight
+		ss[n] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return strings.Join(ss, " ")
+}
+
+func Backtest(analystSlug string, opt backtest.Options, res backtest.Result) BacktestComponent {
+	link := "/analyst/" + analystSlug + "/backtest"
+	return BacktestComponent{
+		Link:      link,
+		JSONLink:  link + ".json?sizing=" + string(opt.Sizing),
+		Sizing:    string(opt.Sizing),
+		Capital:   rubles(res.Capital),
+		ReturnP:   withSign(decimal.NewFromFloat(res.ReturnP).Round(2)),
+		ReturnUp:  res.ReturnP >= 0,
+		Final:     rubles(res.Final),
+		DrawdownP: decimal.NewFromFloat(res.MaxDrawdownP).Round(2).String(),
+		Followed:  res.Followed,
+		Skipped:   res.Skipped,
+		Points:    equityPoints(res.Equity),
+		Width:     equityWidth,
+		Height:    equityHeight,
+	}
+}
+
+func (bc BacktestComponent) Render(c echo.Context) error {
+	return c.Render(200, "backtest.html", bc)
+}
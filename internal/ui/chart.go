@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"changemedaddy/internal/domain/chart"
+	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/chartimg"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type chartMarker struct {
+	Time  int64   `json:"time"`
+	Price float64 `json:"price,omitempty"`
+	Text  string  `json:"text"`
+}
+
+type chartHistoryMarker struct {
+	Kind position.ChangeKind `json:"kind"`
+	chartMarker
+}
+
+// chartLine is a horizontal segment, the target is drawn as one segment per target change.
+type chartLine struct {
+	From  int64   `json:"from"`
+	To    int64   `json:"to"`
+	Price float64 `json:"price"`
+}
+
+type chartAnnotations struct {
+	Open     chartMarker          `json:"open"`
+	Target   []chartLine          `json:"target"`
+	Deadline chartMarker          `json:"deadline"`
+	Close    *chartMarker         `json:"close"`
+	History  []chartHistoryMarker `json:"history"`
+}
+
+// ChartComponent is the position chart: the candles and the trade drawn on top of them.
+type ChartComponent struct {
+	Candles     []chart.Candle   `json:"candles"`
+	Annotations chartAnnotations `json:"annotations"`
+}
+
+func PositionChart(p *position.Position, candles []chart.Candle, now time.Time) ChartComponent {
+	var (
+		a     chartAnnotations
+		cc    = p.Changes()
+		end   = now
+		money = p.Instrument.FormatPrice
+	)
+
+	for n, ch := range cc {
+		at := ch.At.Unix()
+		switch ch.Kind {
+		case position.OpenedChange:
+			a.Open = chartMarker{Time: at, Price: ch.Price.InexactFloat64(), Text: fmt.Sprintf("Открытие по %s", money(ch.Price))}
+		case position.CloseChange:
+			a.Close = &chartMarker{Time: at, Price: ch.Price.InexactFloat64(), Text: fmt.Sprintf("Закрытие по %s", money(ch.Price))}
+			end = ch.At
+			continue
+		case position.TargetChange:
+			a.History = append(a.History, chartHistoryMarker{ch.Kind, chartMarker{
+				Time:  at,
+				Price: ch.TargetPrice.InexactFloat64(),
+				Text:  fmt.Sprintf("Цель изменена на %s", money(ch.TargetPrice)),
+			}})
+		case position.DeadlineChange:
+			a.History = append(a.History, chartHistoryMarker{ch.Kind, chartMarker{
+				Time: at,
+				Text: fmt.Sprintf("Срок перенесён на %s", ch.Deadline.Format("2.01.2006")),
+			}})
+		case position.TargetHitChange:
+			a.History = append(a.History, chartHistoryMarker{ch.Kind, chartMarker{
+				Time:  at,
+				Price: ch.Price.InexactFloat64(),
+				Text:  fmt.Sprintf("Цель %s достигнута", money(ch.Price)),
+			}})
+		case position.AddChange:
+			a.History = append(a.History, chartHistoryMarker{ch.Kind, chartMarker{
+				Time:  at,
+				Price: ch.Price.InexactFloat64(),
+				Text:  fmt.Sprintf("Добор %s%% по %s", percent(ch.Fraction), money(ch.Price)),
+			}})
+		case position.ReduceChange:
+			a.History = append(a.History, chartHistoryMarker{ch.Kind, chartMarker{
+				Time:  at,
+				Price: ch.Price.InexactFloat64(),
+				Text:  fmt.Sprintf("Частичное закрытие %s%% по %s", percent(ch.Fraction.Neg()), money(ch.Price)),
+			}})
+		}
+
+		// closing overwrites the deadline, the last planned one comes from the change before
+		a.Deadline = chartMarker{Time: ch.Deadline.Unix(), Text: fmt.Sprintf("Срок %s", ch.Deadline.Format("2.01.2006"))}
+
+		if n == 0 || !ch.TargetPrice.Equal(cc[n-1].TargetPrice) {
+			a.Target = append(a.Target, chartLine{From: at, Price: ch.TargetPrice.InexactFloat64()})
+		}
+	}
+
+	for n := range a.Target {
+		if n+1 < len(a.Target) {
+			a.Target[n].To = a.Target[n+1].From
+		} else {
+			a.Target[n].To = end.Unix()
+		}
+	}
+
+	if candles == nil {
+		candles = []chart.Candle{}
+	}
+
+	return ChartComponent{Candles: candles, Annotations: a}
+}
+
+func (cc ChartComponent) Render(c echo.Context) error {
+	return c.JSON(200, cc)
+}
+
+// PositionImage is the chart of the position for link previews.
+func PositionImage(p *position.Position, candles []chart.Candle) chartimg.Chart {
+	ticker := strings.ToUpper(p.Instrument.Ticker)
+	c := chartimg.Chart{
+		Title:    fmt.Sprintf("%s %s", strings.ToUpper(string(p.Type)), ticker),
+		Subtitle: p.Instrument.Name,
+		Candles:  candles,
+		Levels: []chartimg.Level{
+			{Label: fmt.Sprintf("Вход %s", p.Instrument.FormatPrice(p.OpenPrice)), Price: p.OpenPrice.InexactFloat64(), Color: chartimg.Grey, Dashed: true},
+			{Label: fmt.Sprintf("Цель %s", p.Instrument.FormatPrice(p.TargetPrice)), Price: p.TargetPrice.InexactFloat64(), Color: chartimg.Up, Dashed: true},
+		},
+	}
+
+	if p.Status == position.Closed {
+		col := chartimg.Up
+		if (p.Type == position.Long) != p.ClosedPrice.GreaterThanOrEqual(p.OpenPrice) {
+			col = chartimg.Down
+		}
+		c.Levels = append(c.Levels, chartimg.Level{
+			Label: fmt.Sprintf("Выход %s", p.Instrument.FormatPrice(p.ClosedPrice)),
+			Price: p.ClosedPrice.InexactFloat64(),
+			Color: col,
+		})
+	}
+
+	return c
+}
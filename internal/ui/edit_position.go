@@ -23,9 +23,20 @@ type PositionEditForm struct {
 	PrevTarget  string
 	WrongTarget bool
 
+	// TargetsHint are the staged targets not hit yet.
+	TargetsHint  string
+	PrevTargets  string
+	WrongTargets bool
+
 	DeadlineHint  time.Time
 	PrevDeadline  string
 	WrongDeadline bool
+
+	// Spreads can't be added to or reduced.
+	CanScale      bool
+	PrevAdd       string
+	PrevReduce    string
+	WrongFraction bool
 }
 
 func EditPosition(authorSlug, ideaSlug string, p *position.Position) PositionEditForm {
@@ -37,8 +48,21 @@ func EditPosition(authorSlug, ideaSlug string, p *position.Position) PositionEdi
 		Ticker:       p.Instrument.Ticker,
 		Type:         strings.ToUpper(string(p.Type)),
 		TargetHint:   p.TargetPrice.String(),
+		TargetsHint:  TargetsHint(p),
 		DeadlineHint: p.Deadline,
+		CanScale:     !p.IsSpread(),
+	}
+}
+
+// TargetsHint lists the staged targets not hit yet the way the form takes them.
+func TargetsHint(p *position.Position) string {
+	var tt []position.Target
+	for _, t := range p.StagedTargets() {
+		if !t.IsHit() {
+			tt = append(tt, t)
+		}
 	}
+	return position.FormatTargets(tt)
 }
 
 func (p PositionEditForm) Render(c echo.Context) error {
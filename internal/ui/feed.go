@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"changemedaddy/internal/aggregate/analyst"
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/position"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	feedSize = 50
+	tagBase  = "tag:idea-x3.ru,2024:"
+)
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Updated   string   `xml:"updated"`
+	Published string   `xml:"published"`
+	Link      atomLink `xml:"link"`
+	Summary   atomText `xml:"summary"`
+
+	at time.Time
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// FeedComponent is an Atom feed of an analyst's or an idea's activity.
+type FeedComponent struct {
+	feed    atomFeed
+	Updated time.Time
+}
+
+func entry(id, title, summary, link string, at time.Time) atomEntry {
+	ts := at.UTC().Format(time.RFC3339)
+	return atomEntry{
+		ID:        tagBase + id,
+		Title:     title,
+		Updated:   ts,
+		Published: ts,
+		Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+		Summary:   atomText{Type: "text", Body: summary},
+		at:        at,
+	}
+}
+
+func positionEntries(baseURL string, i *idea.Idea, p *position.Position) []atomEntry {
+	var (
+		ee     []atomEntry
+		ticker = strings.ToUpper(p.Instrument.Ticker)
+		typ    = strings.ToUpper(string(p.Type))
+		id     = fmt.Sprintf("analyst/%s/idea/%s/position/%d", i.AuthorSlug, i.Slug, p.ID)
+		link   = fmt.Sprintf("%s/analyst/%s/idea/%s", baseURL, i.AuthorSlug, i.Slug)
+		money  = p.Instrument.FormatPrice
+	)
+
+	// entries are told apart by when and what, history may be rewritten around them
+	seen := make(map[string]int)
+	for _, ch := range p.Changes() {
+		var title, summary string
+		switch ch.Kind {
+		case position.OpenedChange:
+			title = fmt.Sprintf("Открыта позиция %s %s", typ, ticker)
+			summary = fmt.Sprintf("%s открыл позицию %s %s по %s в идее «%s». Цель %s до %s.",
+				i.AuthorName, typ, ticker, money(ch.Price), i.Name, money(ch.TargetPrice), ch.Deadline.Format("2.01.2006"))
+		case position.TargetChange:
+			title = fmt.Sprintf("Новая цель по %s", ticker)
+			summary = fmt.Sprintf("%s изменил цель позиции %s %s в идее «%s» на %s.",
+				i.AuthorName, typ, ticker, i.Name, money(ch.TargetPrice))
+		case position.DeadlineChange:
+			title = fmt.Sprintf("Новый срок по %s", ticker)
+			summary = fmt.Sprintf("%s изменил срок позиции %s %s в идее «%s» на %s.",
+				i.AuthorName, typ, ticker, i.Name, ch.Deadline.Format("2.01.2006"))
+		case position.TargetHitChange:
+			title = fmt.Sprintf("Цель по %s достигнута", ticker)
+			summary = fmt.Sprintf("Позиция %s %s в идее «%s» %s достигла цели %s.",
+				typ, ticker, i.Name, i.AuthorName, money(ch.Price))
+			if ch.Fraction.IsPositive() {
+				summary += fmt.Sprintf(" Закрыто %s%% позиции.", percent(ch.Fraction))
+			}
+		case position.AddChange:
+			title = fmt.Sprintf("Добор позиции %s %s", typ, ticker)
+			summary = fmt.Sprintf("%s увеличил позицию %s %s в идее «%s» на %s%% по %s.",
+				i.AuthorName, typ, ticker, i.Name, percent(ch.Fraction), money(ch.Price))
+		case position.ReduceChange:
+			title = fmt.Sprintf("Частичное закрытие %s %s", typ, ticker)
+			summary = fmt.Sprintf("%s закрыл %s%% позиции %s %s в идее «%s» по %s.",
+				i.AuthorName, percent(ch.Fraction.Neg()), typ, ticker, i.Name, money(ch.Price))
+		case position.CloseChange:
+			title = fmt.Sprintf("Закрыта позиция %s %s", typ, ticker)
+			summary = fmt.Sprintf("%s закрыл позицию %s %s в идее «%s» по %s (открыта по %s).",
+				i.AuthorName, typ, ticker, i.Name, money(ch.Price), money(p.OpenPrice))
+		default:
+			continue
+		}
+
+		key := fmt.Sprintf("%s-%d", ch.Kind, ch.At.UnixMilli())
+		n := seen[key]
+		seen[key]++
+		if n > 0 {
+			key += fmt.Sprintf("-%d", n)
+		}
+
+		ee = append(ee, entry(id+"/"+key, title, summary, link, ch.At))
+	}
+
+	return ee
+}
+
+func ideaEntries(baseURL string, i *idea.Idea, pp []*position.Position) []atomEntry {
+	var ee []atomEntry
+
+	created := i.CreatedAt
+	for _, p := range pp {
+		if created.IsZero() || p.OpenDate.Before(created) {
+			created = p.OpenDate
+		}
+		ee = append(ee, positionEntries(baseURL, i, p)...)
+	}
+
+	if !created.IsZero() {
+		ee = append(ee, entry(
+			fmt.Sprintf("analyst/%s/idea/%s", i.AuthorSlug, i.Slug),
+			fmt.Sprintf("Новая идея: %s", i.Name),
+			fmt.Sprintf("%s опубликовал идею «%s».", i.AuthorName, i.Name),
+			fmt.Sprintf("%s/analyst/%s/idea/%s", baseURL, i.AuthorSlug, i.Slug),
+			created,
+		))
+	}
+
+	return ee
+}
+
+func newFeed(id, title, selfURL, altURL string, author atomAuthor, ee []atomEntry) FeedComponent {
+	sort.Slice(ee, func(i, j int) bool { return ee[i].at.After(ee[j].at) })
+	if len(ee) > feedSize {
+		ee = ee[:feedSize]
+	}
+
+	var updated time.Time
+	if len(ee) > 0 {
+		updated = ee[0].at
+	}
+
+	return FeedComponent{
+		feed: atomFeed{
+			ID:      tagBase + id,
+			Title:   title,
+			Updated: updated.UTC().Format(time.RFC3339),
+			Links: []atomLink{
+				{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+				{Href: altURL, Rel: "alternate", Type: "text/html"},
+			},
+			Author:  author,
+			Entries: ee,
+		},
+		Updated: updated,
+	}
+}
+
+// AnalystFeed builds the feed of all ideas of the analyst. positions maps idea slugs to their positions.
+func AnalystFeed(baseURL string, a *analyst.Analyst, ideas []*idea.Idea, positions map[string][]*position.Position) FeedComponent {
+	var ee []atomEntry
+	for _, i := range ideas {
+		ee = append(ee, ideaEntries(baseURL, i, positions[i.Slug])...)
+	}
+
+	altURL := fmt.Sprintf("%s/analyst/%s", baseURL, a.Slug)
+	return newFeed(
+		"analyst/"+a.Slug,
+		a.Name,
+		altURL+"/feed.atom",
+		altURL,
+		atomAuthor{Name: a.Name, URI: altURL},
+		ee,
+	)
+}
+
+func IdeaFeed(baseURL string, i *idea.Idea, pp []*position.Position) FeedComponent {
+	altURL := fmt.Sprintf("%s/analyst/%s/idea/%s", baseURL, i.AuthorSlug, i.Slug)
+	return newFeed(
+		fmt.Sprintf("analyst/%s/idea/%s", i.AuthorSlug, i.Slug),
+		fmt.Sprintf("%s — %s", i.Name, i.AuthorName),
+		altURL+"/feed.atom",
+		altURL,
+		atomAuthor{Name: i.AuthorName, URI: fmt.Sprintf("%s/analyst/%s", baseURL, i.AuthorSlug)},
+		ideaEntries(baseURL, i, pp),
+	)
+}
+
+// ETag changes whenever an entry is added or updated.
+func (f FeedComponent) ETag() string {
+	h := sha256.New()
+	h.Write([]byte(f.feed.ID))
+	for _, e := range f.feed.Entries {
+		h.Write([]byte(e.ID))
+		h.Write([]byte(e.Updated))
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+func (f FeedComponent) Render(c echo.Context) error {
+	body, err := xml.MarshalIndent(f.feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal feed: %w", err)
+	}
+
+	return c.Blob(200, "application/atom+xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/instrument"
+	"changemedaddy/internal/domain/position"
+	"testing"
+	"time"
+
+	"github.com/greatcloak/decimal"
+)
+
+func entryIDs(f FeedComponent) map[string]string {
+	ids := make(map[string]string)
+	for _, e := range f.feed.Entries {
+		ids[e.Title+" "+e.Updated] = e.ID
+	}
+	return ids
+}
+
+func TestFeedIDsSurviveHistoryRewrites(t *testing.T) {
+	var (
+		at = time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+		i  = &idea.Idea{Name: "Сбер", Slug: "sber", AuthorSlug: "mk", AuthorName: "MK", CreatedAt: at}
+		p  = &position.Position{
+			ID:         1,
+			Instrument: &instrument.Instrument{Ticker: "SBER"},
+			Type:       position.Long,
+			History: []position.Change{
+				{Kind: position.OpenedChange, At: at, Price: decimal.NewFromInt(300), TargetPrice: decimal.NewFromInt(350), Deadline: at.AddDate(0, 6, 0)},
+				{Kind: position.TargetChange, At: at.Add(48 * time.Hour), TargetPrice: decimal.NewFromInt(360)},
+			},
+		}
+	)
+
+	before := entryIDs(IdeaFeed("https://idea-x3.ru", i, []*position.Position{p}))
+
+	// an entry lands in the middle of the history
+	p.History = []position.Change{
+		p.History[0],
+		{Kind: position.AddChange, At: at.Add(24 * time.Hour), Price: decimal.NewFromInt(310), Fraction: decimal.NewFromFloat(0.5)},
+		p.History[1],
+	}
+	after := entryIDs(IdeaFeed("https://idea-x3.ru", i, []*position.Position{p}))
+
+	if len(after) != len(before)+1 {
+		t.Fatalf("want one more entry, got %d before and %d after", len(before), len(after))
+	}
+	for k, id := range before {
+		if after[k] != id {
+			t.Errorf("entry %q changed its id from %q to %q", k, id, after[k])
+		}
+	}
+}
+
+func TestFeedIDsAreUnique(t *testing.T) {
+	at := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	p := &position.Position{
+		ID:         1,
+		Instrument: &instrument.Instrument{Ticker: "SBER"},
+		Type:       position.Long,
+		// two stages hit within one candle
+		History: []position.Change{
+			{Kind: position.OpenedChange, At: at},
+			{Kind: position.TargetHitChange, At: at.Add(time.Hour), Price: decimal.NewFromInt(320)},
+			{Kind: position.TargetHitChange, At: at.Add(time.Hour), Price: decimal.NewFromInt(330)},
+		},
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range IdeaFeed("https://idea-x3.ru", &idea.Idea{Slug: "sber", AuthorSlug: "mk"}, []*position.Position{p}).feed.Entries {
+		if seen[e.ID] {
+			t.Errorf("duplicate entry id %q", e.ID)
+		}
+		seen[e.ID] = true
+	}
+}
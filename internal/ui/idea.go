@@ -2,6 +2,8 @@ package ui
 
 import (
 	_ "embed"
+	"fmt"
+	"slices"
 
 	"changemedaddy/internal/aggregate/idea"
 
@@ -19,14 +21,39 @@ type IdeaComponent struct {
 	SourceLink string
 	HasSource  bool
 
+	Body IdeaBodyComponent
+	// Excerpt is the start of the thesis, empty while there's none.
+	Excerpt string
+
 	PositionIDs []int
+	// PendingIDs are only shown to the owner.
+	PendingIDs []int
 
-	IsActive bool
+	IsActive   bool
+	Status     string
+	StatusName string
+	// CanAddPositions is false for closed and archived ideas.
+	CanAddPositions bool
 
 	IsOwner bool
+
+	// Preview is used for the OpenGraph and Twitter card meta tags.
+	Preview Preview
+}
+
+type Preview struct {
+	URL         string
+	Description string
+	// Image is empty while the idea has no positions.
+	Image string
 }
 
 func Idea(i *idea.Idea, isOwner bool) IdeaComponent {
+	var pending []int
+	if isOwner {
+		pending = i.PendingIDs
+	}
+
 	return IdeaComponent{
 		Name:        i.Name,
 		Slug:        i.Slug,
@@ -34,10 +61,43 @@ func Idea(i *idea.Idea, isOwner bool) IdeaComponent {
 		AuthorSlug:  i.AuthorSlug,
 		SourceLink:  i.SourceLink,
 		HasSource:   len(i.SourceLink) > 0,
+		Body:        IdeaBody(i, isOwner),
+		Excerpt:     i.Excerpt(),
 		PositionIDs: i.PositionIDs,
+		PendingIDs:  pending,
 		IsActive:    i.Status == idea.Active,
+		Status:      string(i.Status),
+		StatusName:  statusNames[i.Status],
 		IsOwner:     isOwner,
+
+		CanAddPositions: i.Status == idea.Draft || i.Status == idea.Active,
+	}
+}
+
+// WithPreview points link previews at the chart of the shared position, or of
+// the latest one if no position is shared.
+func (i IdeaComponent) WithPreview(baseURL string, sharedPositionID int) IdeaComponent {
+	url := fmt.Sprintf("%s/analyst/%s/idea/%s", baseURL, i.AuthorSlug, i.Slug)
+	i.Preview = Preview{
+		URL:         url,
+		Description: fmt.Sprintf("Идея %s от %s", i.Name, i.AuthorName),
+	}
+	if i.Excerpt != "" {
+		i.Preview.Description = i.Excerpt
+	}
+
+	if len(i.PositionIDs) == 0 {
+		return i
+	}
+
+	id := i.PositionIDs[len(i.PositionIDs)-1]
+	if slices.Contains(i.PositionIDs, sharedPositionID) {
+		id = sharedPositionID
+		i.Preview.URL = fmt.Sprintf("%s?position=%d", url, id)
 	}
+	i.Preview.Image = fmt.Sprintf("%s/position/%d/chart.png", url, id)
+
+	return i
 }
 
 func (i IdeaComponent) Render(c echo.Context) error {
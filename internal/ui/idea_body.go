@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/pkg/markdown"
+	"errors"
+	"html/template"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BodySection struct {
+	Title string
+	HTML  template.HTML
+}
+
+// bodySections renders the sections that are written, in the order they're read.
+func bodySections(b idea.Body) []BodySection {
+	var ss []BodySection
+	for _, s := range []struct{ title, src string }{
+		{"Тезис", b.Thesis},
+		{"Катализаторы", b.Catalysts},
+		{"Риски", b.Risks},
+	} {
+		if s.src != "" {
+			ss = append(ss, BodySection{Title: s.title, HTML: markdown.HTML(s.src)})
+		}
+	}
+	return ss
+}
+
+type IdeaBodyComponent struct {
+	AnalystSlug string
+	IdeaSlug    string
+	IsOwner     bool
+	CanEdit     bool
+
+	Sections []BodySection
+	EditedAt time.Time
+	// Versions is the number of the bodies the idea had before.
+	Versions int
+}
+
+func IdeaBody(i *idea.Idea, isOwner bool) IdeaBodyComponent {
+	return IdeaBodyComponent{
+		AnalystSlug: i.AuthorSlug,
+		IdeaSlug:    i.Slug,
+		IsOwner:     isOwner,
+		CanEdit:     isOwner && (i.Status == idea.Draft || i.Status == idea.Active),
+		Sections:    bodySections(i.Body),
+		EditedAt:    i.BodyEditedAt,
+		Versions:    len(i.Versions),
+	}
+}
+
+func (bc IdeaBodyComponent) Render(c echo.Context) error {
+	return c.Render(200, "idea_body.html", bc)
+}
+
+type IdeaBodyForm struct {
+	AnalystSlug string
+	IdeaSlug    string
+	Thesis      string
+	Catalysts   string
+	Risks       string
+	// Published ideas keep their previous bodies, the form warns about it.
+	IsPublic bool
+
+	TooLong   bool
+	MaxLength int
+
+	Attachments AttachmentsComponent
+}
+
+func EditIdeaBody(i *idea.Idea, b idea.Body, err error) IdeaBodyForm {
+	return IdeaBodyForm{
+		AnalystSlug: i.AuthorSlug,
+		IdeaSlug:    i.Slug,
+		Thesis:      b.Thesis,
+		Catalysts:   b.Catalysts,
+		Risks:       b.Risks,
+		IsPublic:    i.IsPublic(),
+		TooLong:     errors.Is(err, idea.ErrBodyTooLong),
+		MaxLength:   idea.MaxSectionLength,
+		Attachments: Attachments(i, nil),
+	}
+}
+
+func (bf IdeaBodyForm) Render(c echo.Context) error {
+	return c.Render(200, "edit_idea_body.html", bf)
+}
+
+type AttachmentItem struct {
+	Name     string
+	URL      string
+	Markdown string
+}
+
+type AttachmentsComponent struct {
+	AnalystSlug string
+	IdeaSlug    string
+	Attachments []AttachmentItem
+	// Error is why the last image wasn't attached.
+	Error string
+}
+
+func attachmentError(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, idea.ErrAttachmentType):
+		return "Можно прикрепить только PNG, JPEG, GIF или WebP"
+	case errors.Is(err, idea.ErrAttachmentTooBig):
+		return "Картинка должна быть не больше 5 МБ"
+	case errors.Is(err, idea.ErrTooManyAttachments):
+		return "К идее уже прикреплено слишком много картинок"
+	default:
+		return "Не получилось прикрепить картинку"
+	}
+}
+
+// Attachments lists the idea's images, the latest first.
+func Attachments(i *idea.Idea, err error) AttachmentsComponent {
+	aa := make([]AttachmentItem, 0, len(i.Attachments))
+	for n := len(i.Attachments) - 1; n >= 0; n-- {
+		a := i.Attachments[n]
+		aa = append(aa, AttachmentItem{Name: a.Name, URL: a.URL(), Markdown: a.Markdown()})
+	}
+
+	return AttachmentsComponent{
+		AnalystSlug: i.AuthorSlug,
+		IdeaSlug:    i.Slug,
+		Attachments: aa,
+		Error:       attachmentError(err),
+	}
+}
+
+func (ac AttachmentsComponent) Render(c echo.Context) error {
+	return c.Render(200, "attachments.html", ac)
+}
+
+type VersionItem struct {
+	EditedAt time.Time
+	Sections []BodySection
+}
+
+type IdeaVersionsComponent struct {
+	AnalystSlug string
+	IdeaSlug    string
+	Versions    []VersionItem
+}
+
+// IdeaVersions lists the previous bodies, the latest first.
+func IdeaVersions(i *idea.Idea) IdeaVersionsComponent {
+	vv := make([]VersionItem, 0, len(i.Versions))
+	for n := len(i.Versions) - 1; n >= 0; n-- {
+		v := i.Versions[n]
+		vv = append(vv, VersionItem{EditedAt: v.EditedAt, Sections: bodySections(v.Body)})
+	}
+
+	return IdeaVersionsComponent{
+		AnalystSlug: i.AuthorSlug,
+		IdeaSlug:    i.Slug,
+		Versions:    vv,
+	}
+}
+
+func (vc IdeaVersionsComponent) Render(c echo.Context) error {
+	return c.Render(200, "idea_versions.html", vc)
+}
@@ -9,6 +9,9 @@ type IdeaCardComponent struct {
 	AnalystSlug string
 	Slug        string
 	IsActive    bool
+	Status      string
+	StatusName  string
+	Excerpt     string
 }
 
 func IdeaCard(i IdeaComponent) IdeaCardComponent {
@@ -17,6 +20,9 @@ func IdeaCard(i IdeaComponent) IdeaCardComponent {
 		AnalystSlug: i.AuthorSlug,
 		Slug:        i.Slug,
 		IsActive:    i.IsActive,
+		Status:      i.Status,
+		StatusName:  i.StatusName,
+		Excerpt:     i.Excerpt,
 	}
 }
 
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"changemedaddy/internal/aggregate/idea"
+	"changemedaddy/internal/domain/position"
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var statusNames = map[idea.Status]string{
+	idea.Draft:    "Черновик",
+	idea.Active:   "Активна",
+	idea.Closed:   "Закрыта",
+	idea.Archived: "В архиве",
+}
+
+// IdeaStatusComponent is the owner's controls of the idea's lifecycle.
+type IdeaStatusComponent struct {
+	AnalystSlug string
+	IdeaSlug    string
+	Status      string
+	StatusName  string
+
+	CanPublish bool
+	CanClose   bool
+	CanArchive bool
+	CanRestore bool
+
+	// Drafts can be scheduled to go public.
+	CanSchedule bool
+	PublishAt   time.Time
+
+	// Error is why the idea couldn't change its status.
+	Error string
+}
+
+func statusError(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, idea.ErrPublishAt):
+		return "Время публикации должно быть в будущем"
+	case errors.Is(err, idea.ErrNoPositions):
+		return "Добавьте в идею хотя бы одну открытую позицию"
+	case errors.Is(err, position.ErrTargetPrice):
+		return "Цена уже прошла цель одной из позиций, поменяйте цель"
+	case errors.Is(err, position.ErrParseDeadline):
+		return "Срок одной из позиций уже прошёл, поменяйте срок"
+	case errors.Is(err, position.ErrExpired):
+		return "Контракт одной из позиций уже исполнен"
+	default:
+		return "Из этого статуса так нельзя"
+	}
+}
+
+func IdeaStatus(i *idea.Idea, err error) IdeaStatusComponent {
+	return IdeaStatusComponent{
+		AnalystSlug: i.AuthorSlug,
+		IdeaSlug:    i.Slug,
+		Status:      string(i.Status),
+		StatusName:  statusNames[i.Status],
+		CanPublish:  i.Status == idea.Draft,
+		CanClose:    i.Status == idea.Active,
+		CanArchive:  i.Status == idea.Draft || i.Status == idea.Closed,
+		CanRestore:  i.Status == idea.Archived,
+		CanSchedule: i.Status == idea.Draft,
+		PublishAt:   i.PublishAt,
+		Error:       statusError(err),
+	}
+}
+
+func (sc IdeaStatusComponent) Render(c echo.Context) error {
+	return c.Render(200, "idea_status.html", sc)
+}
@@ -1,19 +1,27 @@
 package ui
 
-import "github.com/labstack/echo/v4"
+import (
+	"changemedaddy/internal/aggregate/idea"
+
+	"github.com/labstack/echo/v4"
+)
 
 type IdeaForm struct {
-	AnalystSlug  string
-	PrevName     string
-	PrevLink     string
-	NameTooLong  bool
-	NameTooShort bool
-	NameTaken    bool
+	AnalystSlug   string
+	PrevName      string
+	PrevLink      string
+	PrevBenchmark string
+	Benchmarks    []idea.BenchmarkOption
+	NameTooLong   bool
+	NameTooShort  bool
+	NameTaken     bool
 }
 
 func NewIdea(analystSlug string) IdeaForm {
 	return IdeaForm{
-		AnalystSlug: analystSlug,
+		AnalystSlug:   analystSlug,
+		PrevBenchmark: idea.DefaultBenchmark,
+		Benchmarks:    idea.Benchmarks,
 	}
 }
 
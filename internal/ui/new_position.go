@@ -2,7 +2,9 @@ package ui
 
 import (
 	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
 	_ "embed"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
@@ -10,18 +12,33 @@ import (
 type PositionForm struct {
 	IdeaSlug    string
 	AnalystSlug string
+	// PositionID is set when the form rewrites a position of a draft.
+	PositionID int
 
 	PrevTicker  string
 	WrongTicker bool
 
+	PrevLegs   string
+	PrevSpread position.SpreadKind
+	WrongLegs  bool
+
 	PrevTarget  string
 	WrongTarget bool
 
+	PrevTargets  string
+	WrongTargets bool
+
+	PrevYield  string
+	WrongYield bool
+
 	PrevType  position.Type
 	WrongType bool
 
 	PrevDeadline  string
 	WrongDeadline bool
+
+	PrevPublishAt  string
+	WrongPublishAt bool
 }
 
 func NewPosition(ideaSlug, analystSlug string) PositionForm {
@@ -32,6 +49,39 @@ func NewPosition(ideaSlug, analystSlug string) PositionForm {
 	}
 }
 
+// RedraftPosition is the form filled with what a draft's position was made from.
+func RedraftPosition(authorSlug, ideaSlug string, p *position.Position) PositionForm {
+	pf := PositionForm{
+		IdeaSlug:     ideaSlug,
+		AnalystSlug:  authorSlug,
+		PositionID:   p.ID,
+		PrevTicker:   p.Instrument.Ticker,
+		PrevType:     p.Type,
+		PrevDeadline: p.Deadline.In(timeext.Moscow).Format("02.01.2006"),
+	}
+
+	if p.IsSpread() {
+		ll := make([]string, len(p.Legs))
+		for n, l := range p.Legs {
+			ll[n] = l.Instrument.Ticker + " " + l.Weight.String()
+		}
+		pf.PrevTicker = ""
+		pf.PrevLegs = strings.Join(ll, ", ")
+		pf.PrevSpread = p.Spread
+	}
+
+	switch {
+	case len(p.Targets) > 0:
+		pf.PrevTargets = position.FormatTargets(p.Targets)
+	case !p.TargetYield.IsZero():
+		pf.PrevYield = p.TargetYield.String()
+	default:
+		pf.PrevTarget = p.TargetPrice.String()
+	}
+
+	return pf
+}
+
 func (p PositionForm) Render(c echo.Context) error {
 	return c.Render(200, "new_position.html", p)
 }
@@ -5,48 +5,193 @@ import (
 	"strings"
 	"time"
 
+	"changemedaddy/internal/domain/instrument"
 	"changemedaddy/internal/domain/position"
+	"changemedaddy/internal/pkg/timeext"
 
 	"github.com/greatcloak/decimal"
 	"github.com/labstack/echo/v4"
 )
 
+// PositionComponent is also the position payload of analysts' webhooks, hence the json tags.
 type PositionComponent struct {
-	ID        int
-	Ticker    string
-	AssetName string
-	Type      string
+	ID        int    `json:"id"`
+	Ticker    string `json:"ticker"`
+	AssetName string `json:"asset_name"`
+	Type      string `json:"type"`
 
-	AuthorSlug, IdeaSlug string
+	InstrumentType instrument.Type `json:"instrument_type"`
+	Currency       string          `json:"currency"`
+	Lot            int             `json:"lot"`
 
-	Profitable bool
-	ProfitP    string
+	AuthorSlug string `json:"author_slug"`
+	IdeaSlug   string `json:"idea_slug"`
 
-	IsClosed   bool
-	ClosePrice decimal.Decimal
+	Profitable bool   `json:"profitable"`
+	ProfitP    string `json:"profit_p"`
+	// PriceReturnP leaves dividends and coupons out of ProfitP.
+	PriceReturnP string `json:"price_return_p"`
 
-	OpenPrice decimal.Decimal
-	CurPrice  decimal.Decimal
+	IsClosed bool `json:"is_closed"`
+	// IsDraft positions are rewritten or deleted freely, nobody has seen them.
+	IsDraft    bool            `json:"-"`
+	ClosePrice decimal.Decimal `json:"close_price"`
 
-	TargetPrice decimal.Decimal
-	Change      string
-	ChangeP     string
-	ChangeUp    bool
+	OpenPrice decimal.Decimal `json:"open_price"`
+	CurPrice  decimal.Decimal `json:"cur_price"`
 
-	Deadline time.Time
-	OpenDate time.Time
+	// Scaled positions only. Size is in percent of the opening size, Realized per unit of it.
+	IsScaled bool            `json:"-"`
+	AvgPrice decimal.Decimal `json:"avg_price"`
+	SizeP    string          `json:"size_p,omitempty"`
+	Realized decimal.Decimal `json:"realized,omitempty"`
 
-	IsOwner bool
+	TargetPrice decimal.Decimal `json:"target_price"`
+	// Bonds only, in percent.
+	TargetYield decimal.Decimal `json:"target_yield"`
+	Yield       decimal.Decimal `json:"yield"`
+	IsBond      bool            `json:"-"`
+	// Income is the dividends or coupons per unit.
+	Income   decimal.Decimal `json:"income"`
+	Change   string          `json:"change"`
+	ChangeP  string          `json:"change_p"`
+	ChangeUp bool            `json:"-"`
+
+	// Targets are shown when staged, progress is from the average entry.
+	Targets []TargetComponent `json:"targets,omitempty"`
+
+	// Benchmark is empty when the benchmark couldn't be quoted.
+	Benchmark  string `json:"benchmark,omitempty"`
+	BenchmarkP string `json:"benchmark_p,omitempty"`
+	AlphaP     string `json:"alpha_p,omitempty"`
+	AlphaUp    bool   `json:"-"`
+
+	Deadline time.Time `json:"deadline"`
+	OpenDate time.Time `json:"open_date"`
+	// Pending positions are shown to their author only, with the upside from now.
+	IsPending bool      `json:"-"`
+	PublishAt time.Time `json:"-"`
+	// TradingDaysLeft counts the MOEX sessions until the deadline, zero for closed positions.
+	TradingDaysLeft int `json:"trading_days_left"`
+
+	IsOwner bool `json:"-"`
+
+	// Legs are set for spreads, whose prices are then spread values.
+	Legs   []LegComponent `json:"legs,omitempty"`
+	Spread string         `json:"spread,omitempty"`
+
+	// Futures and options only. PnL is per contract, ProfitP of futures is on the margin.
+	IsDerivative bool            `json:"-"`
+	Expiry       time.Time       `json:"expiry,omitempty"`
+	OptionKind   string          `json:"option_kind,omitempty"`
+	Strike       decimal.Decimal `json:"strike,omitempty"`
+	PointValue   decimal.Decimal `json:"point_value,omitempty"`
+	Margin       decimal.Decimal `json:"margin,omitempty"`
+	PnL          decimal.Decimal `json:"pnl,omitempty"`
+
+	instrument instrument.Instrument
+	change     decimal.Decimal
+}
+
+type TargetComponent struct {
+	Price     string    `json:"price"`
+	FractionP string    `json:"fraction_p,omitempty"`
+	Hit       bool      `json:"hit"`
+	HitAt     time.Time `json:"hit_at,omitempty"`
+	ProgressP int       `json:"progress_p"`
+}
+
+// targets is empty for a position with a single target, its progress is the upside already.
+func targets(p position.WithProfit) []TargetComponent {
+	if len(p.Targets) < 2 && (len(p.Targets) == 0 || !p.Targets[0].Fraction.IsPositive()) {
+		return nil
+	}
+
+	cur := p.Instrument.Price
+	if p.Status == position.Closed {
+		cur = p.ClosedPrice
+	}
+
+	tt := make([]TargetComponent, len(p.Targets))
+	for n, t := range p.Targets {
+		progress := 100
+		if !t.IsHit() {
+			if way := t.Price.Sub(p.AvgPrice); way.IsZero() {
+				progress = 0
+			} else {
+				done := cur.Sub(p.AvgPrice).Div(way).Mul(decimal.NewFromInt(100)).IntPart()
+				progress = int(max(0, min(99, done)))
+			}
+		}
+
+		tt[n] = TargetComponent{
+			Price:     p.Instrument.FormatPrice(t.Price),
+			Hit:       t.IsHit(),
+			HitAt:     t.HitAt,
+			ProgressP: progress,
+		}
+		if t.Fraction.IsPositive() {
+			tt[n].FractionP = percent(t.Fraction)
+		}
+	}
+	return tt
+}
+
+type LegComponent struct {
+	Ticker    string `json:"ticker"`
+	AssetName string `json:"asset_name"`
+	Weight    string `json:"weight"`
+	OpenPrice string `json:"open_price"`
+	CurPrice  string `json:"cur_price"`
+}
+
+// percentOf is change in percent of base, signed. A difference spread can be
+// worth nothing, there's no percent of it then.
+func percentOf(change, base decimal.Decimal) string {
+	if base.IsZero() {
+		return ""
+	}
+	return withSign(change.Div(base.Abs()).Mul(decimal.NewFromInt(100)).Round(2))
+}
+
+func legs(p position.WithProfit) []LegComponent {
+	ll := make([]LegComponent, len(p.Legs))
+	for n, l := range p.Legs {
+		cur := l.ClosedPrice
+		if p.Status != position.Closed {
+			cur = p.LegPrices[n]
+		}
+		ll[n] = LegComponent{
+			Ticker:    strings.ToUpper(l.Instrument.Ticker),
+			AssetName: l.Instrument.Name,
+			Weight:    withSign(l.Weight),
+			OpenPrice: l.Instrument.FormatPrice(l.OpenPrice),
+			CurPrice:  l.Instrument.FormatPrice(cur),
+		}
+	}
+	return ll
 }
 
 func Position(isOwner bool, authorSlug, ideaSlug string, p position.WithProfit) PositionComponent {
-	var change, changeP string
-	if p.Status == position.Active {
-		change = withSign(p.TargetPrice.Sub(p.Instrument.Price))
-		changeP = withSign(p.TargetPrice.Sub(p.Instrument.Price).Div(p.Instrument.Price).Mul(decimal.NewFromInt(100)).Round(2))
+	var (
+		change           decimal.Decimal
+		changeS, changeP string
+	)
+	var daysLeft int
+	if p.Status != position.Closed {
+		daysLeft = timeext.MOEX.TradingDaysLeft(timeext.Now(), p.Deadline)
+		change = p.TargetPrice.Sub(p.Instrument.Price)
+		changeS = withSign(change)
+		changeP = percentOf(change, p.Instrument.Price)
 	} else if p.Status == position.Closed {
-		change = withSign(p.ClosedPrice.Sub(p.OpenPrice))
-		changeP = withSign(p.ClosedPrice.Sub(p.OpenPrice).Div(p.OpenPrice).Mul(decimal.NewFromInt(100)).Round(2))
+		change = p.ClosedPrice.Sub(p.AvgPrice)
+		changeS = withSign(change)
+		changeP = percentOf(change, p.AvgPrice)
+	}
+
+	var sizeP string
+	if p.IsScaled() {
+		sizeP = percent(p.Size)
 	}
 
 	return PositionComponent{
@@ -55,27 +200,100 @@ func Position(isOwner bool, authorSlug, ideaSlug string, p position.WithProfit)
 		Type:      strings.ToUpper(string(p.Type)),
 		AssetName: p.Instrument.Name,
 
+		InstrumentType: p.Instrument.Type,
+		Currency:       p.Instrument.CurrencyCode(),
+		Lot:            p.Instrument.Lot,
+
 		AuthorSlug: authorSlug,
 		IdeaSlug:   ideaSlug,
 
 		Profitable: p.ProfitP.GreaterThanOrEqual(decimal.Zero),
 		ProfitP:    withSign(p.ProfitP.Round(2)),
 
+		PriceReturnP: withSign(p.PriceReturnP.Round(2)),
+
 		IsClosed:   p.Status == position.Closed,
 		ClosePrice: p.ClosedPrice,
 
 		OpenPrice: p.OpenPrice,
 		CurPrice:  p.Instrument.Price,
 
+		IsScaled: p.IsScaled(),
+		AvgPrice: p.AvgPrice,
+		SizeP:    sizeP,
+		Realized: p.Realized,
+
 		TargetPrice: p.TargetPrice,
-		Change:      change,
+		TargetYield: p.TargetYield,
+		Yield:       p.Yield,
+		Income:      p.Income,
+		IsBond:      p.Instrument.IsBond(),
+		Change:      changeS,
 		ChangeP:     changeP,
 
-		Deadline: p.Deadline,
-		OpenDate: p.OpenDate,
+		Deadline:  p.Deadline,
+		OpenDate:  p.OpenDate,
+		IsPending: p.Status == position.Pending,
+		PublishAt: p.PublishAt,
+
+		TradingDaysLeft: daysLeft,
 
 		IsOwner: isOwner,
+
+		Legs:   legs(p),
+		Spread: string(p.Spread),
+
+		Targets: targets(p),
+
+		IsDerivative: p.Instrument.IsDerivative(),
+		Expiry:       p.Instrument.Expiry,
+		OptionKind:   string(p.Instrument.OptionKind),
+		Strike:       p.Instrument.Strike,
+		PointValue:   p.Instrument.PointValue,
+		Margin:       p.Instrument.Margin,
+		PnL:          p.PnL,
+
+		instrument: *p.Instrument.Instrument,
+		change:     change,
+	}
+}
+
+// WithBenchmark adds how the position did against the idea's benchmark.
+func (pc PositionComponent) WithBenchmark(r position.Relative) PositionComponent {
+	pc.Benchmark = r.Benchmark
+	pc.BenchmarkP = withSign(r.BenchmarkP.Round(2))
+	pc.AlphaP = withSign(r.AlphaP.Round(2))
+	pc.AlphaUp = !r.AlphaP.IsNegative()
+	return pc
+}
+
+// Money formats a price in the instrument's currency and tick size.
+func (pc PositionComponent) Money(d decimal.Decimal) string {
+	return pc.instrument.FormatPrice(d)
+}
+
+// IncomeMoney formats the dividends or coupons in the currency, even for bonds
+// whose prices are in percent.
+func (pc PositionComponent) IncomeMoney() string {
+	return pc.Income.Round(2).String() + " " + pc.instrument.CurrencySign()
+}
+
+// PnLMoney formats the money made per contract.
+func (pc PositionComponent) PnLMoney() string {
+	return withSign(pc.PnL.Round(2)) + " " + pc.instrument.CurrencySign()
+}
+
+// RealizedMoney formats the money the exits made.
+func (pc PositionComponent) RealizedMoney() string {
+	return withSign(pc.Realized.Round(2)) + " " + pc.instrument.CurrencySign()
+}
+
+// ChangeMoney is Change formatted as Money.
+func (pc PositionComponent) ChangeMoney() string {
+	if pc.change.IsNegative() {
+		return pc.Money(pc.change)
 	}
+	return "+" + pc.Money(pc.change)
 }
 
 func (pc PositionComponent) Render(c echo.Context) error {
@@ -0,0 +1,49 @@
+package ui
+
+import "github.com/labstack/echo/v4"
+
+type SubscriptionForm struct {
+	AnalystSlug string
+	IdeaSlug    string
+
+	PrevChannel  string
+	WrongChannel bool
+
+	PrevAddress  string
+	WrongAddress bool
+
+	Subscribed bool
+	// NeedsConfirmation is set for emails, they get a letter with the link first.
+	NeedsConfirmation bool
+}
+
+func NewSubscription(analystSlug, ideaSlug string) SubscriptionForm {
+	return SubscriptionForm{
+		AnalystSlug: analystSlug,
+		IdeaSlug:    ideaSlug,
+		PrevChannel: "email",
+	}
+}
+
+func (s SubscriptionForm) Render(c echo.Context) error {
+	return c.Render(200, "subscribe.html", s)
+}
+
+func ConfirmUnsubscribe(c echo.Context, id string) error {
+	return c.Render(200, "unsubscribe.html", id)
+}
+
+func Unsubscribed(c echo.Context) error {
+	return c.Render(200, "unsubscribed.html", nil)
+}
+
+type confirmation struct {
+	ID        string
+	Confirmed bool
+}
+
+// ConfirmSubscription asks to confirm with a button rather than on the link
+// itself, mail scanners follow links.
+func ConfirmSubscription(c echo.Context, id string, confirmed bool) error {
+	return c.Render(200, "confirm_subscription.html", confirmation{ID: id, Confirmed: confirmed})
+}
@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"changemedaddy/internal/domain/instrument"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TickerOptionsComponent struct {
+	Listings []instrument.Listing
+}
+
+func TickerOptions(ll []instrument.Listing) TickerOptionsComponent {
+	return TickerOptionsComponent{Listings: ll}
+}
+
+func (t TickerOptionsComponent) Render(c echo.Context) error {
+	return c.Render(200, "ticker_options.html", t)
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"changemedaddy/internal/domain/chart"
 	"changemedaddy/internal/pkg/assert"
+	"changemedaddy/internal/pkg/timeext"
 	"fmt"
 	"html/template"
 	"io"
@@ -43,6 +44,11 @@ func withSign(d decimal.Decimal) string {
 	return d.String()
 }
 
+// percent formats a fraction in percent.
+func percent(d decimal.Decimal) string {
+	return d.Mul(decimal.NewFromInt(100)).Round(2).String()
+}
+
 type templateRenderer struct {
 	templates *template.Template
 }
@@ -50,13 +56,16 @@ type templateRenderer struct {
 func NewRenderer() *templateRenderer {
 	funcMap := template.FuncMap{
 		"chartDateFormat": func(t time.Time) string {
-			return t.Format(chart.DateFormat)
+			return t.In(timeext.Moscow).Format(chart.DateFormat)
 		},
 		"ruDateFormat": func(t time.Time) string {
-			return monday.Format(t, "2 January 2006", monday.LocaleRuRU)
+			return monday.Format(t.In(timeext.Moscow), "2 January 2006", monday.LocaleRuRU)
+		},
+		"timeFormat": func(t time.Time) string {
+			return t.In(timeext.Moscow).Format("15:04")
 		},
 		"shortDateFormat": func(t time.Time) string {
-			return monday.Format(t, "2.01.2006", monday.LocaleRuRU)
+			return monday.Format(t.In(timeext.Moscow), "2.01.2006", monday.LocaleRuRU)
 		},
 		"IdeaCard": IdeaCard,
 		"Position": Position,
@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"changemedaddy/internal/domain/webhook"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhooksComponent struct {
+	AnalystSlug string
+	Endpoints   []*webhook.Endpoint
+	Deliveries  []webhook.Delivery
+
+	PrevURL  string
+	WrongURL bool
+	TooMany  bool
+}
+
+func Webhooks(analystSlug string, ee []*webhook.Endpoint, dd []webhook.Delivery) WebhooksComponent {
+	return WebhooksComponent{
+		AnalystSlug: analystSlug,
+		Endpoints:   ee,
+		Deliveries:  dd,
+	}
+}
+
+func (w WebhooksComponent) Render(c echo.Context) error {
+	return c.Render(200, "webhooks.html", w)
+}
@@ -5,10 +5,6 @@ const red = "#dc2020";
 const lightGrey = "rgba(140,137,137,0.2)";
 const grey = "rgba(79,75,75,0.2)";
 
-function getTimeInMinutesFromDate(d) {
-    return d.getTime() / (1000 * 60);
-}
-
 function getStingDateYYYYMMDD(timestamp) {
     const dateObject = new Date(timestamp * 1000);
     const year = dateObject.getFullYear();
@@ -89,63 +85,35 @@ function fromJSONToChartOption(jsonArr) {
     ];
 }
 
-function getMarketInterval(openDate, deadline) {
-    let candlesOnScreenCount = ((window.innerWidth - 80) / 210) * 30;
-    let now = new Date();
-    let to = deadline < now ? deadline : now;
-    console.log(to);
-    let diffInMinutes = getTimeInMinutesFromDate(to) - getTimeInMinutesFromDate(openDate);
-    let intervalInMinutes = Math.floor(diffInMinutes / candlesOnScreenCount);
-    console.log(intervalInMinutes);
-
-    let intervalType;
-    switch (true) {
-        case intervalInMinutes <= 1:
-            intervalType = 1;
-            break;
-        case intervalInMinutes <= 2:
-            intervalType = 6;
-            break;
-        case intervalInMinutes <= 3:
-            intervalType = 7;
-            break;
-        case intervalInMinutes <= 5:
-            intervalType = 2;
-            break;
-        case intervalInMinutes <= 10:
-            intervalType = 8;
-            break;
-        case intervalInMinutes <= 15:
-            intervalType = 3;
-            break;
-        case intervalInMinutes <= 30:
-            intervalType = 9;
-            break;
-        case intervalInMinutes <= 60:
-            intervalType = 4;
-            break;
-        case intervalInMinutes <= 2 * 60:
-            intervalType = 10;
-            break;
-        case intervalInMinutes <= 4 * 60:
-            intervalType = 11;
-            break;
-        case intervalInMinutes <= 24 * 60:
-            intervalType = 5;
-            break;
-        case intervalInMinutes <= 7 * 24 * 60:
-            intervalType = 12;
-            break;
-        case intervalInMinutes <= 4 * 7 * 24 * 60:
-            intervalType = 13;
-            break;
-        default:
-            intervalType = 0;
+// snapToCandle returns the time of the last candle not after the given time, so that
+// annotations don't add points of their own to the time scale.
+function snapToCandle(data, time) {
+    if (data.length === 0) {
+        return time;
+    }
+
+    let snapped = data[0].time;
+    for (const candle of data) {
+        if (candle.time > time) {
             break;
+        }
+        snapped = candle.time;
     }
+    return snapped;
+}
 
-    console.log(intervalType);
-    return intervalType;
+function segment(from, to, value) {
+    if (from >= to) {
+        return [{time: from, value: value}];
+    }
+    return [
+        {time: from, value: value},
+        {time: to, value: value},
+    ];
+}
+
+function formatDate(timestamp) {
+    return new Date(timestamp * 1000).toLocaleDateString("ru-RU");
 }
 
 class ChartComponent extends HTMLElement {
@@ -157,6 +125,7 @@ class ChartComponent extends HTMLElement {
         template.innerHTML = `<div>
                             <div id="chart-container"></div>
                             <button id="scrollBtn">>></button>
+                            <ul id="legend" style="list-style: none; padding: 0; font-size: 0.875rem; color: #6b7280;"></ul>
                           </div>`;
 
         const clonedTemplate = document.importNode(template.content, true);
@@ -165,23 +134,13 @@ class ChartComponent extends HTMLElement {
         this.chart = null;
         this.series = null;
         this.lineSeries = null;
+        this.annotations = null;
         this.loadChart();
     }
 
     loadChart() {
-        let url = this.getAttribute("url");
-        const urlParts = url.split("/");
-        let openDate, deadline;
-        for (let i = 0; i < urlParts.length; i++) {
-            if (urlParts[i] === "from") {
-                openDate = new Date(decodeURIComponent(urlParts[i + 1]));
-            } else if (urlParts[i] === "to") {
-                deadline = new Date(decodeURIComponent(urlParts[i + 1]));
-            }
-        }
-
-        let marketInterval = getMarketInterval(openDate, deadline);
-        url += "/interval/" + marketInterval.toString();
+        // the server picks the candle interval from the position's lifespan
+        const url = this.getAttribute("url");
 
         fetch(url)
             .then((response) => {
@@ -191,7 +150,12 @@ class ChartComponent extends HTMLElement {
                 return response.json();
             })
             .then((json) => {
-                return fromJSONToChartOption(json);
+                // position charts come with annotations, bare candle arrays are still accepted
+                if (Array.isArray(json)) {
+                    return fromJSONToChartOption(json);
+                }
+                this.annotations = json.annotations;
+                return fromJSONToChartOption(json.candles);
             })
             .then((option) => this.render(option))
             .catch((error) => {
@@ -220,6 +184,41 @@ class ChartComponent extends HTMLElement {
                 from: startRange,
                 to: endRange,
             });
+
+            if (this.annotations !== null) {
+                this.drawAnnotations(this.annotations, data);
+            }
+        }
+    }
+
+    drawAnnotations(a, data) {
+        const end = a.close !== null
+            ? snapToCandle(data, a.close.time)
+            : data[data.length - 1].time;
+
+        this.lineSeries.setData(segment(snapToCandle(data, a.open.time), end, a.open.price));
+
+        for (const line of a.target) {
+            const series = this.chart.addLineSeries({
+                priceLine: false,
+                lineStyle: 2,
+                color: turquoise,
+                lineWidth: 1,
+            });
+            series.setData(segment(snapToCandle(data, line.from), snapToCandle(data, line.to), line.price));
+        }
+
+        const legend = this.shadowRoot.getElementById("legend");
+        const markers = [a.open, ...a.history];
+        if (a.close !== null) {
+            markers.push(a.close);
+        } else {
+            markers.push(a.deadline);
+        }
+        for (const m of markers) {
+            const item = document.createElement("li");
+            item.textContent = `${formatDate(m.time)} — ${m.text}`;
+            legend.appendChild(item);
         }
     }
 
@@ -55,7 +55,7 @@ function ssrize(elt) {
             }
 
             try {
-              const response = await fetch(url, {
+              const response = await fetch(requestURL(url, verbEl), {
                 method: verb.toUpperCase(),
               });
               const responseText = await response.text();
@@ -81,13 +81,47 @@ function ssrize(elt) {
           ) {
             ssrAction();
           } else if (triggerEvent) {
-            verbEl.addEventListener(triggerEvent, ssrAction);
+            verbEl.addEventListener(
+              triggerEvent,
+              debounce(ssrAction, verbEl.getAttribute("ssr-delay")),
+            );
           }
       }
     }
   }
 }
 
+/**
+ * Inputs send their own value along, like a one-field form would.
+ */
+function requestURL(url, elt) {
+  const name = elt.getAttribute("name");
+  if (!name || elt.value === undefined) {
+    return url;
+  }
+
+  const u = new URL(url, window.location.href);
+  u.searchParams.set(name, elt.value);
+  return u.toString();
+}
+
+/**
+ * Delays fn until the trigger has been quiet for delay ms, so typing
+ * doesn't fire a request per keystroke.
+ */
+function debounce(fn, delay) {
+  const ms = parseInt(delay, 10);
+  if (!ms) {
+    return fn;
+  }
+
+  let timer;
+  return () => {
+    clearTimeout(timer);
+    timer = setTimeout(fn, ms);
+  };
+}
+
 function getTrigger(elt) {
   let triggerEvent;
   const triggerAttr = elt.getAttribute("ssr-trigger");
@@ -129,6 +163,8 @@ function swapTarget(toSwap, targetFrom) {
     targetElement.insertAdjacentElement("beforebegin", toSwap);
   } else if (swapAttr === "afterend") {
     targetElement.insertAdjacentElement("afterend", toSwap);
+  } else if (swapAttr === "innerHTML") {
+    targetElement.replaceChildren(...toSwap.childNodes);
   } else {
     targetElement.replaceWith(toSwap);
   }